@@ -15,8 +15,11 @@
 // Package bar allows a user to create a go binary that follows the i3bar protocol.
 package bar
 
-import "image/color"
-import "time"
+import (
+	"context"
+	"image/color"
+	"time"
+)
 
 // TextAlignment defines the alignment of text within a block.
 // Using TextAlignment rather than string opens up the possibility of i18n without
@@ -49,8 +52,9 @@ type Segment struct {
 	// A bitmask of attributes that are set. Needed because the go default
 	// for some attributes behave differently from unset values when sent to
 	// i3bar. (e.g. the default separatorWidth is not 0).
-	attrSet int
-	onClick func(Event)
+	attrSet    int
+	onClick    func(Event)
+	identifier string
 
 	text      string
 	pango     bool
@@ -136,15 +140,19 @@ X, Y describe event co-ordinates relative to the output segment, and
 Width, Height are set to the size of the output segment.
 
 ScreenX, ScreenY are the event co-ordinates relative to the root window.
+
+Modifiers is the set of keyboard modifiers held down during the event.
 */
 type Event struct {
-	Button  Button `json:"button"`
-	X       int    `json:"relative_x,omitempty"`
-	Y       int    `json:"relative_y,omitempty"`
-	Width   int    `json:"width,omitempty"`
-	Height  int    `json:"height,omitempty"`
-	ScreenX int    `json:"x,omitempty"`
-	ScreenY int    `json:"y,omitempty"`
+	SegmentID string   `json:"instance,omitempty"`
+	Button    Button   `json:"button"`
+	Modifiers Modifier `json:"modifiers,omitempty"`
+	X         int      `json:"relative_x,omitempty"`
+	Y         int      `json:"relative_y,omitempty"`
+	Width     int      `json:"width,omitempty"`
+	Height    int      `json:"height,omitempty"`
+	ScreenX   int      `json:"x,omitempty"`
+	ScreenY   int      `json:"y,omitempty"`
 }
 
 /*
@@ -156,9 +164,14 @@ Since the Event that triggered the error handler is also embedded,
 error handlers have information about the position of the module and can
 choose to display more contextual messages than a simple bar across the
 entire screen.
+
+Restart restarts the module that produced the error once it has stopped, as
+if the error segment had been left clicked. It may be nil if the module
+cannot be restarted.
 */
 type ErrorEvent struct {
-	Error error
+	Error   error
+	Restart func()
 	Event
 }
 
@@ -176,6 +189,19 @@ type Module interface {
 	Stream(Sink)
 }
 
+// ContextModule extends module with a StreamContext method, which allows the
+// module to be stopped. core.WithContext adapts any Module to a ContextModule.
+//
+// Modules that implement ContextModule usually implement Stream by calling
+// StreamContext with context.Background().
+type ContextModule interface {
+	Module
+	// StreamContext is like Stream, but returns when the context is cancelled.
+	// Any resources used by the module (e.g. watchers) should be released
+	// before it returns, and the sink must not be used afterwards.
+	StreamContext(context.Context, Sink)
+}
+
 // RefresherModule extends module with a Refresh() method that forces a refresh
 // of the data being displayed (e.g. a fresh HTTP request or file read).
 // core.Module will add middle-click to refresh for modules that implement it.
@@ -0,0 +1,86 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bar
+
+import "encoding/json"
+
+// Modifier represents a set of keyboard modifiers that were held down during
+// a mouse event. The values match the X11 modifier masks, and can be combined
+// using bitwise or, e.g. ModShift|ModControl.
+type Modifier int
+
+const (
+	// ModShift is the shift key.
+	ModShift Modifier = 1 << iota
+	// ModLock is caps lock.
+	ModLock
+	// ModControl is the control key.
+	ModControl
+	// Mod1 is usually the alt key.
+	Mod1
+	// Mod2 is usually num lock.
+	Mod2
+	// Mod3 is not usually assigned.
+	Mod3
+	// Mod4 is usually the super (windows) key.
+	Mod4
+	// Mod5 is usually AltGr (ISO_Level3_Shift).
+	Mod5
+)
+
+// modifierNames are the names used for modifiers in the i3bar protocol.
+var modifierNames = []struct {
+	mod  Modifier
+	name string
+}{
+	{ModShift, "Shift"},
+	{ModLock, "Lock"},
+	{ModControl, "Control"},
+	{Mod1, "Mod1"},
+	{Mod2, "Mod2"},
+	{Mod3, "Mod3"},
+	{Mod4, "Mod4"},
+	{Mod5, "Mod5"},
+}
+
+// MarshalJSON serialises the modifiers as an array of names, for example
+// ["Shift","Mod4"], to match the format used by i3bar.
+func (m Modifier) MarshalJSON() ([]byte, error) {
+	names := []string{}
+	for _, n := range modifierNames {
+		if m&n.mod != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON parses an array of modifier names, as sent by i3bar and
+// swaybar with each click event. Unknown names are ignored.
+func (m *Modifier) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*m = 0
+	for _, name := range names {
+		for _, n := range modifierNames {
+			if n.name == name {
+				*m |= n.mod
+			}
+		}
+	}
+	return nil
+}
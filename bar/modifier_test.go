@@ -0,0 +1,47 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bar
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifierJSON(t *testing.T) {
+	var e Event
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"button":1,"modifiers":["Shift","Mod4","Mod2","Foo"]}`), &e))
+	require.Equal(t, Event{Button: ButtonLeft, Modifiers: ModShift | Mod4 | Mod2}, e,
+		"known modifiers are parsed, unknown ones ignored")
+
+	require.NoError(t, json.Unmarshal([]byte(`{"button":4,"modifiers":[]}`), &e))
+	require.Equal(t, Event{Button: ScrollUp}, e, "empty modifiers")
+
+	require.NoError(t, json.Unmarshal([]byte(`{"button":5}`), &e))
+	require.Equal(t, Event{Button: ScrollDown}, e, "missing modifiers")
+
+	require.Error(t, json.Unmarshal([]byte(`{"modifiers":"Shift"}`), &e),
+		"modifiers is not an array")
+
+	out, err := json.Marshal(Event{Button: ButtonRight, Modifiers: ModControl | Mod1})
+	require.NoError(t, err)
+	require.Equal(t, `{"button":3,"modifiers":["Control","Mod1"]}`, string(out))
+
+	out, err = json.Marshal(Event{Button: ButtonRight})
+	require.NoError(t, err)
+	require.Equal(t, `{"button":3}`, string(out), "no modifiers omitted")
+}
@@ -58,6 +58,21 @@ func (s *Segment) Content() (text string, isPango bool) {
 	return s.text, s.pango
 }
 
+// Identifier sets an identifier for the segment, which is set as the
+// SegmentID of click events on the segment. It is also used to route click
+// events to the same segment even if the module's output changes between
+// the click and the event being received.
+func (s *Segment) Identifier(identifier string) *Segment {
+	s.identifier = identifier
+	return s
+}
+
+// GetID returns the identifier of this segment.
+// The second value indicates whether it was explicitly set.
+func (s *Segment) GetID() (string, bool) {
+	return s.identifier, s.identifier != ""
+}
+
 // ShortText sets the shortened text, used if the default text
 // for all segments does not fit in the bar.
 func (s *Segment) ShortText(shortText string) *Segment {
@@ -54,6 +54,7 @@ func TestSegment(t *testing.T) {
 	}
 
 	assertUnset(segment.GetShortText())
+	assertUnset(segment.GetID())
 	assertUnset(segment.GetAlignment())
 	assertUnset(segment.GetColor())
 	assertUnset(segment.GetBackground())
@@ -86,6 +87,9 @@ func TestSegment(t *testing.T) {
 	segment.ShortText("")
 	require.Equal("", assertSet(segment.GetShortText()))
 
+	segment.Identifier("foo")
+	require.Equal("foo", assertSet(segment.GetID()))
+
 	segment.Color(color.Gray{0x77})
 	assertColorEqual(t, color.RGBA{0x77, 0x77, 0x77, 0xff},
 		assertSet(segment.GetColor()).(color.Color))
@@ -16,40 +16,25 @@
 package barista
 
 import (
-	"encoding/json"
-	"errors"
-	"image/color"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"os/signal"
-	"strconv"
 	"sync"
 
 	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/control"
 	"github.com/soumya92/barista/core"
 	l "github.com/soumya92/barista/logging"
 	"github.com/soumya92/barista/oauth"
+	"github.com/soumya92/barista/renderer"
 	"github.com/soumya92/barista/timing"
 
-	"github.com/lucasb-eyer/go-colorful"
 	"golang.org/x/sys/unix"
 )
 
-// i3Event instances are received from i3bar on stdin.
-type i3Event struct {
-	bar.Event
-	Name string `json:"name"`
-}
-
-// i3Header is sent at the beginning of output.
-type i3Header struct {
-	Version     int  `json:"version"`
-	StopSignal  int  `json:"stop_signal,omitempty"`
-	ContSignal  int  `json:"cont_signal,omitempty"`
-	ClickEvents bool `json:"click_events"`
-}
-
 // i3Bar is the "bar" instance that handles events and streams output.
 type i3Bar struct {
 	sync.Mutex
@@ -63,13 +48,15 @@ type i3Bar struct {
 	// The channel that receives a signal on module updates.
 	update chan struct{}
 	// The channel that aggregates all events from i3.
-	events chan i3Event
+	events chan renderer.Event
 	// The Reader to read events from (e.g. stdin)
 	reader io.Reader
 	// The Writer to write bar output to (e.g. stdout)
 	writer io.Writer
-	// A json encoder set to write to the output stream.
-	encoder *json.Encoder
+	// The renderer that implements the protocol spoken by the bar process.
+	renderer renderer.Renderer
+	// The path of the unix socket for the control interface, if enabled.
+	controlSocket string
 	// Flipped when Run() is called, to prevent issues with modules
 	// being added after the bar has been started.
 	started bool
@@ -109,10 +96,11 @@ var instanceInit sync.Once
 func construct() {
 	instanceInit.Do(func() {
 		instance = &i3Bar{
-			update: make(chan struct{}, 1),
-			events: make(chan i3Event),
-			reader: os.Stdin,
-			writer: os.Stdout,
+			update:   make(chan struct{}, 1),
+			events:   make(chan renderer.Event),
+			reader:   os.Stdin,
+			writer:   os.Stdout,
+			renderer: renderer.I3Bar(),
 			// bar starts paused, will be resumed on Run().
 			paused: true,
 			// Default to i3-nagbar when right-clicking errors.
@@ -144,6 +132,32 @@ func SuppressSignals(suppressSignals bool) {
 	instance.suppressSignals = suppressSignals
 }
 
+// SetRenderer sets the renderer used to communicate with the program that
+// displays the bar, e.g. renderer.Lemonbar(). The default is renderer.I3Bar(),
+// which works for both i3bar and swaybar. Must be called before Run.
+func SetRenderer(r renderer.Renderer) {
+	construct()
+	instance.Lock()
+	defer instance.Unlock()
+	if instance.started {
+		panic("Cannot change renderer after .Run()")
+	}
+	instance.renderer = r
+}
+
+// SetControlSocket enables the control interface on a unix socket at the given
+// path, which allows other programs (e.g. barista-ctl) to control the bar's
+// modules. See the control package for details. Must be called before Run.
+func SetControlSocket(path string) {
+	construct()
+	instance.Lock()
+	defer instance.Unlock()
+	if instance.started {
+		panic("Cannot change control socket after .Run()")
+	}
+	instance.controlSocket = path
+}
+
 // SetErrorHandler sets the function to be called when an error segment
 // is right clicked. This replaces the DefaultErrorHandler.
 func SetErrorHandler(handler func(bar.ErrorEvent)) {
@@ -157,7 +171,19 @@ func SetErrorHandler(handler func(bar.ErrorEvent)) {
 // If any modules are provided, they are added to the bar now.
 // This allows both styles of bar construction:
 // `bar.Add(a); bar.Add(b); bar.Run()`, and `bar.Run(a, b)`.
+// On SIGINT or SIGTERM, all modules are stopped, the final output is written,
+// and Run returns nil.
 func Run(modules ...bar.Module) error {
+	ctx, stop := signal.NotifyContext(context.Background(), unix.SIGINT, unix.SIGTERM)
+	defer stop()
+	return RunContext(ctx, modules...)
+}
+
+// RunContext is like Run, but runs the bar until the context is cancelled,
+// which allows the bar to be embedded in a larger program. When the context
+// is cancelled, all modules are stopped, the final output is written, and
+// RunContext returns nil.
+func RunContext(ctx context.Context, modules ...bar.Module) error {
 	// Oauth configs are setup by modules when they're created.
 	// Now that all modules are created, the oauth system knows about all providers.
 	// So if the 'setup-oauth' arg was given, enter interactive setup instead.
@@ -177,15 +203,29 @@ func Run(modules ...bar.Module) error {
 	b.modules = append(b.modules, modules...)
 	b.moduleSet = core.NewModuleSet(b.modules)
 
+	if b.controlSocket != "" {
+		srv, err := control.Listen(b.controlSocket, b.moduleSet)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := srv.Serve(ctx); err != nil {
+				l.Log("Control interface stopped: %v", err)
+			}
+		}()
+	}
+
 	// Mark the bar as started.
 	b.started = true
 	l.Log("Bar started")
 
+	stopped := make(chan struct{})
 	go func(i <-chan int) {
 		for range i {
 			b.refresh()
 		}
-	}(b.moduleSet.Stream())
+		close(stopped)
+	}(b.moduleSet.StreamContext(ctx))
 
 	errChan := make(chan error)
 	// Read events from the input stream, pipe them to the events channel.
@@ -193,26 +233,14 @@ func Run(modules ...bar.Module) error {
 		e <- b.readEvents()
 	}(errChan)
 
-	// Write header.
-	header := i3Header{
-		Version:     1,
-		ClickEvents: true,
-	}
-
+	var opts renderer.Options
 	if !b.suppressSignals {
 		// Go doesn't allow us to handle the default SIGSTOP,
 		// so we'll use SIGUSR1 and SIGUSR2 for pause/resume.
-		header.StopSignal = int(unix.SIGUSR1)
-		header.ContSignal = int(unix.SIGUSR2)
-	}
-	// Set up the encoder for the output stream,
-	// so that module outputs can be written directly.
-	b.encoder = json.NewEncoder(b.writer)
-	if err := b.encoder.Encode(&header); err != nil {
-		return err
+		opts.StopSignal = unix.SIGUSR1
+		opts.ContSignal = unix.SIGUSR2
 	}
-	// Start the infinite array.
-	if _, err := io.WriteString(b.writer, "["); err != nil {
+	if err := b.renderer.Start(b.writer, opts); err != nil {
 		return err
 	}
 
@@ -227,6 +255,11 @@ func Run(modules ...bar.Module) error {
 			if err := b.print(); err != nil {
 				return err
 			}
+		case <-ctx.Done():
+			l.Log("Bar stopping")
+			<-stopped
+			// Flush the last output from all modules before returning.
+			return b.print()
 		case event := <-b.events:
 			if onClick, ok := b.clickHandlers[event.Name]; ok {
 				go onClick(event.Event)
@@ -239,62 +272,22 @@ func Run(modules ...bar.Module) error {
 				b.resume()
 			}
 		case err := <-errChan:
-			return err
+			if err != nil {
+				return err
+			}
+			// The renderer does not support events, but the bar should
+			// continue to run.
+			errChan = nil
 		}
 	}
 }
 
 // DefaultErrorHandler invokes i3-nagbar to show the full error message.
+// On sway, or to use desktop notifications instead, see notify.ErrorHandler.
 func DefaultErrorHandler(e bar.ErrorEvent) {
 	exec.Command("i3-nagbar", "-m", e.Error.Error()).Run()
 }
 
-func colorString(c color.Color) string {
-	cful, _ := colorful.MakeColor(c)
-	return cful.Hex()
-}
-
-// i3map serialises the attributes of the Segment in
-// the format used by i3bar.
-func i3map(s *bar.Segment) map[string]interface{} {
-	i3map := make(map[string]interface{})
-	txt, pango := s.Content()
-	i3map["full_text"] = txt
-	if shortText, ok := s.GetShortText(); ok {
-		i3map["short_text"] = shortText
-	}
-	if color, ok := s.GetColor(); ok {
-		i3map["color"] = colorString(color)
-	}
-	if background, ok := s.GetBackground(); ok {
-		i3map["background"] = colorString(background)
-	}
-	if border, ok := s.GetBorder(); ok {
-		i3map["border"] = colorString(border)
-	}
-	if minWidth, ok := s.GetMinWidth(); ok {
-		i3map["min_width"] = minWidth
-	}
-	if align, ok := s.GetAlignment(); ok {
-		i3map["align"] = align
-	}
-	if urgent, ok := s.IsUrgent(); ok {
-		i3map["urgent"] = urgent
-	}
-	if separator, ok := s.HasSeparator(); ok {
-		i3map["separator"] = separator
-	}
-	if padding, ok := s.GetPadding(); ok {
-		i3map["separator_block_width"] = padding
-	}
-	if pango {
-		i3map["markup"] = "pango"
-	} else {
-		i3map["markup"] = "none"
-	}
-	return i3map
-}
-
 // print outputs the entire bar, using the last output for each module.
 func (b *i3Bar) print() error {
 	// Store the set of click handlers for any segments that can handle clicks.
@@ -303,17 +296,21 @@ func (b *i3Bar) print() error {
 	b.clickHandlers = map[string]func(bar.Event){}
 	// i3bar requires the entire bar to be printed at once, so we just take the
 	// last cached value for each module and construct the current bar.
-	output := make([]map[string]interface{}, 0)
-	for _, segments := range b.moduleSet.LastOutputs() {
-		for _, segment := range segments {
-			out := i3map(segment)
+	var output []renderer.NamedSegment
+	for i, segments := range b.moduleSet.LastOutputs() {
+		ids := map[string]bool{}
+		for j, segment := range segments {
+			out := renderer.NamedSegment{Segment: segment}
 			var clickHandler func(bar.Event)
+			kind := "m"
 			if err := segment.GetError(); err != nil {
+				kind = "e"
 				// because go.
 				segment := segment
+				restart := b.moduleSet.Module(i).Restart
 				clickHandler = func(e bar.Event) {
 					if e.Button == bar.ButtonRight {
-						b.errorHandler(bar.ErrorEvent{Error: err, Event: e})
+						b.errorHandler(bar.ErrorEvent{Error: err, Restart: restart, Event: e})
 					} else {
 						segment.Click(e)
 					}
@@ -322,37 +319,47 @@ func (b *i3Bar) print() error {
 				clickHandler = segment.Click
 			}
 			if clickHandler != nil {
-				name := strconv.Itoa(len(b.clickHandlers))
-				out["name"] = name
-				b.clickHandlers[name] = clickHandler
+				out.Name = segmentName(kind, i, j, segment, ids)
+				b.clickHandlers[out.Name] = withSegmentID(segment, clickHandler)
 			}
 			output = append(output, out)
 		}
 	}
-	if err := b.encoder.Encode(output); err != nil {
-		return err
+	return b.renderer.Render(b.writer, output)
+}
+
+// segmentName returns a name for the segment that identifies it in click
+// events. Names are derived from the module index, and the segment's
+// identifier if it has a unique one, or its position within the module's
+// output otherwise. This ensures that a click received after the bar has been
+// updated is delivered to the same segment, or to the same module if the
+// segments have changed. Error segments use a different prefix, so a click on
+// an error is never delivered to the module's regular output.
+func segmentName(kind string, moduleIdx, segmentIdx int, s *bar.Segment, ids map[string]bool) string {
+	if id, ok := s.GetID(); ok && !ids[id] {
+		ids[id] = true
+		return fmt.Sprintf("%s/%d/id/%s", kind, moduleIdx, id)
 	}
-	_, err := io.WriteString(b.writer, ",\n")
-	return err
+	return fmt.Sprintf("%s/%d/%d", kind, moduleIdx, segmentIdx)
 }
 
-// readEvents parses the infinite stream of events received from i3.
-func (b *i3Bar) readEvents() error {
-	decoder := json.NewDecoder(b.reader)
-	// Consume opening '['
-	_, err := decoder.Token()
-	if err != nil {
-		return err
+// withSegmentID wraps a click handler to set the SegmentID of events to the
+// segment's identifier, since not all bars report it in click events.
+func withSegmentID(s *bar.Segment, handler func(bar.Event)) func(bar.Event) {
+	id, ok := s.GetID()
+	if !ok {
+		return handler
 	}
-	for decoder.More() {
-		var event i3Event
-		err = decoder.Decode(&event)
-		if err != nil {
-			return err
-		}
-		b.events <- event
+	return func(e bar.Event) {
+		e.SegmentID = id
+		handler(e)
 	}
-	return errors.New("stdin exhausted")
+}
+
+// readEvents reads click events using the renderer,
+// and pipes them to the events channel.
+func (b *i3Bar) readEvents() error {
+	return b.renderer.ReadEvents(b.reader, b.events)
 }
 
 // pause instructs all pausable modules to suspend processing.
@@ -15,17 +15,20 @@
 package barista
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"image/color"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/control"
 	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/renderer"
 	"github.com/soumya92/barista/testing/mockio"
 	testModule "github.com/soumya92/barista/testing/module"
 	"github.com/soumya92/barista/timing"
@@ -475,6 +478,142 @@ func TestSignalHandlingSuppression(t *testing.T) {
 	signal.Stop(signalChan)
 }
 
+func TestRenderer(t *testing.T) {
+	mockStdin := mockio.Stdin()
+	mockStdout := mockio.Stdout()
+	TestMode(mockStdin, mockStdout)
+
+	module := testModule.New(t)
+	Add(module)
+	require.NotPanics(t,
+		func() { SetRenderer(renderer.Lemonbar()) },
+		"Can set renderer before Run")
+	go Run()
+	module.AssertStarted()
+
+	module.OutputText("foo")
+	out, err := mockStdout.ReadUntil('\n', time.Second)
+	require.NoError(t, err, "output was written")
+	require.Contains(t, out, "foo", "output uses lemonbar format")
+	require.Contains(t, out, "%{A1:1 m/0/0:}", "output uses lemonbar format")
+
+	mockStdin.WriteString("1 m/0/0\n")
+	module.AssertClicked("click events use lemonbar format")
+
+	require.Panics(t,
+		func() { SetRenderer(renderer.I3Bar()) },
+		"Cannot change renderer after Run")
+}
+
+func TestRunContext(t *testing.T) {
+	mockStdin := mockio.Stdin()
+	mockStdout := mockio.Stdout()
+	TestMode(mockStdin, mockStdout)
+
+	module := testModule.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() { errChan <- RunContext(ctx, module) }()
+
+	_, err := mockStdout.ReadUntil('[', time.Second)
+	require.Nil(t, err, "output array started without any errors")
+	module.AssertStarted()
+	module.OutputText("foo")
+	require.Equal(t, []string{"foo"}, readOutputTexts(t, mockStdout))
+
+	cancel()
+	select {
+	case err := <-errChan:
+		require.NoError(t, err, "no error on cancellation")
+	case <-time.After(time.Second):
+		require.Fail(t, "RunContext did not return on cancellation")
+	}
+	require.Equal(t, []string{"foo"}, readOutputTexts(t, mockStdout),
+		"final output is written on exit")
+
+	module.OutputText("bar")
+	require.False(t, mockStdout.WaitForWrite(10*time.Millisecond),
+		"no output after exit")
+}
+
+func TestControlSocket(t *testing.T) {
+	mockStdin := mockio.Stdin()
+	mockStdout := mockio.Stdout()
+	TestMode(mockStdin, mockStdout)
+
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	SetControlSocket(path)
+	module := testModule.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go RunContext(ctx, module)
+	module.AssertStarted()
+
+	client, err := control.Dial(path)
+	require.NoError(t, err, "control socket is available")
+	defer client.Close()
+	resp, err := client.Send(control.Request{Command: control.List})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Modules))
+
+	require.Panics(t,
+		func() { SetControlSocket("") },
+		"Cannot change control socket after Run")
+}
+
+func TestSegmentIdentifiers(t *testing.T) {
+	mockStdin := mockio.Stdin()
+	mockStdout := mockio.Stdout()
+	TestMode(mockStdin, mockStdout)
+
+	module1 := testModule.New(t)
+	module2 := testModule.New(t)
+	go Run(module1, module2)
+
+	module1.AssertStarted()
+	module2.AssertStarted()
+	mockStdin.WriteString("[")
+	mockStdout.ReadUntil('[', time.Second)
+
+	module1.Output(outputs.Group(
+		bar.TextSegment("a").Identifier("x"),
+		bar.TextSegment("b").Identifier("y"),
+		bar.TextSegment("c").Identifier("y"),
+		bar.TextSegment("d"),
+	))
+	out := readOutput(t, mockStdout)
+	require.Equal(t, "x", out[0]["instance"], "identifier is sent as instance")
+	require.Equal(t, "y", out[1]["instance"])
+	require.Nil(t, out[3]["instance"], "no instance without identifier")
+	names := map[string]bool{}
+	for _, o := range out {
+		names[o["name"].(string)] = true
+	}
+	require.Len(t, names, 4, "names are unique even with duplicate identifiers")
+	yName := out[1]["name"].(string)
+
+	module2.Output(bar.TextSegment("e").Identifier("y"))
+	out = readOutput(t, mockStdout)
+	require.NotEqual(t, yName, out[4]["name"], "names are unique across modules")
+
+	module1.Output(outputs.Group(
+		bar.TextSegment("b").Identifier("y"),
+		bar.TextSegment("a").Identifier("x"),
+	))
+	out = readOutput(t, mockStdout)
+	require.Equal(t, yName, out[0]["name"], "name follows the identifier")
+
+	mockStdin.WriteString(fmt.Sprintf(`{"name": "%s", "button": 1},`, yName))
+	evt := module1.AssertClicked("on click of identified segment")
+	require.Equal(t, "y", evt.SegmentID, "SegmentID is set from the segment")
+	module2.AssertNotClicked("other modules do not receive the event")
+
+	mockStdin.WriteString(fmt.Sprintf(
+		`{"name": "%s", "instance": "y", "button": 1},`, out[2]["name"]))
+	evt = module2.AssertClicked("on click of identified segment")
+	require.Equal(t, "y", evt.SegmentID, "SegmentID is set from the instance")
+}
+
 func TestErrorHandling(t *testing.T) {
 	mockStdin := mockio.Stdin()
 	mockStdout := mockio.Stdout()
@@ -550,6 +689,24 @@ func TestErrorHandling(t *testing.T) {
 	mockStdin.WriteString(fmt.Sprintf(`{"name": "%s", "button": 1},`, regularSegmentName))
 	require.Equal(t, []string{"regular"}, readOutputTexts(t, mockStdout),
 		"restarting from regular segment also clears errors")
+
+	module.Output(outputsWithError)
+	out = readOutput(t, mockStdout)
+	errorSegmentName = out[0]["name"].(string)
+	module.Close()
+	readOutput(t, mockStdout)
+
+	mockStdin.WriteString(fmt.Sprintf(`{"name": "%s", "button": 3},`, errorSegmentName))
+	select {
+	case e := <-errChan:
+		require.NotNil(t, e.Restart, "error event has restart function")
+		e.Restart()
+	case <-time.After(time.Second):
+		require.Fail(t, "should trigger error handler on right click")
+	}
+	require.Equal(t, []string{"regular"}, readOutputTexts(t, mockStdout),
+		"restarting from error handler clears errors")
+	module.AssertStarted()
 }
 
 func testIoError(
@@ -637,87 +794,3 @@ func TestIOErrors(t *testing.T) {
 			in.WriteString(`{"foo": $$}`)
 		}, "on stdin invalid json")
 }
-
-type segmentAssertions struct {
-	*testing.T
-	actual   *bar.Segment
-	Expected map[string]string
-}
-
-func (s segmentAssertions) AssertEqual(message string) {
-	actualMap := make(map[string]string)
-	for k, v := range i3map(s.actual) {
-		actualMap[k] = fmt.Sprintf("%v", v)
-	}
-	require.Equal(s.T, s.Expected, actualMap, message)
-}
-
-func TestI3Map(t *testing.T) {
-	segment := bar.TextSegment("test")
-	a := segmentAssertions{t, segment, make(map[string]string)}
-
-	a.Expected["full_text"] = "test"
-	a.Expected["markup"] = "none"
-	a.AssertEqual("sets full_text")
-
-	segment2 := segment.ShortText("t")
-	a2 := segmentAssertions{t, segment2, make(map[string]string)}
-	a2.Expected["full_text"] = "test"
-	a2.Expected["short_text"] = "t"
-	a2.Expected["markup"] = "none"
-	a2.AssertEqual("sets short_text, does not lose full_text")
-
-	segment3 := bar.PangoSegment("<b>bold</b>")
-	a3 := segmentAssertions{t, segment3, make(map[string]string)}
-	a3.Expected["full_text"] = "<b>bold</b>"
-	a3.Expected["markup"] = "pango"
-	a3.AssertEqual("markup set for pango segment")
-
-	a.Expected["short_text"] = "t"
-	a.AssertEqual("mutates in place")
-
-	segment.Color(color.RGBA{0xff, 0x00, 0x00, 0xff})
-	a.Expected["color"] = "#ff0000"
-	a.AssertEqual("sets color value")
-
-	segment.Color(nil)
-	delete(a.Expected, "color")
-	a.AssertEqual("clears color value when blank")
-
-	segment.Background(nil)
-	a.AssertEqual("clearing unset color works")
-
-	segment.Background(color.RGBA{0x00, 0x77, 0x00, 0x77})
-	a.Expected["background"] = "#00ff00"
-	a.AssertEqual("sets background color")
-
-	segment.Border(color.Transparent)
-	// i3 doesn't support alpha in colours yet.
-	a.Expected["border"] = "#000000"
-	a.AssertEqual("sets border color")
-
-	segment.Align(bar.AlignStart)
-	a.Expected["align"] = "left"
-	a.AssertEqual("alignment strings are preserved")
-
-	segment.MinWidth(10)
-	a.Expected["min_width"] = "10"
-	a.AssertEqual("sets min width in px")
-
-	segment.MinWidthPlaceholder("00:00")
-	a.Expected["min_width"] = "00:00"
-	a.AssertEqual("sets min width placeholder")
-
-	// sanity check default go values.
-	segment.Separator(false)
-	a.Expected["separator"] = "false"
-	a.AssertEqual("separator = false")
-
-	segment.Padding(0)
-	a.Expected["separator_block_width"] = "0"
-	a.AssertEqual("separator width = 0")
-
-	segment.Urgent(false)
-	a.Expected["urgent"] = "false"
-	a.AssertEqual("urgent = false")
-}
@@ -67,6 +67,30 @@ func ButtonE(handler func(bar.Event), btns ...bar.Button) func(bar.Event) {
 	}
 }
 
+// ignoredModifiers are toggled rather than held down, so they are ignored when
+// matching modifiers. This means that e.g. a Ctrl+click handler will continue
+// to work when num lock is on.
+const ignoredModifiers = bar.ModLock | bar.Mod2
+
+// Modified wraps the click handler so that it is only triggered when exactly
+// the given modifiers are held down (ignoring caps lock and num lock).
+// Use Modified(0, handler) to ignore modified clicks.
+func Modified(mods bar.Modifier, handler func(bar.Event)) func(bar.Event) {
+	return func(e bar.Event) {
+		if e.Modifiers&^ignoredModifiers == mods {
+			handler(e)
+		}
+	}
+}
+
+// ButtonMod invokes the given function when any of the specified buttons
+// trigger the event handler while the given modifiers are held down. For
+// example, ButtonMod(do, bar.ModShift, bar.ScrollUp, bar.ScrollDown) handles
+// only shift+scroll.
+func ButtonMod(do func(bar.Button), mods bar.Modifier, btns ...bar.Button) func(bar.Event) {
+	return Modified(mods, Button(do, btns...))
+}
+
 // RunLeft executes the given command on a left-click. This is a shortcut for
 // click.Left(func(){exec.Command(cmd).Run()}).
 func RunLeft(cmd string, args ...string) func(bar.Event) {
@@ -85,17 +109,46 @@ type Map map[bar.Button]func(bar.Event)
 func (m Map) Handle(e bar.Event) {
 	if handler, ok := m[e.Button]; ok {
 		handler(e)
-	} else if fallback, ok := m[fallbackButton]; ok {
+	} else {
+		m.fallback(e)
+	}
+}
+
+func (m Map) fallback(e bar.Event) {
+	if fallback, ok := m[fallbackButton]; ok {
 		fallback(e)
 	}
 }
 
 // Set sets the click handler for a button, and returns the map for chaining.
+// This replaces any existing handlers for the button, including any handlers
+// for the button with modifiers.
 func (m Map) Set(btn bar.Button, handler func(bar.Event)) Map {
 	m[btn] = handler
 	return m
 }
 
+// SetMod sets the click handler for a button when exactly the given modifiers
+// are held down (ignoring caps lock and num lock), and returns the map for
+// chaining. Events with other modifiers are passed to the existing handler
+// for the button, so SetMod should be called after Set, e.g.
+//
+//	Map{}.ScrollUp(louder).SetMod(bar.ScrollUp, bar.ModShift, slightlyLouder)
+func (m Map) SetMod(btn bar.Button, mods bar.Modifier, handler func(bar.Event)) Map {
+	mods &^= ignoredModifiers
+	existing, hasExisting := m[btn]
+	return m.Set(btn, func(e bar.Event) {
+		switch {
+		case e.Modifiers&^ignoredModifiers == mods:
+			handler(e)
+		case hasExisting:
+			existing(e)
+		default:
+			m.fallback(e)
+		}
+	})
+}
+
 // Else sets the click handler for all buttons that don't already have one.
 func (m Map) Else(handler func(bar.Event)) Map {
 	return m.Set(fallbackButton, handler)
@@ -223,6 +223,80 @@ func verifySingleButton(t *testing.T, btn bar.Button, handler func(bar.Event),
 	}
 }
 
+func triggerModified(handler func(bar.Event), mods bar.Modifier, btn bar.Button) {
+	e := randomEvent(btn)
+	e.Modifiers = mods
+	handler(e)
+}
+
+func TestModified(t *testing.T) {
+	handler, check := makeHandler()
+	handler = Modified(bar.ModShift, handler)
+
+	triggerHandler(handler, bar.ButtonLeft)
+	require.Equal(t, notClicked, check(), "without modifiers")
+
+	triggerModified(handler, bar.ModShift|bar.ModControl, bar.ButtonLeft)
+	require.Equal(t, notClicked, check(), "with additional modifiers")
+
+	triggerModified(handler, bar.ModShift, bar.ButtonLeft)
+	require.Equal(t, bar.ButtonLeft, check(), "with exact modifiers")
+
+	triggerModified(handler, bar.ModShift|bar.Mod2|bar.ModLock, bar.ScrollUp)
+	require.Equal(t, bar.ScrollUp, check(), "lock modifiers are ignored")
+
+	do, checkDo := makeFunc()
+	handler = ButtonMod(func(bar.Button) { do() }, bar.ModControl, bar.ButtonRight)
+	triggerModified(handler, bar.ModControl, bar.ButtonLeft)
+	require.False(t, checkDo(), "with wrong button")
+	triggerHandler(handler, bar.ButtonRight)
+	require.False(t, checkDo(), "without modifiers")
+	triggerModified(handler, bar.ModControl, bar.ButtonRight)
+	require.True(t, checkDo(), "with button and modifiers")
+
+	handler, check = makeHandler()
+	handler = Modified(0, handler)
+	triggerModified(handler, bar.Mod4, bar.ButtonLeft)
+	require.Equal(t, notClicked, check(), "with modifiers")
+	triggerModified(handler, bar.Mod2, bar.ButtonLeft)
+	require.Equal(t, bar.ButtonLeft, check(), "with only num lock")
+}
+
+func TestClickMapModifiers(t *testing.T) {
+	handlerUp, checkUp := makeHandler()
+	handlerShiftUp, checkShiftUp := makeHandler()
+	handlerCtrlLeft, checkCtrlLeft := makeHandler()
+	funcElse, checkElse := makeFunc()
+
+	handler := Map{}.
+		ScrollUpE(handlerUp).
+		SetMod(bar.ScrollUp, bar.ModShift, handlerShiftUp).
+		SetMod(bar.ButtonLeft, bar.ModControl|bar.Mod2, handlerCtrlLeft).
+		Else(DiscardEvent(funcElse)).
+		Handle
+
+	triggerHandler(handler, bar.ScrollUp)
+	require.Equal(t, bar.ScrollUp, checkUp(), "unmodified handler")
+	require.Equal(t, notClicked, checkShiftUp())
+
+	triggerModified(handler, bar.ModShift, bar.ScrollUp)
+	require.Equal(t, bar.ScrollUp, checkShiftUp(), "modified handler")
+	require.Equal(t, notClicked, checkUp())
+
+	triggerModified(handler, bar.ModControl, bar.ScrollUp)
+	require.Equal(t, bar.ScrollUp, checkUp(), "other modifiers use existing handler")
+	require.Equal(t, notClicked, checkShiftUp())
+
+	triggerModified(handler, bar.ModControl, bar.ButtonLeft)
+	require.Equal(t, bar.ButtonLeft, checkCtrlLeft(),
+		"lock modifiers are ignored when setting handler")
+	require.False(t, checkElse())
+
+	triggerHandler(handler, bar.ButtonLeft)
+	require.True(t, checkElse(), "fallback without existing handler")
+	require.Equal(t, notClicked, checkCtrlLeft())
+}
+
 func TestButtonFuncs(t *testing.T) {
 	buttonFuncs := map[bar.Button]func(func()) func(bar.Event){
 		bar.ButtonLeft:    Left,
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package netlink uses the netlink library to watch for changes in link states.
+// Package netlink uses the netlink library to watch for changes in link states
+// and default routes.
 package netlink
 
 import (
@@ -49,6 +50,32 @@ type Link struct {
 	State        OperState
 	HardwareAddr net.HardwareAddr
 	IPs          []net.IP
+	// DefaultRoutes are the default routes through this link, in order of
+	// preference (lowest metric first).
+	DefaultRoutes []Route
+}
+
+// Route represents a default route through a link.
+type Route struct {
+	// Gateway is the address of the next hop, or nil if the route does not use
+	// a gateway (e.g. point-to-point links such as VPN tunnels).
+	Gateway net.IP
+	Metric  int
+	// Table is the routing table that contains the route. Default routes in
+	// tables other than main are included, since tools such as wg-quick use
+	// policy routing to send all traffic through a tunnel.
+	Table int
+	// Family is the address family of the route, unix.AF_INET or
+	// unix.AF_INET6. A link can have a default route for each family, e.g.
+	// wg-quick adds both without a gateway.
+	Family int
+}
+
+func (r Route) equal(other Route) bool {
+	return r.Family == other.Family &&
+		r.Table == other.Table &&
+		r.Metric == other.Metric &&
+		r.Gateway.Equal(other.Gateway)
 }
 
 var (
@@ -80,8 +107,9 @@ func addLink(index LinkIndex, link Link) {
 			return
 		}
 		l.Fine("Updating link %s@%d", link.Name, index)
-		// addLink does not have address information
+		// addLink does not have address or route information
 		link.IPs = oldLink.IPs
+		link.DefaultRoutes = oldLink.DefaultRoutes
 	} else {
 		l.Fine("Adding link %s@%d", link.Name, index)
 	}
@@ -151,6 +179,49 @@ func ipPriority(ip net.IP) int {
 	return len(priorities)
 }
 
+func addRoute(index LinkIndex, route Route) {
+	linksMu.Lock()
+	defer linksMu.Unlock()
+	link, ok := links[index]
+	if !ok {
+		l.Log("Skipping add route for unknown link %d", index)
+		return
+	}
+	for _, oldRoute := range link.DefaultRoutes {
+		if oldRoute.equal(route) {
+			l.Fine("Route %+v for %s@%d already present, skipping add",
+				route, link.Name, index)
+			return
+		}
+	}
+	l.Fine("Adding route %+v for %s@%d", route, link.Name, index)
+	// Copy the routes, since the existing slice is shared with subscribers.
+	routes := append([]Route(nil), link.DefaultRoutes...)
+	link.DefaultRoutes = append(routes, route)
+	sortRoutes(link.DefaultRoutes)
+	links[index] = link
+	notifyChanged(link.Name)
+}
+
+// sortRoutes orders routes the way the kernel would prefer them, by metric.
+// Ties are broken by table, family, and gateway to keep the order
+// deterministic.
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(ai, bi int) bool {
+		a, b := routes[ai], routes[bi]
+		switch {
+		case a.Metric != b.Metric:
+			return a.Metric < b.Metric
+		case a.Table != b.Table:
+			return a.Table < b.Table
+		case a.Family != b.Family:
+			return a.Family < b.Family
+		default:
+			return a.Gateway.String() < b.Gateway.String()
+		}
+	})
+}
+
 func delLink(index LinkIndex) {
 	linksMu.Lock()
 	defer linksMu.Unlock()
@@ -190,6 +261,34 @@ func delIP(index LinkIndex, addr net.IP) {
 	notifyChanged(link.Name)
 }
 
+func delRoute(index LinkIndex, route Route) {
+	linksMu.Lock()
+	defer linksMu.Unlock()
+	link, ok := links[index]
+	if !ok {
+		l.Fine("Skipping delete route for unknown link %d", index)
+		return
+	}
+	exists := false
+	for idx, oldRoute := range link.DefaultRoutes {
+		if oldRoute.equal(route) {
+			exists = true
+			routes := make([]Route, 0, len(link.DefaultRoutes)-1)
+			routes = append(routes, link.DefaultRoutes[:idx]...)
+			link.DefaultRoutes = append(routes, link.DefaultRoutes[idx+1:]...)
+			break
+		}
+	}
+	if !exists {
+		l.Fine("Route %+v for %s@%d not present, skipping delete",
+			route, link.Name, index)
+		return
+	}
+	l.Fine("Deleting route %+v for %s@%d", route, link.Name, index)
+	links[index] = link
+	notifyChanged(link.Name)
+}
+
 func nlInit() {
 	initialData, err := getInitialData()
 	if err != nil {
@@ -213,15 +312,19 @@ var (
 // Subscription represents a potentially filtered subscription to netlink, which
 // returns the best link that matches the filter conditions specified.
 type Subscription struct {
-	C       <-chan struct{}
-	name    string
-	prefix  string
-	value   value.Value // of Link
-	doneSub func()
+	C            <-chan struct{}
+	name         string
+	prefix       string
+	defaultRoute bool
+	value        value.Value // of Link
+	doneSub      func()
 }
 
 func (s *Subscription) matches(name string) bool {
 	switch {
+	case s.defaultRoute:
+		// Any change could affect which link has the best default route.
+		return true
 	case s.name != "":
 		return s.name == name
 	case s.prefix != "":
@@ -232,6 +335,10 @@ func (s *Subscription) matches(name string) bool {
 }
 
 func (s *Subscription) notify(links []Link) {
+	if s.defaultRoute {
+		s.value.Set(bestDefaultRoute(links))
+		return
+	}
 	for _, link := range links {
 		if s.matches(link.Name) {
 			s.value.Set(link)
@@ -241,6 +348,23 @@ func (s *Subscription) notify(links []Link) {
 	s.value.Set(Link{State: Gone})
 }
 
+// bestDefaultRoute returns the link with the lowest metric default route, or
+// a link with state Gone if there are no default routes. Ties are broken by the
+// order of the given links.
+func bestDefaultRoute(links []Link) Link {
+	best := Link{State: Gone}
+	for _, link := range links {
+		if len(link.DefaultRoutes) == 0 {
+			continue
+		}
+		if len(best.DefaultRoutes) == 0 ||
+			link.DefaultRoutes[0].Metric < best.DefaultRoutes[0].Metric {
+			best = link
+		}
+	}
+	return best
+}
+
 func sortedLinks() []Link {
 	allLinks := []Link{}
 	for _, link := range links {
@@ -326,6 +450,13 @@ func Any() *Subscription {
 	return subscribe(new(Subscription))
 }
 
+// DefaultRoute creates a netlink watcher that returns the link carrying the
+// best default route, i.e. the default route with the lowest metric. A
+// 'virtual' link with status Gone is returned if there is no default route.
+func DefaultRoute() *Subscription {
+	return subscribe(&Subscription{defaultRoute: true})
+}
+
 // Get returns the most recent Link that matches the subscription conditions.
 func (s *Subscription) Get() Link {
 	return s.value.Get().(Link)
@@ -366,6 +497,8 @@ type Tester interface {
 	RemoveLink(LinkIndex)
 	AddIP(LinkIndex, net.IP)
 	RemoveIP(LinkIndex, net.IP)
+	AddRoute(LinkIndex, Route)
+	RemoveRoute(LinkIndex, Route)
 }
 
 type tester struct{ lastIdx LinkIndex }
@@ -402,6 +535,14 @@ func (t *tester) RemoveIP(index LinkIndex, addr net.IP) {
 	delIP(index, addr)
 }
 
+func (t *tester) AddRoute(index LinkIndex, route Route) {
+	addRoute(index, route)
+}
+
+func (t *tester) RemoveRoute(index LinkIndex, route Route) {
+	delRoute(index, route)
+}
+
 // TestMode puts the netlink watcher in test mode, and resets the
 // link and subscriber states.
 func TestMode() Tester {
@@ -23,6 +23,7 @@ import (
 
 	"github.com/soumya92/barista/testing/notifier"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
 )
 
 var errFoo = errors.New("foo")
@@ -70,6 +71,14 @@ func TestErrors(t *testing.T) {
 	})
 	require.Empty(t, All().Get(), "no links on error")
 
+	reset()
+	setInitialDataWithRoutes(testNlRequest{}, testNlRequest{}, testNlRequest{err: errFoo})
+	returnCustomSubscriber(func(int, ...uint) (nlReceiver, error) {
+		require.Fail(t, "Should not call subscribe")
+		return nil, nil
+	})
+	require.Empty(t, All().Get(), "no links on error")
+
 	reset()
 	setInitialData(testNlRequest{}, testNlRequest{})
 	returnCustomSubscriber(func(int, ...uint) (nlReceiver, error) {
@@ -289,6 +298,106 @@ func TestFiltering(t *testing.T) {
 	require.Equal(t, wwan0, subAny.Get(), "Re-order on state change")
 }
 
+func TestRoutes(t *testing.T) {
+	reset()
+	_, subnet, _ := net.ParseCIDR("192.168.0.0/24")
+	gw := net.IPv4(192, 168, 0, 254)
+	setInitialDataWithRoutes(testNlRequest{
+		msgs: []syscall.NetlinkMessage{
+			msgNewLink(1, Link{Name: "eno1", State: Up, HardwareAddr: hwA[1]}),
+			msgNewLink(2, Link{Name: "wlan0", State: Up, HardwareAddr: hwA[2]}),
+			msgNewLink(3, Link{Name: "wg0", State: Unknown}),
+		},
+	}, testNlRequest{}, testNlRequest{
+		msgs: []syscall.NetlinkMessage{
+			msgNewSubnetRoute(1, subnet),
+			msgNewRoute(1, Route{Gateway: gw, Metric: 100, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET}),
+			msgNewRoute(2, Route{Gateway: gw, Metric: 600, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET}),
+			msgNewRoute(1, Route{Gateway: gw, Metric: 20, Table: unix.RT_TABLE_LOCAL, Family: unix.AF_INET}),
+			msgNewRoute(5, Route{Gateway: gw, Metric: 10, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET}),
+		},
+	})
+	msgCh, _ := returnTestSubscriber()
+
+	subEth := ByName("eno1")
+	nextEth := subEth.Next()
+	subDef := DefaultRoute()
+	nextDef := subDef.Next()
+
+	eno1 := Link{
+		Name:          "eno1",
+		State:         Up,
+		HardwareAddr:  hwA[1],
+		DefaultRoutes: []Route{{Gateway: gw, Metric: 100, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET}},
+	}
+	require.Equal(t, eno1, subEth.Get(), "only default routes are included")
+	require.Equal(t, eno1, subDef.Get(), "lowest metric default route")
+
+	msgCh <- msgNewSubnetRoute(2, subnet)
+	notifier.AssertNoUpdate(t, nextDef, "on adding a non-default route")
+
+	msgCh <- msgNewRoute(1, Route{Gateway: gw, Metric: 100, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET})
+	notifier.AssertNoUpdate(t, nextEth, "on adding the same route")
+
+	wgRoute := Route{Metric: 0, Table: 51820, Family: unix.AF_INET}
+	msgCh <- msgNewRoute(3, wgRoute)
+	nextDef = assertUpdated(t, nextDef, subDef, "on adding a better route")
+	require.Equal(t, "wg0", subDef.Get().Name)
+	wgRoutes := subDef.Get().DefaultRoutes
+	require.Equal(t, []Route{wgRoute}, wgRoutes)
+	notifier.AssertNoUpdate(t, nextEth, "unrelated link")
+
+	wgRoute6 := Route{Metric: 0, Table: 51820, Family: unix.AF_INET6}
+	msgCh <- msgNewRoute(3, wgRoute6)
+	nextDef = assertUpdated(t, nextDef, subDef, "on adding a route for another family")
+	require.Equal(t, []Route{wgRoute, wgRoute6}, subDef.Get().DefaultRoutes)
+	require.Equal(t, []Route{wgRoute}, wgRoutes, "previous value is unchanged")
+
+	wgRoutes = subDef.Get().DefaultRoutes
+	msgCh <- msgDelRoute(3, wgRoute)
+	nextDef = assertUpdated(t, nextDef, subDef, "on removing a route for one family")
+	require.Equal(t, "wg0", subDef.Get().Name)
+	require.Equal(t, []Route{wgRoute6}, subDef.Get().DefaultRoutes)
+	require.Equal(t, []Route{wgRoute, wgRoute6}, wgRoutes, "previous value is unchanged")
+
+	v6gw := net.ParseIP("fe80::1")
+	msgCh <- msgNewRoute(1, Route{Gateway: v6gw, Metric: 1024, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET6})
+	nextEth = assertUpdated(t, nextEth, subEth, "on adding a route")
+	eno1.DefaultRoutes = append(eno1.DefaultRoutes,
+		Route{Gateway: v6gw, Metric: 1024, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET6})
+	require.Equal(t, eno1, subEth.Get(), "routes are sorted by metric")
+	nextDef = assertUpdated(t, nextDef, subDef, "on any route change")
+	require.Equal(t, "wg0", subDef.Get().Name)
+
+	msgCh <- msgNewLink(1, Link{Name: "eno1", State: Dormant, HardwareAddr: hwA[1]})
+	nextEth = assertUpdated(t, nextEth, subEth)
+	eno1.State = Dormant
+	require.Equal(t, eno1, subEth.Get(), "routes are not lost on link update")
+	nextDef = assertUpdated(t, nextDef, subDef, "on any link change")
+
+	msgCh <- msgDelRoute(3, wgRoute6)
+	nextDef = assertUpdated(t, nextDef, subDef, "on removing the best route")
+	require.Equal(t, eno1, subDef.Get())
+
+	msgCh <- msgDelRoute(3, wgRoute6)
+	notifier.AssertNoUpdate(t, nextDef, "on removing a non-existent route")
+
+	msgCh <- msgDelRoute(1, Route{Gateway: gw, Metric: 100, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET})
+	nextEth = assertUpdated(t, nextEth, subEth, "on removing a route")
+	nextDef = assertUpdated(t, nextDef, subDef, "on removing a route")
+	require.Equal(t, "wlan0", subDef.Get().Name, "metric 600 beats 1024")
+
+	msgCh <- msgDelRoute(1, Route{Gateway: v6gw, Metric: 1024, Table: unix.RT_TABLE_MAIN, Family: unix.AF_INET6})
+	assertUpdated(t, nextEth, subEth, "on removing a route")
+	require.Empty(t, subEth.Get().DefaultRoutes)
+	nextDef = assertUpdated(t, nextDef, subDef, "on removing a route")
+	require.Equal(t, "wlan0", subDef.Get().Name)
+
+	msgCh <- msgDelLink(2, Link{})
+	assertUpdated(t, nextDef, subDef, "on removing the link")
+	require.Equal(t, Link{State: Gone}, subDef.Get(), "no default routes left")
+}
+
 func TestTestMode(t *testing.T) {
 	nlt := TestMode()
 
@@ -311,6 +420,16 @@ func TestTestMode(t *testing.T) {
 	nextAll = assertUpdated(t, nextAll, subAll)
 	notifier.AssertNoUpdate(t, nextEth)
 
+	nlt.AddRoute(id, Route{Gateway: net.IPv4(10, 0, 0, 254)})
+	nextAll = assertUpdated(t, nextAll, subAll)
+	notifier.AssertNoUpdate(t, nextEth)
+	require.Equal(t, "eno1", DefaultRoute().Get().Name)
+
+	nlt.RemoveRoute(id, Route{Gateway: net.IPv4(10, 0, 0, 254)})
+	nextAll = assertUpdated(t, nextAll, subAll)
+	notifier.AssertNoUpdate(t, nextEth)
+	require.Equal(t, Link{State: Gone}, DefaultRoute().Get())
+
 	nlt.RemoveLink(id)
 	nextAll = assertUpdated(t, nextAll, subAll)
 	notifier.AssertNoUpdate(t, nextEth)
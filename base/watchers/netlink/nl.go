@@ -64,6 +64,36 @@ func addrFromMsg(msg []byte) (LinkIndex, net.IP) {
 	return linkIndex, addr
 }
 
+// routeFromMsg returns the link and route for a default route message. ok is
+// false for other routes, which are not tracked.
+func routeFromMsg(msg []byte) (index LinkIndex, route Route, ok bool) {
+	rtmsg := nl.DeserializeRtMsg(msg)
+	if rtmsg.Dst_len != 0 || rtmsg.Type != unix.RTN_UNICAST {
+		return 0, route, false
+	}
+	route.Table = int(rtmsg.Table)
+	route.Family = int(rtmsg.Family)
+	attrs, _ := nl.ParseRouteAttr(msg[rtmsg.Len():])
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case unix.RTA_TABLE:
+			// Tables above 255 are only reported in RTA_TABLE.
+			route.Table = int(native.Uint32(attr.Value[0:4]))
+		case unix.RTA_OIF:
+			index = LinkIndex(native.Uint32(attr.Value[0:4]))
+		case unix.RTA_GATEWAY:
+			route.Gateway = net.IP(attr.Value)
+		case unix.RTA_PRIORITY:
+			route.Metric = int(native.Uint32(attr.Value[0:4]))
+		}
+	}
+	// Multipath routes have no single output interface, and are ignored.
+	if index == 0 || route.Table == unix.RT_TABLE_LOCAL {
+		return 0, route, false
+	}
+	return index, route, true
+}
+
 // for tests.
 type nlRequest interface {
 	AddData(nl.NetlinkRequestData)
@@ -119,6 +149,29 @@ func getInitialData() (map[LinkIndex]Link, error) {
 		links[idx] = link
 	}
 
+	req = newNlRequest(unix.RTM_GETROUTE, unix.NLM_F_DUMP)
+	req.AddData(&nl.RtMsg{RtMsg: unix.RtMsg{Family: unix.AF_UNSPEC}})
+	msgs, err = req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWROUTE)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range msgs {
+		idx, route, ok := routeFromMsg(msg)
+		if !ok {
+			continue
+		}
+		link, ok := links[idx]
+		if !ok {
+			l.Log("Got route for unknown link %d", idx)
+			continue
+		}
+		l.Fine("Got route %+v for %s@%d", route, link.Name, idx)
+		routes := append([]Route(nil), link.DefaultRoutes...)
+		link.DefaultRoutes = append(routes, route)
+		sortRoutes(link.DefaultRoutes)
+		links[idx] = link
+	}
+
 	return links, nil
 }
 
@@ -129,6 +182,8 @@ func nlListen() {
 		unix.RTNLGRP_LINK,
 		unix.RTNLGRP_IPV4_IFADDR,
 		unix.RTNLGRP_IPV6_IFADDR,
+		unix.RTNLGRP_IPV4_ROUTE,
+		unix.RTNLGRP_IPV6_ROUTE,
 	)
 	nlMu.RUnlock()
 	if err != nil {
@@ -152,6 +207,14 @@ func nlListen() {
 				addIP(addrFromMsg(msg.Data))
 			case unix.RTM_DELADDR:
 				delIP(addrFromMsg(msg.Data))
+			case unix.RTM_NEWROUTE:
+				if idx, route, ok := routeFromMsg(msg.Data); ok {
+					addRoute(idx, route)
+				}
+			case unix.RTM_DELROUTE:
+				if idx, route, ok := routeFromMsg(msg.Data); ok {
+					delRoute(idx, route)
+				}
 			}
 		}
 	}
@@ -70,6 +70,10 @@ func (t testNlRequest) Execute(int, uint16) ([][]byte, error) {
 }
 
 func setInitialData(getLinks, getAddrs testNlRequest) {
+	setInitialDataWithRoutes(getLinks, getAddrs, testNlRequest{})
+}
+
+func setInitialDataWithRoutes(getLinks, getAddrs, getRoutes testNlRequest) {
 	nlMu.Lock()
 	defer nlMu.Unlock()
 	newNlRequest = func(proto, flags int) nlRequest {
@@ -78,6 +82,8 @@ func setInitialData(getLinks, getAddrs testNlRequest) {
 			return getLinks
 		case unix.RTM_GETADDR:
 			return getAddrs
+		case unix.RTM_GETROUTE:
+			return getRoutes
 		default:
 			return testNlRequest{nil, errors.New("unexpected request")}
 		}
@@ -135,3 +141,42 @@ func msgDelAddrs(linkIdx int, addr, localAddr net.IP) syscall.NetlinkMessage {
 	m.Header.Type = unix.RTM_DELADDR
 	return m
 }
+
+func msgNewRoute(linkIdx int, r Route) syscall.NetlinkMessage {
+	data := &nl.RtMsg{RtMsg: unix.RtMsg{
+		Family: uint8(r.Family),
+		Table:  unix.RT_TABLE_UNSPEC,
+		Type:   unix.RTN_UNICAST,
+	}}
+	attrs := []*nl.RtAttr{
+		nl.NewRtAttr(unix.RTA_TABLE, nl.Uint32Attr(uint32(r.Table))),
+		nl.NewRtAttr(unix.RTA_OIF, nl.Uint32Attr(uint32(linkIdx))),
+		nl.NewRtAttr(unix.RTA_PRIORITY, nl.Uint32Attr(uint32(r.Metric))),
+	}
+	if r.Gateway != nil {
+		attrs = append(attrs, nl.NewRtAttr(unix.RTA_GATEWAY, r.Gateway))
+	}
+	return makeNetlinkMessage(unix.RTM_NEWROUTE, data, attrs...)
+}
+
+func msgDelRoute(linkIdx int, r Route) syscall.NetlinkMessage {
+	m := msgNewRoute(linkIdx, r)
+	m.Header.Type = unix.RTM_DELROUTE
+	return m
+}
+
+// msgNewSubnetRoute returns a message for a non-default route, which should be
+// ignored.
+func msgNewSubnetRoute(linkIdx int, dst *net.IPNet) syscall.NetlinkMessage {
+	ones, _ := dst.Mask.Size()
+	data := &nl.RtMsg{RtMsg: unix.RtMsg{
+		Family:  uint8(nl.GetIPFamily(dst.IP)),
+		Dst_len: uint8(ones),
+		Table:   unix.RT_TABLE_MAIN,
+		Type:    unix.RTN_UNICAST,
+	}}
+	return makeNetlinkMessage(unix.RTM_NEWROUTE, data,
+		nl.NewRtAttr(unix.RTA_DST, dst.IP),
+		nl.NewRtAttr(unix.RTA_OIF, nl.Uint32Attr(uint32(linkIdx))),
+	)
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// barista-ctl sends commands to the control interface of a running bar.
+// The bar must enable the control interface using barista.SetControlSocket.
+//
+// For example, to switch to the next module in a switching group registered
+// as "weather" using an i3 keybinding:
+//
+//	bindsym $mod+w exec barista-ctl switch weather next
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/control"
+)
+
+const usage = `Usage: barista-ctl [-socket path] command [args...]
+
+Commands:
+  list                                   list modules and groups
+  outputs                                show the last output of each module
+  refresh MODULE                         refresh a module
+  restart MODULE                         restart a finished module
+  click MODULE [SEGMENT [BUTTON [MOD...]]] click a module's output
+  modal NAME [MODE]                      activate a mode, or reset if no mode
+  switch NAME next|prev|INDEX            switch the module shown in a group
+
+MODULE is either the index of the module on the bar, or its logging ID.
+Logging IDs are only available if the bar is built with the baristadebuglog
+tag, otherwise modules must be identified by index. Modules inside another
+module, e.g. a configuration file, are identified by NAME/INDEX, as shown in
+the "nested" list output.
+BUTTON is an X11 button number (default 1), and MOD is a modifier name, e.g.
+Shift, Control, Mod1, or Mod4.
+`
+
+func main() {
+	socket := flag.String("socket", control.DefaultSocket(), "path of the control socket")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	req, err := parseRequest(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client, err := control.Dial(*socket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer client.Close()
+	resp, err := client.Send(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if req.Command == control.List || req.Command == control.Outputs {
+		out, _ := json.MarshalIndent(resp, "", "  ")
+		fmt.Println(string(out))
+	}
+	if req.Command == control.List && !hasIDs(resp.Modules) && len(resp.Modules) > 0 {
+		fmt.Fprintln(os.Stderr, "note: module IDs require the baristadebuglog build tag, use indices instead")
+	}
+}
+
+func hasIDs(modules []control.ModuleInfo) bool {
+	for _, m := range modules {
+		if m.ID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRequest(args []string) (control.Request, error) {
+	if len(args) == 0 {
+		return control.Request{}, fmt.Errorf("missing command")
+	}
+	req := control.Request{Command: args[0]}
+	args = args[1:]
+	switch req.Command {
+	case control.List, control.Outputs:
+		return req, checkArgs(args, 0, 0)
+	case control.Refresh, control.Restart:
+		if err := checkArgs(args, 1, 1); err != nil {
+			return req, err
+		}
+		req.Module = args[0]
+	case control.Click:
+		if err := checkArgs(args, 1, -1); err != nil {
+			return req, err
+		}
+		req.Module = args[0]
+		if len(args) > 1 {
+			seg, err := strconv.Atoi(args[1])
+			if err != nil {
+				return req, fmt.Errorf("invalid segment %q", args[1])
+			}
+			req.Segment = seg
+		}
+		if len(args) > 2 {
+			e, err := parseEvent(args[2], args[3:])
+			if err != nil {
+				return req, err
+			}
+			req.Event = &e
+		}
+	case control.Modal:
+		if err := checkArgs(args, 1, 2); err != nil {
+			return req, err
+		}
+		req.Name = args[0]
+		if len(args) > 1 {
+			req.Mode = args[1]
+		}
+	case control.Switch:
+		if err := checkArgs(args, 2, 2); err != nil {
+			return req, err
+		}
+		req.Name = args[0]
+		switch args[1] {
+		case "next":
+			req.Delta = 1
+		case "prev", "previous":
+			req.Delta = -1
+		default:
+			idx, err := strconv.Atoi(args[1])
+			if err != nil {
+				return req, fmt.Errorf("invalid index %q", args[1])
+			}
+			req.Index = &idx
+		}
+	default:
+		return req, fmt.Errorf("unknown command %q", req.Command)
+	}
+	return req, nil
+}
+
+func checkArgs(args []string, min, max int) error {
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		return fmt.Errorf("wrong number of arguments")
+	}
+	return nil
+}
+
+func parseEvent(button string, modifiers []string) (bar.Event, error) {
+	var e bar.Event
+	btn, err := strconv.Atoi(button)
+	if err != nil {
+		return e, fmt.Errorf("invalid button %q", button)
+	}
+	e.Button = bar.Button(btn)
+	if len(modifiers) > 0 {
+		mods, _ := json.Marshal(modifiers)
+		if err := json.Unmarshal(mods, &e.Modifiers); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/modules/ac"
+	"github.com/soumya92/barista/modules/backlight"
+	"github.com/soumya92/barista/modules/battery"
+	"github.com/soumya92/barista/modules/clock"
+	"github.com/soumya92/barista/modules/cpuload"
+	"github.com/soumya92/barista/modules/cputemp"
+	"github.com/soumya92/barista/modules/cpuusage"
+	"github.com/soumya92/barista/modules/diskio"
+	"github.com/soumya92/barista/modules/diskspace"
+	"github.com/soumya92/barista/modules/hwmon"
+	"github.com/soumya92/barista/modules/i3"
+	"github.com/soumya92/barista/modules/lockkeys"
+	"github.com/soumya92/barista/modules/meminfo"
+	"github.com/soumya92/barista/modules/netinfo"
+	"github.com/soumya92/barista/modules/netspeed"
+	"github.com/soumya92/barista/modules/networkmanager"
+	"github.com/soumya92/barista/modules/pressure"
+	"github.com/soumya92/barista/modules/rapl"
+	"github.com/soumya92/barista/modules/shell"
+	"github.com/soumya92/barista/modules/sysinfo"
+	"github.com/soumya92/barista/modules/systemd"
+	"github.com/soumya92/barista/modules/top"
+	"github.com/soumya92/barista/modules/vpn"
+	"github.com/soumya92/barista/modules/wlan"
+)
+
+func init() {
+	Register("ac", ac.New)
+	Register("backlight", backlight.New)
+	Register("backlight.device", backlight.Device)
+	Register("battery.all", battery.All)
+	Register("battery.named", battery.Named)
+	Register("battery.upower", battery.UPower)
+	Register("battery.upower_display", battery.UPowerDisplay)
+	Register("clock.local", func() *clockModule { return newClock(clock.Local()) })
+	Register("clock.zone", func(name string) (*clockModule, error) {
+		c, err := clock.ZoneByName(name)
+		if err != nil {
+			return nil, err
+		}
+		return newClock(c), nil
+	})
+	Register("cpuload", cpuload.New)
+	Register("cputemp", cputemp.New)
+	Register("cputemp.type", cputemp.OfType)
+	Register("cputemp.zone", cputemp.Zone)
+	Register("cpuusage", func() cpuusageModule { return cpuusageModule{cpuusage.New()} })
+	Register("diskio", func(disk string) diskioModule { return diskioModule{diskio.New(disk)} })
+	Register("diskspace", diskspace.New)
+	Register("hwmon.sensors", hwmon.Sensors)
+	Register("i3", i3.New)
+	Register("i3.socket", i3.Socket)
+	Register("lockkeys", lockkeys.New)
+	Register("meminfo", func() meminfoModule { return meminfoModule{meminfo.New()} })
+	Register("netinfo", netinfo.New)
+	Register("netinfo.default_route", netinfo.DefaultRoute)
+	Register("netinfo.interface", netinfo.Interface)
+	Register("netinfo.prefix", netinfo.Prefix)
+	Register("netspeed", netspeed.New)
+	Register("networkmanager", networkmanager.New)
+	Register("pressure", pressure.New)
+	Register("pressure.cgroup", pressure.Cgroup)
+	Register("rapl", rapl.New)
+	Register("shell", func(cmd string, args ...string) shellModule {
+		return shellModule{shell.New(cmd, args...)}
+	})
+	Register("shell.tail", shell.Tail)
+	Register("sysinfo", func() sysinfoModule { return sysinfoModule{sysinfo.New()} })
+	Register("systemd.service", systemd.Service)
+	Register("systemd.timer", systemd.Timer)
+	Register("systemd.user_service", systemd.UserService)
+	Register("systemd.user_timer", systemd.UserTimer)
+	Register("top", func(count int) (*top.Module, error) {
+		if count < 0 {
+			return nil, fmt.Errorf("count must not be negative")
+		}
+		return top.New(count), nil
+	})
+	Register("vpn", vpn.New)
+	Register("vpn.default", vpn.DefaultInterface)
+	Register("vpn.wireguard", vpn.WireGuard)
+	Register("wlan", wlan.Named)
+	Register("wlan.any", wlan.Any)
+}
+
+// The adapters below provide the Output and RefreshInterval methods expected
+// by the config for modules with a different API.
+
+// clockModule uses the refresh interval as the clock's granularity, since the
+// clock's Output takes the granularity and the output function together.
+type clockModule struct {
+	*clock.Module
+	granularity time.Duration
+}
+
+func newClock(m *clock.Module) *clockModule {
+	return &clockModule{m, time.Minute}
+}
+
+func (c *clockModule) RefreshInterval(granularity time.Duration) {
+	c.granularity = granularity
+}
+
+func (c *clockModule) Output(outputFunc func(time.Time) bar.Output) {
+	c.Module.Output(c.granularity, outputFunc)
+}
+
+type shellModule struct{ *shell.Module }
+
+func (s shellModule) RefreshInterval(interval time.Duration) {
+	s.Every(interval)
+}
+
+// diskio, meminfo, and sysinfo share a single refresh interval across all
+// instances, so the last one configured wins.
+
+type diskioModule struct{ *diskio.Module }
+
+func (diskioModule) RefreshInterval(interval time.Duration) {
+	diskio.RefreshInterval(interval)
+}
+
+type cpuusageModule struct{ *cpuusage.Module }
+
+func (cpuusageModule) RefreshInterval(interval time.Duration) {
+	cpuusage.RefreshInterval(interval)
+}
+
+type meminfoModule struct{ *meminfo.Module }
+
+func (meminfoModule) RefreshInterval(interval time.Duration) {
+	meminfo.RefreshInterval(interval)
+}
+
+type sysinfoModule struct{ *sysinfo.Module }
+
+func (sysinfoModule) RefreshInterval(interval time.Duration) {
+	sysinfo.RefreshInterval(interval)
+}
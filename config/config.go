@@ -0,0 +1,454 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package config builds a bar from a YAML configuration file, for users who would
+rather not write (and recompile) a Go program to change their bar.
+
+A configuration file looks like:
+
+	colors:
+	  good: "#6d6"
+	  bad: "#d66"
+	modules:
+	- type: diskspace
+	  args: ["/home"]
+	  refresh: 30s
+	  output: '{{bytesize .Available}}'
+	- type: group.collapsing
+	  modules:
+	  - type: meminfo
+	  - type: sysinfo
+	- type: clock.local
+	  output: '{{.Format "15:04"}}'
+	  color: good
+	  click:
+	    left: gsimplecal
+
+Each module's type refers to a constructor added using Register. Common
+modules are registered by this package, and Types lists all registered types.
+Unknown keys are reported as errors, to catch typos early.
+
+Use Load and Build to construct the modules once, or Watch to get a single
+module that reloads the configuration whenever the file changes.
+*/
+package config
+
+import (
+	"fmt"
+	"image/color"
+	"os/exec"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/click"
+	"github.com/soumya92/barista/colors"
+	"github.com/soumya92/barista/control"
+	"github.com/soumya92/barista/format/template"
+	"github.com/soumya92/barista/group/collapsing"
+	"github.com/soumya92/barista/group/cycling"
+	"github.com/soumya92/barista/group/following"
+	"github.com/soumya92/barista/group/modal"
+	"github.com/soumya92/barista/group/switching"
+	"github.com/soumya92/barista/modules/meta/reformat"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the top-level configuration for a bar.
+type Config struct {
+	// Colors are added to the colour scheme, see colors.LoadFromMap.
+	Colors map[string]string `yaml:"colors"`
+	// Modules are the modules on the bar, in order.
+	Modules []Module `yaml:"modules"`
+}
+
+// Module is the configuration for a single module, or a group of modules.
+type Module struct {
+	// Type is the registered name of the module constructor, or one of the
+	// group types: group.collapsing, group.cycling, group.following,
+	// group.modal, or group.switching.
+	Type string `yaml:"type"`
+	// Args are the arguments passed to the module constructor.
+	Args []interface{} `yaml:"args"`
+	// Refresh is the refresh interval of the module. For group.cycling, it is
+	// the interval at which the group cycles between modules.
+	Refresh time.Duration `yaml:"refresh"`
+	// Output is a template used to format the module's output, see the
+	// format/template package. It is executed with the value passed to the
+	// module's output function.
+	Output string `yaml:"output"`
+	// Pango interprets the output template as pango markup.
+	Pango bool `yaml:"pango"`
+	// Color is either the name of a colour in the scheme, or a hex colour.
+	Color string `yaml:"color"`
+	// Click maps button names (left, right, middle, back, forward, scroll_up,
+	// scroll_down, scroll_left, scroll_right) to shell commands.
+	Click map[string]string `yaml:"click"`
+	// Name registers a modal or switching group with the control interface.
+	Name string `yaml:"name"`
+	// Modules are the modules in a group, except for group.modal.
+	Modules []Module `yaml:"modules"`
+	// Modes are the modes of a group.modal.
+	Modes []Mode `yaml:"modes"`
+}
+
+// Mode is the configuration for a single mode of a modal group.
+type Mode struct {
+	Name string `yaml:"name"`
+	// Summary modules are shown when no mode is active, and in this mode.
+	Summary []Module `yaml:"summary"`
+	// Detail modules are shown only in this mode.
+	Detail []Module `yaml:"detail"`
+	// Modules are shown only when this mode is not active.
+	Modules []Module `yaml:"modules"`
+}
+
+var fs = afero.NewOsFs()
+
+// Parse parses a configuration from YAML. Unknown keys result in an error.
+func Parse(data []byte) (*Config, error) {
+	c := new(Config)
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load reads and parses a configuration file.
+func Load(filename string) (*Config, error) {
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return c, nil
+}
+
+// Build loads the colour scheme and constructs all modules in the config.
+// Named modal and switching groups are registered with the control interface.
+func (c *Config) Build() ([]bar.Module, error) {
+	colors.LoadFromMap(c.Colors)
+	ctrls := newControls()
+	modules, err := buildAll(ctrls, "modules", c.Modules)
+	if err != nil {
+		return nil, err
+	}
+	ctrls.register()
+	return modules, nil
+}
+
+// controls collects the controllers of named modal and switching groups while
+// building modules, so that they are only registered with the control
+// interface once all modules have been built successfully.
+type controls struct {
+	modals    map[string]modal.Controller
+	switchers map[string]switching.Controller
+}
+
+func newControls() *controls {
+	return &controls{
+		modals:    map[string]modal.Controller{},
+		switchers: map[string]switching.Controller{},
+	}
+}
+
+func (c *controls) register() {
+	for name, ctrl := range c.modals {
+		control.RegisterModal(name, ctrl)
+	}
+	for name, ctrl := range c.switchers {
+		control.RegisterSwitching(name, ctrl)
+	}
+}
+
+func buildAll(ctrls *controls, path string, cfgs []Module) ([]bar.Module, error) {
+	var modules []bar.Module
+	for i, cfg := range cfgs {
+		m, err := cfg.build(ctrls, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+var buttons = map[string]bar.Button{
+	"left":         bar.ButtonLeft,
+	"right":        bar.ButtonRight,
+	"middle":       bar.ButtonMiddle,
+	"back":         bar.ButtonBack,
+	"forward":      bar.ButtonForward,
+	"scroll_up":    bar.ScrollUp,
+	"scroll_down":  bar.ScrollDown,
+	"scroll_left":  bar.ScrollLeft,
+	"scroll_right": bar.ScrollRight,
+}
+
+func (c Module) build(ctrls *controls, path string) (bar.Module, error) {
+	m, err := c.buildModule(ctrls, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%s): %w", path, c.Type, err)
+	}
+	return m, nil
+}
+
+func (c Module) buildModule(ctrls *controls, path string) (bar.Module, error) {
+	if c.Type == "" {
+		return nil, fmt.Errorf("type is required")
+	}
+	isGroup := true
+	var m bar.Module
+	var err error
+	switch c.Type {
+	case "group.collapsing":
+		m, err = c.buildCollapsing(ctrls, path)
+	case "group.cycling":
+		m, err = c.buildCycling(ctrls, path)
+	case "group.following":
+		m, err = c.buildFollowing(ctrls, path)
+	case "group.modal":
+		m, err = c.buildModal(ctrls, path)
+	case "group.switching":
+		m, err = c.buildSwitching(ctrls, path)
+	default:
+		isGroup = false
+		m, err = c.buildRegistered()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if isGroup {
+		if c.Output != "" || c.Pango {
+			return nil, fmt.Errorf("output is not supported")
+		}
+		if len(c.Args) > 0 {
+			return nil, fmt.Errorf("args are not supported")
+		}
+	} else {
+		if len(c.Modules) > 0 || len(c.Modes) > 0 {
+			return nil, fmt.Errorf("only groups can contain modules")
+		}
+		if c.Name != "" {
+			return nil, fmt.Errorf("name is not supported")
+		}
+	}
+	return c.wrap(m)
+}
+
+func (c Module) buildRegistered() (bar.Module, error) {
+	fn, ok := lookup(c.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown module type")
+	}
+	m, err := construct(fn, c.Args)
+	if err != nil {
+		return nil, err
+	}
+	if c.Refresh != 0 {
+		if err := setRefresh(m, c.Refresh); err != nil {
+			return nil, err
+		}
+	}
+	if c.Output != "" {
+		parse := template.Text
+		if c.Pango {
+			parse = template.Pango
+		}
+		t, err := parse(c.Output)
+		if err != nil {
+			return nil, fmt.Errorf("output: %w", err)
+		}
+		if err := template.SetOutput(m, t); err != nil {
+			return nil, fmt.Errorf("output is not supported")
+		}
+	} else if c.Pango {
+		return nil, fmt.Errorf("pango requires output")
+	}
+	return m, nil
+}
+
+func (c Module) buildCollapsing(ctrls *controls, path string) (bar.Module, error) {
+	if err := c.noRefreshOrName(); err != nil {
+		return nil, err
+	}
+	modules, err := buildAll(ctrls, path+".modules", c.Modules)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := collapsing.Group(modules...)
+	return m, nil
+}
+
+func (c Module) buildCycling(ctrls *controls, path string) (bar.Module, error) {
+	if c.Name != "" {
+		return nil, fmt.Errorf("name is not supported")
+	}
+	if c.Refresh <= 0 {
+		return nil, fmt.Errorf("refresh is required")
+	}
+	modules, err := buildAll(ctrls, path+".modules", c.Modules)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := cycling.Group(c.Refresh, modules...)
+	return m, nil
+}
+
+func (c Module) buildFollowing(ctrls *controls, path string) (bar.Module, error) {
+	if err := c.noRefreshOrName(); err != nil {
+		return nil, err
+	}
+	modules, err := buildAll(ctrls, path+".modules", c.Modules)
+	if err != nil {
+		return nil, err
+	}
+	return following.Group(modules...), nil
+}
+
+func (c Module) buildSwitching(ctrls *controls, path string) (bar.Module, error) {
+	if c.Refresh != 0 {
+		return nil, fmt.Errorf("refresh is not supported")
+	}
+	modules, err := buildAll(ctrls, path+".modules", c.Modules)
+	if err != nil {
+		return nil, err
+	}
+	m, ctrl := switching.Group(modules...)
+	if c.Name != "" {
+		ctrls.switchers[c.Name] = ctrl
+	}
+	return m, nil
+}
+
+func (c Module) buildModal(ctrls *controls, path string) (bar.Module, error) {
+	if len(c.Modules) > 0 {
+		return nil, fmt.Errorf("modules are not supported, use modes")
+	}
+	mdl := modal.New()
+	if c.Refresh != 0 {
+		mdl.AutoReset(c.Refresh)
+	}
+	for i, mode := range c.Modes {
+		modePath := fmt.Sprintf("%s.modes[%d]", path, i)
+		if mode.Name == "" {
+			return nil, fmt.Errorf("%s: name is required", modePath)
+		}
+		summary, err := buildAll(ctrls, modePath+".summary", mode.Summary)
+		if err != nil {
+			return nil, err
+		}
+		detail, err := buildAll(ctrls, modePath+".detail", mode.Detail)
+		if err != nil {
+			return nil, err
+		}
+		modules, err := buildAll(ctrls, modePath+".modules", mode.Modules)
+		if err != nil {
+			return nil, err
+		}
+		mdl.Mode(mode.Name).Summary(summary...).Detail(detail...).Add(modules...)
+	}
+	m, ctrl := mdl.Build()
+	if c.Name != "" {
+		ctrls.modals[c.Name] = ctrl
+	}
+	return m, nil
+}
+
+// groupNames adds the names of all modal and switching groups in the given
+// modules to the maps, including nested groups.
+func groupNames(modules []Module, modals, switchers map[string]bool) {
+	for _, c := range modules {
+		switch {
+		case c.Name == "":
+		case c.Type == "group.modal":
+			modals[c.Name] = true
+		case c.Type == "group.switching":
+			switchers[c.Name] = true
+		}
+		groupNames(c.Modules, modals, switchers)
+		for _, mode := range c.Modes {
+			groupNames(mode.Summary, modals, switchers)
+			groupNames(mode.Detail, modals, switchers)
+			groupNames(mode.Modules, modals, switchers)
+		}
+	}
+}
+
+func (c Module) noRefreshOrName() error {
+	if c.Refresh != 0 {
+		return fmt.Errorf("refresh is not supported")
+	}
+	if c.Name != "" {
+		return fmt.Errorf("name is not supported")
+	}
+	return nil
+}
+
+// wrap applies the colour and click actions to all segments of the module.
+func (c Module) wrap(m bar.Module) (bar.Module, error) {
+	if c.Color == "" && len(c.Click) == 0 {
+		return m, nil
+	}
+	var col color.Color
+	if c.Color != "" {
+		var ok bool
+		if col, ok = parseColor(c.Color); !ok {
+			return nil, fmt.Errorf("unknown color %q", c.Color)
+		}
+	}
+	var onClick click.Map
+	if len(c.Click) > 0 {
+		onClick = click.Map{}
+		for name, cmd := range c.Click {
+			btn, ok := buttons[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown button %q", name)
+			}
+			onClick.Set(btn, runShell(cmd))
+		}
+	}
+	return reformat.New(m).Format(reformat.EachSegment(
+		reformat.SkipErrors(func(s *bar.Segment) *bar.Segment {
+			if col != nil {
+				s.Color(col)
+			}
+			if onClick != nil {
+				s.OnClick(onClick.Handle)
+			}
+			return s
+		}))), nil
+}
+
+// parseColor returns the scheme colour with the given name, or the colour
+// for a hex string starting with '#'.
+func parseColor(name string) (color.Color, bool) {
+	if name[0] == '#' {
+		c := colors.Hex(name)
+		return c, c != nil
+	}
+	c := colors.Scheme(name)
+	return c, c != nil
+}
+
+func runShell(cmd string) func(bar.Event) {
+	return func(bar.Event) {
+		exec.Command("sh", "-c", cmd).Run()
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/colors"
+	testBar "github.com/soumya92/barista/testing/bar"
+	testModule "github.com/soumya92/barista/testing/module"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInfo struct {
+	Name  string
+	Count int
+}
+
+type fakeModule struct {
+	*testModule.TestModule
+	name       string
+	count      int
+	ratio      float64
+	enabled    bool
+	timeout    time.Duration
+	extra      []string
+	refresh    time.Duration
+	outputFunc func(fakeInfo) bar.Output
+}
+
+func (f *fakeModule) RefreshInterval(interval time.Duration) *fakeModule {
+	f.refresh = interval
+	return f
+}
+
+func (f *fakeModule) Output(outputFunc func(fakeInfo) bar.Output) *fakeModule {
+	f.outputFunc = outputFunc
+	return f
+}
+
+var lastFake *fakeModule
+
+func init() {
+	Register("test.fake", func(name string, count int, ratio float64,
+		enabled bool, timeout time.Duration, extra ...string) *fakeModule {
+		lastFake = &fakeModule{
+			name: name, count: count, ratio: ratio,
+			enabled: enabled, timeout: timeout, extra: extra,
+		}
+		return lastFake
+	})
+}
+
+var testModules []*testModule.TestModule
+
+func registerTestModule(t *testing.T) {
+	testModules = nil
+	Register("test.module", func() *testModule.TestModule {
+		m := testModule.New(t)
+		testModules = append(testModules, m)
+		return m
+	})
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`
+colors:
+  good: "#0f0"
+modules:
+- type: test.fake
+  args: [a, 1, 2.5, true, 10s]
+  refresh: 5s
+  output: "{{.Name}}"
+- type: group.modal
+  name: main
+  modes:
+  - name: net
+    summary:
+    - type: wlan.any
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"good": "#0f0"}, c.Colors)
+	require.Len(t, c.Modules, 2)
+	require.Equal(t, "test.fake", c.Modules[0].Type)
+	require.Equal(t, []interface{}{"a", 1, 2.5, true, "10s"}, c.Modules[0].Args)
+	require.Equal(t, 5*time.Second, c.Modules[0].Refresh)
+	require.Equal(t, "net", c.Modules[1].Modes[0].Name)
+	require.Equal(t, "wlan.any", c.Modules[1].Modes[0].Summary[0].Type)
+
+	_, err = Parse([]byte("modules:\n- type: clock.local\n  ouptut: foo\n"))
+	require.Error(t, err, "unknown key")
+	require.Contains(t, err.Error(), "ouptut")
+
+	_, err = Parse([]byte("modules:\n- type: clock.local\n  refresh: soon\n"))
+	require.Error(t, err, "invalid duration")
+}
+
+func TestLoad(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	defer func() { fs = afero.NewOsFs() }()
+
+	_, err := Load("/bar.yaml")
+	require.Error(t, err, "missing file")
+
+	afero.WriteFile(fs, "/bar.yaml", []byte("modules:\n- type: clock.local\n"), 0644)
+	c, err := Load("/bar.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "clock.local", c.Modules[0].Type)
+
+	afero.WriteFile(fs, "/bad.yaml", []byte("foo: bar\n"), 0644)
+	_, err = Load("/bad.yaml")
+	require.Error(t, err, "unknown key")
+	require.Contains(t, err.Error(), "/bad.yaml")
+}
+
+func TestArgsAndOutput(t *testing.T) {
+	c, err := Parse([]byte(`
+modules:
+- type: test.fake
+  args: [foo, 3, 1, true, 1m, x, z]
+  refresh: 5s
+  output: "{{.Name}}: {{.Count}}"
+`))
+	require.NoError(t, err)
+	modules, err := c.Build()
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+
+	f := lastFake
+	require.Equal(t, "foo", f.name)
+	require.Equal(t, 3, f.count)
+	require.Equal(t, 1.0, f.ratio)
+	require.True(t, f.enabled)
+	require.Equal(t, time.Minute, f.timeout)
+	require.Equal(t, []string{"x", "z"}, f.extra)
+	require.Equal(t, 5*time.Second, f.refresh)
+
+	out := f.outputFunc(fakeInfo{"bar", 4})
+	require.Equal(t, bar.TextSegment("bar: 4"), out)
+
+	c.Modules[0].Output = `<b>{{.Name}}</b>{{if .Count}} {{.Count}}{{end}}`
+	c.Modules[0].Pango = true
+	_, err = c.Build()
+	require.NoError(t, err)
+	require.Equal(t, bar.PangoSegment("<b>baz</b>"), lastFake.outputFunc(fakeInfo{Name: "baz"}))
+
+	c.Modules[0].Output = `{{if .Count}}{{.Name}}{{end}}`
+	_, err = c.Build()
+	require.NoError(t, err)
+	require.Nil(t, lastFake.outputFunc(fakeInfo{Name: "hidden"}), "empty output")
+
+	c.Modules[0].Output = `{{.Missing}}`
+	_, err = c.Build()
+	require.NoError(t, err)
+	errSeg := lastFake.outputFunc(fakeInfo{}).Segments()[0]
+	require.Error(t, errSeg.GetError(), "template execution error")
+}
+
+func TestBuildErrors(t *testing.T) {
+	registerTestModule(t)
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{"- args: [foo]", "modules[0] (): type is required"},
+		{"- type: nope", "modules[0] (nope): unknown module type"},
+		{"- type: test.fake\n  args: [a]", "expected at least 5 args, got 1"},
+		{"- type: test.fake\n  args: [1, 1, 1, true, 1s]", "args[0]: expected string"},
+		{"- type: test.fake\n  args: [a, 1.5, 1, true, 1s]", "args[1]: expected an integer"},
+		{"- type: test.fake\n  args: [a, 1, x, true, 1s]", "args[2]: expected a number"},
+		{"- type: test.fake\n  args: [a, 1, 1, true, 1]", "args[4]: expected a duration"},
+		{"- type: test.fake\n  args: [a, 1, 1, true, 1y]", "args[4]: time: unknown unit"},
+		{"- type: test.fake\n  args: [a, 1, 1, true, 1s, 1]", "args[5]: expected string"},
+		{"- type: clock.local\n  args: [a]", "expected 0 args, got 1"},
+		{"- type: clock.zone\n  args: [Nowhere/Special]", "modules[0] (clock.zone): unknown time zone"},
+		{"- type: top\n  args: [-1]", "modules[0] (top): count must not be negative"},
+		{"- type: test.module\n  output: foo", "modules[0] (test.module): output is not supported"},
+		{"- type: test.module\n  refresh: 1s", "modules[0] (test.module): refresh is not supported"},
+		{"- type: test.module\n  pango: true", "pango requires output"},
+		{"- type: clock.local\n  output: '{{.Foo'", "output: template:"},
+		{"- type: test.module\n  color: nonexistent", `unknown color "nonexistent"`},
+		{"- type: test.module\n  color: '#xyz'", `unknown color "#xyz"`},
+		{"- type: test.module\n  click: {top: ls}", `unknown button "top"`},
+		{"- type: test.module\n  name: foo", "name is not supported"},
+		{"- type: test.module\n  modules: [{type: test.module}]", "only groups can contain modules"},
+		{"- type: group.collapsing\n  modules: [{type: test.module}, {type: nope}]",
+			"modules[0].modules[1] (nope): unknown module type"},
+		{"- type: group.collapsing\n  refresh: 1s", "modules[0] (group.collapsing): refresh is not supported"},
+		{"- type: group.following\n  name: foo", "name is not supported"},
+		{"- type: group.switching\n  output: foo", "output is not supported"},
+		{"- type: group.switching\n  args: [foo]", "args are not supported"},
+		{"- type: group.cycling\n  modules: [{type: test.module}]", "refresh is required"},
+		{"- type: group.modal\n  modules: [{type: test.module}]", "use modes"},
+		{"- type: group.modal\n  modes: [{summary: [{type: test.module}]}]",
+			"modules[0].modes[0]: name is required"},
+		{"- type: group.modal\n  modes: [{name: a, detail: [{type: nope}]}]",
+			"modules[0].modes[0].detail[0] (nope): unknown module type"},
+	} {
+		c, err := Parse([]byte("modules:\n" + tc.config))
+		require.NoError(t, err, "parse %s", tc.config)
+		_, err = c.Build()
+		require.Error(t, err, "build %s", tc.config)
+		require.Contains(t, err.Error(), tc.err, "build %s", tc.config)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	require.Panics(t, func() { Register("x", 5) }, "not a func")
+	require.Panics(t, func() { Register("x", func() string { return "" }) },
+		"does not return a module")
+	require.Panics(t, func() { Register("x", func() (bar.Module, string) { return nil, "" }) },
+		"second return value is not an error")
+	require.Panics(t, func() {
+		Register("x", func(o bar.Output) bar.Module { return nil })
+	}, "unsupported argument type")
+	require.NotContains(t, Types(), "x")
+	require.Contains(t, Types(), "clock.local")
+	require.Contains(t, Types(), "test.fake")
+}
+
+func TestColorAndClick(t *testing.T) {
+	registerTestModule(t)
+	tmp := filepath.Join(t.TempDir(), "clicked")
+	c, err := Parse([]byte(`
+colors:
+  accent: "#ff0000"
+modules:
+- type: test.module
+  color: accent
+  click:
+    left: "touch ` + tmp + `"
+- type: test.module
+  color: "#00ff00"
+`))
+	require.NoError(t, err)
+	modules, err := c.Build()
+	require.NoError(t, err)
+	require.Equal(t, colors.Hex("#ff0000"), colors.Scheme("accent"))
+
+	testBar.New(t)
+	testBar.Run(modules...)
+	require.Len(t, testModules, 2)
+	for _, m := range testModules {
+		m.AssertStarted()
+	}
+
+	testModules[0].OutputText("a")
+	out := testBar.NextOutput("first module")
+	seg := out.At(0).Segment()
+	col, _ := seg.GetColor()
+	require.Equal(t, colors.Hex("#ff0000"), col)
+	out.At(0).LeftClick()
+	_, err = os.Stat(tmp)
+	require.NoError(t, err, "click command was run")
+
+	testModules[1].OutputText("b")
+	out = testBar.NextOutput("second module")
+	col, _ = out.At(1).Segment().GetColor()
+	require.Equal(t, colors.Hex("#00ff00"), col)
+
+	testModules[1].Output(bar.ErrorSegment(os.ErrNotExist))
+	out = testBar.NextOutput("error")
+	_, hasColor := out.At(1).Segment().GetColor()
+	require.False(t, hasColor, "errors are not recoloured")
+}
+
+func TestGroups(t *testing.T) {
+	registerTestModule(t)
+	c, err := Parse([]byte(`
+modules:
+- type: group.modal
+  name: test-modal
+  refresh: 1m
+  modes:
+  - name: a
+    summary: [{type: test.module}]
+    detail: [{type: test.module}]
+  - name: b
+    modules: [{type: test.module}]
+- type: group.switching
+  name: test-switching
+  modules: [{type: test.module}, {type: test.module}]
+- type: group.cycling
+  refresh: 5s
+  modules: [{type: test.module}]
+- type: group.following
+  modules: [{type: test.module}]
+`))
+	require.NoError(t, err)
+	modules, err := c.Build()
+	require.NoError(t, err)
+	require.Len(t, modules, 4)
+	require.Len(t, testModules, 7)
+}
+
+func TestSampleConfig(t *testing.T) {
+	c, err := Load("../samples/config/bar.yaml")
+	require.NoError(t, err)
+	_, err = c.Build()
+	require.NoError(t, err)
+}
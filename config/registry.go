@@ -0,0 +1,184 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]reflect.Value{}
+)
+
+var (
+	moduleType   = reflect.TypeOf((*bar.Module)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Register makes a module constructor available to config files under the
+// given type name, e.g. Register("battery.named", battery.Named).
+//
+// The constructor must be a function that returns a bar.Module, and
+// optionally an error. Its arguments are taken from the "args" key, and must
+// be strings, numbers, bools, or time.Durations (given as strings, e.g. "5s").
+//
+// If the module has an Output method that takes a func(T) bar.Output, the
+// module supports the "output" key, which is a text/template executed with a
+// T. If the module has a RefreshInterval method that takes a time.Duration,
+// the module supports the "refresh" key.
+func Register(name string, constructor interface{}) {
+	fn := reflect.ValueOf(constructor)
+	t := fn.Type()
+	if t.Kind() != reflect.Func {
+		panic(fmt.Sprintf("config: constructor for %s is not a func", name))
+	}
+	switch {
+	case t.NumOut() == 1 && t.Out(0).Implements(moduleType):
+	case t.NumOut() == 2 && t.Out(0).Implements(moduleType) && t.Out(1) == errorType:
+	default:
+		panic(fmt.Sprintf("config: constructor for %s must return a bar.Module", name))
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !supportedArg(in) {
+			panic(fmt.Sprintf("config: constructor for %s has unsupported argument type %v", name, in))
+		}
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = fn
+}
+
+// Types returns the names of all registered module types.
+func Types() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(name string) (reflect.Value, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	fn, ok := registry[name]
+	return fn, ok
+}
+
+func supportedArg(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// construct calls the constructor with the given arguments from the config.
+func construct(fn reflect.Value, args []interface{}) (bar.Module, error) {
+	t := fn.Type()
+	numIn := t.NumIn()
+	if t.IsVariadic() {
+		if len(args) < numIn-1 {
+			return nil, fmt.Errorf("expected at least %d args, got %d", numIn-1, len(args))
+		}
+	} else if len(args) != numIn {
+		return nil, fmt.Errorf("expected %d args, got %d", numIn, len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var argType reflect.Type
+		if t.IsVariadic() && i >= numIn-1 {
+			argType = t.In(numIn - 1).Elem()
+		} else {
+			argType = t.In(i)
+		}
+		v, err := convertArg(arg, argType)
+		if err != nil {
+			return nil, fmt.Errorf("args[%d]: %w", i, err)
+		}
+		in[i] = v
+	}
+	out := fn.Call(in)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	if out[0].Kind() == reflect.Ptr && out[0].IsNil() {
+		// e.g. cputemp.New() when there is no x86_pkg_temp thermal zone.
+		return nil, fmt.Errorf("module is not available")
+	}
+	return out[0].Interface().(bar.Module), nil
+}
+
+func convertArg(arg interface{}, t reflect.Type) (reflect.Value, error) {
+	if t == durationType {
+		s, ok := arg.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected a duration string, got %v", arg)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(d), nil
+	}
+	v := reflect.ValueOf(arg)
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		if v.Kind() != t.Kind() {
+			return reflect.Value{}, fmt.Errorf("expected %v, got %v", t.Kind(), arg)
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Kind() != reflect.Int && v.Kind() != reflect.Float64 {
+			return reflect.Value{}, fmt.Errorf("expected a number, got %v", arg)
+		}
+	default:
+		// Integer kinds.
+		if v.Kind() != reflect.Int {
+			return reflect.Value{}, fmt.Errorf("expected an integer, got %v", arg)
+		}
+	}
+	return v.Convert(t), nil
+}
+
+// setRefresh uses the module's RefreshInterval method to set the interval.
+func setRefresh(m bar.Module, interval time.Duration) error {
+	method := reflect.ValueOf(m).MethodByName("RefreshInterval")
+	if !method.IsValid() || method.Type().NumIn() != 1 ||
+		method.Type().In(0) != durationType {
+		return fmt.Errorf("refresh is not supported")
+	}
+	method.Call([]reflect.Value{reflect.ValueOf(interval)})
+	return nil
+}
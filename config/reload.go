@@ -0,0 +1,312 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/notifier"
+	"github.com/soumya92/barista/base/watchers/file"
+	"github.com/soumya92/barista/colors"
+	"github.com/soumya92/barista/control"
+	"github.com/soumya92/barista/core"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/sink"
+	"github.com/soumya92/barista/timing"
+
+	"golang.org/x/sys/unix"
+)
+
+// settleDelay is how long to wait after the last change to the configuration
+// file before reloading it.
+const settleDelay = 100 * time.Millisecond
+
+// Reloader is a module that displays all the modules from a configuration
+// file, and reloads them when the file changes or the bar receives SIGHUP.
+//
+// On reload, modules whose configuration is unchanged keep running, and retain
+// their state (e.g. the active mode of a modal group). Only new or changed
+// modules are started, and removed or changed modules are stopped. If the
+// colour scheme changes, all modules are restarted.
+//
+// Only modules that implement bar.ContextModule can be stopped. Other modules,
+// including those inside groups, stop updating the bar when removed or
+// changed, but their Stream keeps running in the background, along with
+// anything it holds (e.g. watchers), until the bar exits. Restart the bar
+// instead of reloading to stop them.
+//
+// If the new configuration cannot be loaded, the existing modules keep
+// running, and an error segment is shown before them until the next
+// successful reload.
+//
+// While running, the modules are available to the control interface as
+// "config/INDEX", see control.RegisterModules.
+type Reloader struct {
+	filename string
+	reloadFn func()
+	reloadCh <-chan struct{}
+	current  *Config
+	modules  []bar.Module
+}
+
+// Watch loads the configuration file, and returns a module that displays all
+// of its modules, reloading them as the file changes.
+func Watch(filename string) (*Reloader, error) {
+	c, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := c.Build()
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader{filename: filename, current: c, modules: modules}
+	r.reloadFn, r.reloadCh = notifier.New()
+	l.Labelf(r, filename)
+	l.Register(r, "reloadCh")
+	return r, nil
+}
+
+// Reload reloads the configuration file.
+func (r *Reloader) Reload() {
+	r.reloadFn()
+}
+
+// Stream starts the module.
+func (r *Reloader) Stream(s bar.Sink) {
+	r.StreamContext(context.Background(), s)
+}
+
+// child is a single running module from the configuration.
+type child struct {
+	config Module
+	module bar.Module
+	core   *core.Module
+	cancel context.CancelFunc
+
+	mu  sync.Mutex
+	out bar.Segments
+}
+
+func (c *child) output() bar.Segments {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out
+}
+
+// running is the list of running children, which implements control.Modules
+// so that the modules can be controlled through the control interface.
+type running struct {
+	mu       sync.Mutex
+	children []*child
+}
+
+func (r *running) get() []*child {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.children
+}
+
+func (r *running) set(children []*child) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.children = children
+}
+
+func (r *running) Len() int {
+	return len(r.get())
+}
+
+func (r *running) Module(idx int) *core.Module {
+	return r.get()[idx].core
+}
+
+func (r *running) LastOutput(idx int) bar.Segments {
+	return r.get()[idx].output()
+}
+
+// StreamContext implements bar.ContextModule.
+func (r *Reloader) StreamContext(ctx context.Context, s bar.Sink) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, unix.SIGHUP)
+	defer signal.Stop(hup)
+
+	w := file.Watch(r.filename)
+	defer w.Unsubscribe()
+
+	// Editors often write files in several steps, so wait for changes to the
+	// file to settle before reloading.
+	settle := timing.NewScheduler()
+	defer settle.Close()
+
+	updateFn, updateCh := notifier.New()
+	start := func(cfg Module, m bar.Module) *child {
+		c := &child{config: cfg, module: m, core: core.NewModule(m)}
+		var childCtx context.Context
+		childCtx, c.cancel = context.WithCancel(ctx)
+		go c.core.StreamContext(childCtx, sink.Func(func(out bar.Segments) {
+			c.mu.Lock()
+			c.out = out
+			c.mu.Unlock()
+			updateFn()
+		}))
+		return c
+	}
+	var children []*child
+	for i, m := range r.modules {
+		children = append(children, start(r.current.Modules[i], m))
+	}
+	active := &running{children: children}
+	control.RegisterModules("config", active)
+	defer control.UnregisterModules("config")
+	defer func() {
+		for _, c := range active.get() {
+			c.cancel()
+		}
+	}()
+
+	var reloadErr error
+	for {
+		var out bar.Segments
+		if reloadErr != nil {
+			out = append(out, bar.ErrorSegment(reloadErr))
+		}
+		for _, c := range children {
+			out = append(out, c.output()...)
+		}
+		s.Output(out)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-updateCh:
+			continue
+		case <-hup:
+			l.Log("%s: reloading on SIGHUP", l.ID(r))
+		case <-w.Updates:
+			settle.After(settleDelay)
+			continue
+		case <-settle.C:
+			l.Log("%s: reloading on file change", l.ID(r))
+		case err := <-w.Errors:
+			// The configuration can still be reloaded using SIGHUP.
+			l.Log("%s: file watcher failed: %v", l.ID(r), err)
+			continue
+		case <-r.reloadCh:
+		}
+
+		var newChildren []*child
+		newChildren, reloadErr = r.reload(children, start)
+		if reloadErr != nil {
+			l.Log("%s: reload failed: %v", l.ID(r), reloadErr)
+			continue
+		}
+		children = newChildren
+		active.set(children)
+	}
+}
+
+// reload loads the configuration file and returns the new list of children,
+// reusing any existing children with unchanged configuration. Removed children
+// are stopped. On error, the existing children are unaffected.
+func (r *Reloader) reload(
+	children []*child,
+	start func(Module, bar.Module) *child,
+) ([]*child, error) {
+	c, err := Load(r.filename)
+	if err != nil {
+		return nil, err
+	}
+	colorsChanged := !reflect.DeepEqual(c.Colors, r.current.Colors)
+	if colorsChanged {
+		colors.LoadFromMap(c.Colors)
+	}
+	// Build all new modules before starting or stopping any, and only register
+	// their groups with the control interface afterwards, so that any error
+	// leaves the bar unchanged.
+	ctrls := newControls()
+	reused := make([]*child, len(c.Modules))
+	built := make([]bar.Module, len(c.Modules))
+	unused := append([]*child(nil), children...)
+	for i, cfg := range c.Modules {
+		if !colorsChanged {
+			if idx := findChild(unused, cfg); idx >= 0 {
+				reused[i] = unused[idx]
+				unused = append(unused[:idx], unused[idx+1:]...)
+				continue
+			}
+		}
+		built[i], err = cfg.build(ctrls, fmt.Sprintf("modules[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, old := range unused {
+		old.cancel()
+	}
+	unregisterRemovedGroups(r.current, c)
+	ctrls.register()
+	newChildren := make([]*child, len(c.Modules))
+	started := 0
+	for i, cfg := range c.Modules {
+		if reused[i] != nil {
+			newChildren[i] = reused[i]
+			built[i] = reused[i].module
+		} else {
+			newChildren[i] = start(cfg, built[i])
+			started++
+		}
+	}
+	l.Log("%s: reloaded, %d started, %d stopped", l.ID(r), started, len(unused))
+	r.current = c
+	r.modules = built
+	return newChildren, nil
+}
+
+// unregisterRemovedGroups removes any modal or switching groups that are no
+// longer in the configuration from the control interface. Groups that are
+// still present were either reused, or registered again when rebuilt.
+func unregisterRemovedGroups(prev, next *Config) {
+	oldModals, oldSwitchers := map[string]bool{}, map[string]bool{}
+	groupNames(prev.Modules, oldModals, oldSwitchers)
+	newModals, newSwitchers := map[string]bool{}, map[string]bool{}
+	groupNames(next.Modules, newModals, newSwitchers)
+	for name := range oldModals {
+		if !newModals[name] {
+			control.UnregisterModal(name)
+		}
+	}
+	for name := range oldSwitchers {
+		if !newSwitchers[name] {
+			control.UnregisterSwitching(name)
+		}
+	}
+}
+
+func findChild(children []*child, cfg Module) int {
+	for i, c := range children {
+		if reflect.DeepEqual(c.config, cfg) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/control"
+	"github.com/soumya92/barista/core"
+	testBar "github.com/soumya92/barista/testing/bar"
+	testModule "github.com/soumya92/barista/testing/module"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+type namedModule struct {
+	name string
+	*testModule.TestModule
+}
+
+func TestReloader(t *testing.T) {
+	built := make(chan namedModule, 10)
+	Register("test.named", func(name string) *testModule.TestModule {
+		m := testModule.New(t)
+		built <- namedModule{name, m}
+		return m
+	})
+	nextBuilt := func(name string) *testModule.TestModule {
+		select {
+		case m := <-built:
+			require.Equal(t, name, m.name, "built module")
+			return m.TestModule
+		case <-time.After(time.Second):
+			require.Fail(t, "module not built", name)
+		}
+		return nil
+	}
+	assertNoneBuilt := func() {
+		select {
+		case m := <-built:
+			require.Fail(t, "unexpected module built", m.name)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cfgFile := filepath.Join(t.TempDir(), "bar.yaml")
+	writeConfig := func(cfg string) {
+		require.NoError(t, os.WriteFile(cfgFile, []byte(cfg), 0644))
+	}
+
+	_, err := Watch(cfgFile)
+	require.Error(t, err, "missing config file")
+
+	writeConfig("modules:\n- type: nope\n")
+	_, err = Watch(cfgFile)
+	require.Error(t, err, "invalid config file")
+
+	writeConfig(`
+modules:
+- {type: test.named, args: [a]}
+- {type: test.named, args: [b]}
+`)
+	r, err := Watch(cfgFile)
+	require.NoError(t, err)
+	a := nextBuilt("a")
+	b := nextBuilt("b")
+
+	testBar.New(t)
+	testBar.Run(r)
+	a.AssertStarted()
+	b.AssertStarted()
+	a.OutputText("a")
+	b.OutputText("b")
+	testBar.Drain(100 * time.Millisecond).AssertText([]string{"a", "b"})
+
+	reloadConfig(t, writeConfig, `
+modules:
+- {type: test.named, args: [a]}
+- {type: test.named, args: [c]}
+- {type: test.named, args: [b], color: "#f00"}
+`)
+	c := nextBuilt("c")
+	newB := nextBuilt("b")
+	c.AssertStarted("new module")
+	newB.AssertStarted("changed module")
+	c.OutputText("c")
+	newB.OutputText("b2")
+	testBar.Drain(100 * time.Millisecond).AssertText([]string{"a", "c", "b2"})
+	require.Len(t, controlList(t).Nested["config"], 3,
+		"reloaded modules are available to the control interface")
+
+	reloadConfig(t, writeConfig, "modules: [{type: test.named, args: [a]}, {type: nope}]")
+	out := testBar.Drain(100 * time.Millisecond)
+	out.At(0).AssertError("invalid config")
+	require.Equal(t, 4, out.Len(), "existing modules are unaffected")
+	assertNoneBuilt()
+
+	reloadConfig(t, writeConfig, "modules: [{type: test.named, args: [a]}]")
+	testBar.Drain(100 * time.Millisecond).AssertText([]string{"a"})
+	assertNoneBuilt()
+
+	a.OutputText("a2")
+	testBar.NextOutput().AssertText([]string{"a2"}, "unchanged module still running")
+
+	require.NoError(t, unix.Kill(os.Getpid(), unix.SIGHUP))
+	testBar.NextOutput().AssertText([]string{"a2"}, "reload on SIGHUP")
+
+	r.Reload()
+	testBar.NextOutput().AssertText([]string{"a2"}, "manual reload")
+
+	reloadConfig(t, writeConfig, "colors: {accent: '#00f'}\nmodules: [{type: test.named, args: [a]}]")
+	newA := nextBuilt("a")
+	newA.AssertStarted("restarted on colour change")
+}
+
+// reloadConfig writes the configuration file, waits for the reloader to notice
+// the change, and then triggers the reload without waiting for the changes to
+// settle.
+func reloadConfig(t *testing.T, writeConfig func(string), cfg string) {
+	start := timing.Now()
+	writeConfig(cfg)
+	require.Eventually(t, func() bool { return timing.NextTick().After(start) },
+		time.Second, time.Millisecond, "reload scheduled")
+}
+
+// controlList returns the response to a list request to the control
+// interface.
+func controlList(t *testing.T) control.Response {
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	srv, err := control.Listen(path, core.NewModuleSet(nil))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Serve(ctx)
+	client, err := control.Dial(path)
+	require.NoError(t, err)
+	defer client.Close()
+	resp, err := client.Send(control.Request{Command: control.List})
+	require.NoError(t, err)
+	return resp
+}
+
+// registeredGroups returns the names of modal and switching groups registered
+// with the control interface, ignoring groups registered by other tests.
+func registeredGroups(t *testing.T) []string {
+	resp := controlList(t)
+	names := []string{}
+	for name := range resp.Modals {
+		if strings.HasPrefix(name, "reload-") {
+			names = append(names, "modal:"+name)
+		}
+	}
+	for name := range resp.Switchers {
+		if strings.HasPrefix(name, "reload-") {
+			names = append(names, "switching:"+name)
+		}
+	}
+	return names
+}
+
+func TestReloadGroups(t *testing.T) {
+	registerTestModule(t)
+	cfgFile := filepath.Join(t.TempDir(), "bar.yaml")
+	writeConfig := func(cfg string) {
+		require.NoError(t, os.WriteFile(cfgFile, []byte(cfg), 0644))
+	}
+	writeConfig(`
+modules:
+- type: group.switching
+  name: reload-switching
+  modules: [{type: test.module}]
+- type: group.collapsing
+  modules:
+  - type: group.modal
+    name: reload-modal
+    modes: [{name: a, modules: [{type: test.module}]}]
+`)
+	r, err := Watch(cfgFile)
+	require.NoError(t, err)
+	require.ElementsMatch(t,
+		[]string{"modal:reload-modal", "switching:reload-switching"},
+		registeredGroups(t))
+
+	testBar.New(t)
+	testBar.Run(r)
+	testBar.LatestOutput()
+
+	reloadConfig(t, writeConfig, `
+modules:
+- type: group.switching
+  name: reload-switching
+  modules: [{type: test.module}, {type: test.module}]
+`)
+	testBar.Drain(100 * time.Millisecond)
+	require.ElementsMatch(t,
+		[]string{"switching:reload-switching"}, registeredGroups(t),
+		"removed groups are unregistered")
+
+	reloadConfig(t, writeConfig, `
+modules:
+- type: group.switching
+  name: reload-switching
+  modules: [{type: test.module}, {type: test.module}, {type: test.module}]
+- type: group.modal
+  name: reload-modal
+  modes: [{name: a, modules: [{type: test.module}]}]
+- type: not.a.module
+`)
+	testBar.Drain(100 * time.Millisecond)
+	resp := controlList(t)
+	require.Equal(t, 2, resp.Switchers["reload-switching"].Count,
+		"running group is still registered after a failed reload")
+	require.NotContains(t, resp.Modals, "reload-modal",
+		"groups are not registered after a failed reload")
+
+	reloadConfig(t, writeConfig, "modules: [{type: test.module}]")
+	testBar.Drain(100 * time.Millisecond)
+	require.Empty(t, registeredGroups(t), "all groups are unregistered")
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+)
+
+// Client sends requests to the control interface of a running bar.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the control socket at the given path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn, bufio.NewScanner(conn)}, nil
+}
+
+// Send sends a request and waits for the response. If the bar could not
+// handle the request, the error from the response is returned.
+func (c *Client) Send(req Request) (Response, error) {
+	var resp Response
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return resp, err
+	}
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return resp, err
+		}
+		return resp, io.ErrUnexpectedEOF
+	}
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return resp, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Close closes the connection to the bar.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package control provides a local control interface for a running bar, using
+// JSON lines over a unix socket. It allows other programs (e.g. barista-ctl,
+// bound to i3 keybindings) to inspect and control the bar's modules.
+//
+// Each request is a single line of JSON, and each request receives a single
+// line of JSON in response. See Request and Response for the format.
+package control
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/core"
+	"github.com/soumya92/barista/group/modal"
+	"github.com/soumya92/barista/group/switching"
+)
+
+// Commands supported by the control interface.
+const (
+	// List lists all modules, and any registered modal or switching groups.
+	List = "list"
+	// Outputs returns the last output of each module.
+	Outputs = "outputs"
+	// Refresh refreshes a module, if it supports refreshing.
+	Refresh = "refresh"
+	// Restart restarts a module, if it has finished.
+	Restart = "restart"
+	// Click sends a click event to a segment of a module's output.
+	Click = "click"
+	// Modal activates a mode in a modal group, or resets it if mode is empty.
+	Modal = "modal"
+	// Switch shows a module in a switching group, by index or relative to the
+	// current module.
+	Switch = "switch"
+)
+
+// Request is a request sent to the control interface.
+type Request struct {
+	Command string `json:"command"`
+	// Module identifies the module for refresh, restart, and click. It can be
+	// either the index of the module in the bar, or the module's logging.ID.
+	// Logging IDs are only available when built with the baristadebuglog tag,
+	// so normal builds must use the index. Modules registered using
+	// RegisterModules are identified by "name/index".
+	Module string `json:"module,omitempty"`
+	// Segment is the index of the segment to click, within the module output.
+	Segment int `json:"segment,omitempty"`
+	// Event is the click event to send. Defaults to a left click.
+	Event *bar.Event `json:"event,omitempty"`
+	// Name is the name of a registered modal or switching group.
+	Name string `json:"name,omitempty"`
+	// Mode is the mode to activate in a modal group.
+	Mode string `json:"mode,omitempty"`
+	// Index is the index of the module to show in a switching group. If not
+	// set, Delta is used to move relative to the current module instead.
+	Index *int `json:"index,omitempty"`
+	Delta int  `json:"delta,omitempty"`
+}
+
+// Response is the response to a control request. Error is set if the request
+// could not be handled, otherwise the fields relevant to the request are set.
+type Response struct {
+	Error     string                   `json:"error,omitempty"`
+	Modules   []ModuleInfo             `json:"modules,omitempty"`
+	Nested    map[string][]ModuleInfo  `json:"nested,omitempty"`
+	Modals    map[string]ModalInfo     `json:"modals,omitempty"`
+	Switchers map[string]SwitchingInfo `json:"switchers,omitempty"`
+	Outputs   [][]SegmentInfo          `json:"outputs,omitempty"`
+}
+
+// ModuleInfo describes a module on the bar.
+type ModuleInfo struct {
+	Index int `json:"index"`
+	// ID is the logging.ID of the module. This is only available when built
+	// with the baristadebuglog tag, and is empty otherwise; use Index to
+	// identify the module instead.
+	ID          string `json:"id,omitempty"`
+	Type        string `json:"type"`
+	Refreshable bool   `json:"refreshable"`
+	Finished    bool   `json:"finished"`
+}
+
+// ModalInfo describes a registered modal group.
+type ModalInfo struct {
+	Modes   []string `json:"modes"`
+	Current string   `json:"current"`
+}
+
+// SwitchingInfo describes a registered switching group.
+type SwitchingInfo struct {
+	Current int `json:"current"`
+	Count   int `json:"count"`
+}
+
+// SegmentInfo describes a single segment of a module's output.
+type SegmentInfo struct {
+	Text  string `json:"text"`
+	Pango bool   `json:"pango,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Modules is a list of running modules, such as the modules on the bar, or the
+// modules inside another module (e.g. a config.Reloader).
+type Modules interface {
+	Len() int
+	Module(idx int) *core.Module
+	LastOutput(idx int) bar.Segments
+}
+
+var (
+	mu        sync.Mutex
+	modals    = map[string]modal.Controller{}
+	switchers = map[string]switching.Controller{}
+	nested    = map[string]Modules{}
+)
+
+// RegisterModal makes a modal group available to the control interface under
+// the given name.
+func RegisterModal(name string, c modal.Controller) {
+	mu.Lock()
+	defer mu.Unlock()
+	modals[name] = c
+}
+
+// RegisterSwitching makes a switching group available to the control
+// interface under the given name.
+func RegisterSwitching(name string, c switching.Controller) {
+	mu.Lock()
+	defer mu.Unlock()
+	switchers[name] = c
+}
+
+// UnregisterModal removes a modal group registered under the given name.
+func UnregisterModal(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(modals, name)
+}
+
+// UnregisterSwitching removes a switching group registered under the given
+// name.
+func UnregisterSwitching(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(switchers, name)
+}
+
+// RegisterModules makes modules that are not directly on the bar available to
+// the control interface under the given name. They are listed separately, and
+// identified by "name/index" in requests.
+func RegisterModules(name string, m Modules) {
+	mu.Lock()
+	defer mu.Unlock()
+	nested[name] = m
+}
+
+// UnregisterModules removes modules registered under the given name.
+func UnregisterModules(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(nested, name)
+}
+
+// DefaultSocket returns the default path of the control socket, in the user's
+// runtime directory.
+func DefaultSocket() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("barista-%d.sock", os.Getuid()))
+	}
+	return filepath.Join(dir, "barista.sock")
+}
@@ -0,0 +1,268 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/core"
+	"github.com/soumya92/barista/group/modal"
+	"github.com/soumya92/barista/group/switching"
+	"github.com/soumya92/barista/outputs"
+	testModule "github.com/soumya92/barista/testing/module"
+
+	"github.com/stretchr/testify/require"
+)
+
+type refresherModule struct {
+	*testModule.TestModule
+	refreshed chan struct{}
+}
+
+func (r refresherModule) Refresh() {
+	r.refreshed <- struct{}{}
+}
+
+func setup(t *testing.T, modules ...bar.Module) (*Client, <-chan int) {
+	set := core.NewModuleSet(modules)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	updates := set.StreamContext(ctx)
+
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	srv, err := Listen(path, set)
+	require.NoError(t, err)
+	go srv.Serve(ctx)
+
+	_, err = Listen(path, set)
+	require.Error(t, err, "socket already in use")
+
+	client, err := Dial(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client, updates
+}
+
+func awaitUpdate(t *testing.T, updates <-chan int) {
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		require.Fail(t, "no update from module set")
+	}
+}
+
+func TestModules(t *testing.T) {
+	tm := testModule.New(t)
+	rm := refresherModule{testModule.New(t), make(chan struct{}, 1)}
+	client, updates := setup(t, tm, rm)
+	tm.AssertStarted()
+	rm.AssertStarted()
+
+	resp, err := client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.Equal(t, []ModuleInfo{
+		{Index: 0, Type: "*module.TestModule"},
+		{Index: 1, Type: "control.refresherModule", Refreshable: true},
+	}, resp.Modules)
+
+	tm.Output(outputs.Group(outputs.Text("foo"), outputs.Errorf("oops")))
+	awaitUpdate(t, updates)
+	rm.Output(bar.PangoSegment("<b>bar</b>"))
+	awaitUpdate(t, updates)
+
+	resp, err = client.Send(Request{Command: Outputs})
+	require.NoError(t, err)
+	require.Equal(t, [][]SegmentInfo{
+		{{Text: "foo"}, {Text: "Error", Error: "oops"}},
+		{{Text: "<b>bar</b>", Pango: true}},
+	}, resp.Outputs)
+
+	_, err = client.Send(Request{Command: Refresh, Module: "0"})
+	require.Error(t, err, "module without Refresh")
+	_, err = client.Send(Request{Command: Refresh, Module: "1"})
+	require.NoError(t, err)
+	select {
+	case <-rm.refreshed:
+	case <-time.After(time.Second):
+		require.Fail(t, "module not refreshed")
+	}
+	_, err = client.Send(Request{Command: Refresh, Module: "2"})
+	require.Error(t, err, "out of range")
+	_, err = client.Send(Request{Command: Refresh, Module: "foo"})
+	require.Error(t, err, "unknown module")
+
+	_, err = client.Send(Request{Command: Click, Module: "0", Segment: 1})
+	require.NoError(t, err)
+	require.Equal(t, bar.Event{Button: bar.ButtonLeft}, tm.AssertClicked())
+
+	evt := bar.Event{Button: bar.ScrollUp, Modifiers: bar.ModShift}
+	_, err = client.Send(Request{Command: Click, Module: "1", Event: &evt})
+	require.NoError(t, err)
+	require.Equal(t, evt, rm.AssertClicked())
+
+	_, err = client.Send(Request{Command: Click, Module: "0", Segment: 2})
+	require.Error(t, err, "segment out of range")
+
+	_, err = client.Send(Request{Command: Restart, Module: "0"})
+	require.Error(t, err, "module still running")
+	tm.Close()
+	awaitUpdate(t, updates)
+	resp, err = client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.True(t, resp.Modules[0].Finished)
+	_, err = client.Send(Request{Command: Restart, Module: "0"})
+	require.NoError(t, err)
+	awaitUpdate(t, updates)
+	tm.AssertStarted("on restart")
+
+	_, err = client.Send(Request{Command: "foo"})
+	require.Error(t, err, "unknown command")
+}
+
+func TestGroups(t *testing.T) {
+	m := modal.New()
+	m.Mode("a").Add(testModule.New(t))
+	m.Mode("b").Add(testModule.New(t))
+	_, modalCtrl := m.Build()
+	RegisterModal("main", modalCtrl)
+
+	_, switchCtrl := switching.Group(testModule.New(t), testModule.New(t), testModule.New(t))
+	RegisterSwitching("sw", switchCtrl)
+
+	client, _ := setup(t)
+	resp, err := client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.Equal(t, map[string]ModalInfo{
+		"main": {Modes: []string{"a", "b"}},
+	}, resp.Modals)
+	require.Equal(t, map[string]SwitchingInfo{
+		"sw": {Current: 0, Count: 3},
+	}, resp.Switchers)
+
+	_, err = client.Send(Request{Command: Modal, Name: "main", Mode: "b"})
+	require.NoError(t, err)
+	require.Equal(t, "b", modalCtrl.Current())
+	_, err = client.Send(Request{Command: Modal, Name: "main", Mode: "c"})
+	require.Error(t, err, "unknown mode")
+	require.Equal(t, "b", modalCtrl.Current())
+	_, err = client.Send(Request{Command: Modal, Name: "main"})
+	require.NoError(t, err)
+	require.Equal(t, "", modalCtrl.Current())
+	_, err = client.Send(Request{Command: Modal, Name: "other", Mode: "a"})
+	require.Error(t, err, "unknown modal group")
+
+	two := 2
+	_, err = client.Send(Request{Command: Switch, Name: "sw", Index: &two})
+	require.NoError(t, err)
+	require.Equal(t, 2, switchCtrl.Current())
+	_, err = client.Send(Request{Command: Switch, Name: "sw", Delta: 1})
+	require.NoError(t, err)
+	require.Equal(t, 0, switchCtrl.Current(), "wraps around")
+	_, err = client.Send(Request{Command: Switch, Name: "sw", Delta: -1})
+	require.NoError(t, err)
+	require.Equal(t, 2, switchCtrl.Current())
+	ten := 10
+	_, err = client.Send(Request{Command: Switch, Name: "sw", Index: &ten})
+	require.Error(t, err, "index out of range")
+	_, err = client.Send(Request{Command: Switch, Name: "other", Delta: 1})
+	require.Error(t, err, "unknown switching group")
+
+	_, emptyCtrl := switching.Group()
+	RegisterSwitching("empty", emptyCtrl)
+	_, err = client.Send(Request{Command: Switch, Name: "empty", Delta: 1})
+	require.Error(t, err, "empty switching group")
+	zero := 0
+	_, err = client.Send(Request{Command: Switch, Name: "empty", Index: &zero})
+	require.Error(t, err, "empty switching group")
+
+	UnregisterModal("main")
+	UnregisterSwitching("sw")
+	UnregisterSwitching("empty")
+	resp, err = client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.Empty(t, resp.Modals)
+	require.Empty(t, resp.Switchers)
+	_, err = client.Send(Request{Command: Switch, Name: "sw", Delta: 1})
+	require.Error(t, err, "unregistered switching group")
+}
+
+func TestNestedModules(t *testing.T) {
+	inner := testModule.New(t)
+	set := core.NewModuleSet([]bar.Module{inner})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := set.StreamContext(ctx)
+	RegisterModules("inner", set)
+	defer UnregisterModules("inner")
+
+	client, _ := setup(t, testModule.New(t))
+	inner.AssertStarted()
+	resp, err := client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.Len(t, resp.Modules, 1)
+	require.Equal(t, map[string][]ModuleInfo{
+		"inner": {{Index: 0, Type: "*module.TestModule"}},
+	}, resp.Nested)
+
+	inner.OutputText("inner")
+	awaitUpdate(t, updates)
+	_, err = client.Send(Request{Command: Click, Module: "inner/0"})
+	require.NoError(t, err)
+	inner.AssertClicked()
+
+	_, err = client.Send(Request{Command: Click, Module: "inner/1"})
+	require.Error(t, err, "out of range")
+	_, err = client.Send(Request{Command: Click, Module: "inner/foo"})
+	require.Error(t, err, "invalid index")
+	_, err = client.Send(Request{Command: Click, Module: "other/0"})
+	require.Error(t, err, "unknown modules")
+
+	UnregisterModules("inner")
+	resp, err = client.Send(Request{Command: List})
+	require.NoError(t, err)
+	require.Empty(t, resp.Nested)
+}
+
+func TestServeStopsOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctl.sock")
+	srv, err := Listen(path, core.NewModuleSet(nil))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() { errCh <- srv.Serve(ctx) }()
+
+	client, err := Dial(path)
+	require.NoError(t, err)
+	_, err = client.Send(Request{Command: List})
+	require.NoError(t, err)
+
+	cancel()
+	require.NoError(t, <-errCh)
+	_, err = client.Send(Request{Command: List})
+	require.Error(t, err, "connection closed on cancel")
+	_, err = Dial(path)
+	require.Error(t, err, "socket removed")
+}
+
+func TestDefaultSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	require.Equal(t, "/run/user/1000/barista.sock", DefaultSocket())
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	require.Contains(t, DefaultSocket(), "barista-")
+}
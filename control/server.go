@@ -0,0 +1,321 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/core"
+	l "github.com/soumya92/barista/logging"
+)
+
+// Server handles control requests for a set of modules.
+type Server struct {
+	set  *core.ModuleSet
+	ln   net.Listener
+	path string
+}
+
+// Listen creates a control server for the given modules, listening on a unix
+// socket at the given path. A stale socket left behind by a previous run is
+// removed, but an error is returned if another bar is using the socket.
+func Listen(path string, set *core.ModuleSet) (*Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("control socket %s is already in use", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{set: set, ln: ln, path: path}
+	l.Register(s, "set")
+	return s, nil
+}
+
+// Serve accepts and handles connections until the context is cancelled, or
+// the listener fails. The socket is removed before Serve returns.
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.ln.Close()
+	}()
+	defer os.Remove(s.path)
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = errorResponse(err)
+		} else {
+			resp = s.Handle(req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			l.Log("%s: %v", l.ID(s), err)
+			return
+		}
+	}
+}
+
+func errorResponse(err error) Response {
+	return Response{Error: err.Error()}
+}
+
+// Handle handles a single control request.
+func (s *Server) Handle(req Request) Response {
+	l.Fine("%s: %+v", l.ID(s), req)
+	var err error
+	resp := Response{}
+	switch req.Command {
+	case List:
+		resp = s.list()
+	case Outputs:
+		resp = s.outputs()
+	case Refresh:
+		err = s.refresh(req.Module)
+	case Restart:
+		err = s.restart(req.Module)
+	case Click:
+		err = s.click(req)
+	case Modal:
+		err = activateMode(req.Name, req.Mode)
+	case Switch:
+		err = switchTo(req.Name, req.Index, req.Delta)
+	default:
+		err = fmt.Errorf("unknown command %q", req.Command)
+	}
+	if err != nil {
+		return errorResponse(err)
+	}
+	return resp
+}
+
+func moduleInfos(modules Modules) []ModuleInfo {
+	infos := []ModuleInfo{}
+	for i := 0; i < modules.Len(); i++ {
+		m := modules.Module(i)
+		_, refreshable := m.Original().(bar.RefresherModule)
+		infos = append(infos, ModuleInfo{
+			Index:       i,
+			ID:          l.ID(m.Original()),
+			Type:        fmt.Sprintf("%T", m.Original()),
+			Refreshable: refreshable,
+			Finished:    m.Finished(),
+		})
+	}
+	return infos
+}
+
+func (s *Server) list() Response {
+	resp := Response{Modules: moduleInfos(s.set)}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(nested) > 0 {
+		resp.Nested = map[string][]ModuleInfo{}
+		for name, m := range nested {
+			resp.Nested[name] = moduleInfos(m)
+		}
+	}
+	if len(modals) > 0 {
+		resp.Modals = map[string]ModalInfo{}
+		for name, c := range modals {
+			resp.Modals[name] = ModalInfo{Modes: c.Modes(), Current: c.Current()}
+		}
+	}
+	if len(switchers) > 0 {
+		resp.Switchers = map[string]SwitchingInfo{}
+		for name, c := range switchers {
+			resp.Switchers[name] = SwitchingInfo{Current: c.Current(), Count: c.Count()}
+		}
+	}
+	return resp
+}
+
+func (s *Server) outputs() Response {
+	resp := Response{Outputs: [][]SegmentInfo{}}
+	for _, segments := range s.set.LastOutputs() {
+		out := []SegmentInfo{}
+		for _, seg := range segments {
+			txt, pango := seg.Content()
+			info := SegmentInfo{Text: txt, Pango: pango}
+			if err := seg.GetError(); err != nil {
+				info.Error = err.Error()
+			}
+			out = append(out, info)
+		}
+		resp.Outputs = append(resp.Outputs, out)
+	}
+	return resp
+}
+
+// module finds a module by index or logging ID, or by "name/index" for
+// modules registered with RegisterModules. It also returns the list the module
+// belongs to, and its index in that list.
+func (s *Server) module(name string) (Modules, int, *core.Module, error) {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		mu.Lock()
+		modules, ok := nested[name[:i]]
+		mu.Unlock()
+		if ok {
+			return moduleByIndex(modules, name[i+1:])
+		}
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return moduleByIndex(s.set, name)
+	}
+	for i := 0; i < s.set.Len(); i++ {
+		m := s.set.Module(i)
+		if id := l.ID(m.Original()); id != "" && id == name {
+			return s.set, i, m, nil
+		}
+	}
+	return nil, 0, nil, fmt.Errorf("unknown module %q", name)
+}
+
+func moduleByIndex(modules Modules, index string) (Modules, int, *core.Module, error) {
+	idx, err := strconv.Atoi(index)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("invalid module index %q", index)
+	}
+	if idx < 0 || idx >= modules.Len() {
+		return nil, 0, nil, fmt.Errorf("module index %d out of range", idx)
+	}
+	return modules, idx, modules.Module(idx), nil
+}
+
+func (s *Server) refresh(name string) error {
+	_, _, m, err := s.module(name)
+	if err != nil {
+		return err
+	}
+	r, ok := m.Original().(bar.RefresherModule)
+	if !ok {
+		return fmt.Errorf("module %q cannot be refreshed", name)
+	}
+	r.Refresh()
+	return nil
+}
+
+func (s *Server) restart(name string) error {
+	_, _, m, err := s.module(name)
+	if err != nil {
+		return err
+	}
+	if !m.Finished() {
+		return fmt.Errorf("module %q is still running", name)
+	}
+	m.Restart()
+	return nil
+}
+
+func (s *Server) click(req Request) error {
+	modules, idx, _, err := s.module(req.Module)
+	if err != nil {
+		return err
+	}
+	out := modules.LastOutput(idx)
+	if req.Segment < 0 || req.Segment >= len(out) {
+		return fmt.Errorf("segment %d out of range for module %q", req.Segment, req.Module)
+	}
+	seg := out[req.Segment]
+	if !seg.HasClick() {
+		return fmt.Errorf("segment %d of module %q is not clickable", req.Segment, req.Module)
+	}
+	e := bar.Event{Button: bar.ButtonLeft}
+	if req.Event != nil {
+		e = *req.Event
+	}
+	if id, ok := seg.GetID(); ok {
+		e.SegmentID = id
+	}
+	go seg.Click(e)
+	return nil
+}
+
+func activateMode(name, mode string) error {
+	mu.Lock()
+	c, ok := modals[name]
+	mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown modal group %q", name)
+	}
+	if mode == "" {
+		c.Reset()
+		return nil
+	}
+	for _, m := range c.Modes() {
+		if m == mode {
+			c.Activate(mode)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown mode %q in modal group %q", mode, name)
+}
+
+func switchTo(name string, index *int, delta int) error {
+	mu.Lock()
+	c, ok := switchers[name]
+	mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown switching group %q", name)
+	}
+	count := c.Count()
+	if count == 0 {
+		return fmt.Errorf("switching group %q has no modules", name)
+	}
+	if index != nil {
+		if *index < 0 || *index >= count {
+			return fmt.Errorf("index %d out of range for switching group %q", *index, name)
+		}
+		c.Show(*index)
+		return nil
+	}
+	c.Show(((c.Current()+delta)%count + count) % count)
+	return nil
+}
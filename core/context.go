@@ -0,0 +1,55 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+
+	"github.com/soumya92/barista/bar"
+	l "github.com/soumya92/barista/logging"
+)
+
+// contextModule adapts a bar.Module that does not support cancellation.
+type contextModule struct {
+	bar.Module
+}
+
+// WithContext returns a bar.ContextModule for the given module. If the module
+// already supports contexts, it is returned unchanged. Otherwise, the returned
+// module stops forwarding output and returns when the context is cancelled,
+// but the original module's Stream will continue running in the background.
+func WithContext(m bar.Module) bar.ContextModule {
+	if c, ok := m.(bar.ContextModule); ok {
+		return c
+	}
+	return contextModule{m}
+}
+
+func (c contextModule) StreamContext(ctx context.Context, sink bar.Sink) {
+	done := make(chan struct{})
+	go func() {
+		c.Module.Stream(func(o bar.Output) {
+			if ctx.Err() == nil {
+				sink(o)
+			}
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		l.Fine("%s abandoned on cancellation", l.ID(c.Module))
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/sink"
+	testModule "github.com/soumya92/barista/testing/module"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ctxModule is a ContextModule that records when it is stopped.
+type ctxModule struct {
+	outputs chan bar.Output
+	stopped chan struct{}
+}
+
+func newCtxModule() *ctxModule {
+	return &ctxModule{
+		outputs: make(chan bar.Output),
+		stopped: make(chan struct{}, 10),
+	}
+}
+
+func (c *ctxModule) Stream(s bar.Sink) {
+	c.StreamContext(context.Background(), s)
+}
+
+func (c *ctxModule) StreamContext(ctx context.Context, s bar.Sink) {
+	defer func() { c.stopped <- struct{}{} }()
+	for {
+		select {
+		case o := <-c.outputs:
+			s.Output(o)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *ctxModule) assertStopped(t *testing.T, msg string) {
+	select {
+	case <-c.stopped:
+	case <-time.After(time.Second):
+		require.Fail(t, "module not stopped", msg)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	c := newCtxModule()
+	require.Equal(t, c, WithContext(c), "returns ContextModules unchanged")
+
+	tm := testModule.New(t)
+	m := WithContext(tm)
+	ch, sink := sink.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	go func() {
+		m.StreamContext(ctx, sink)
+		close(returned)
+	}()
+	tm.AssertStarted()
+	tm.OutputText("foo")
+	txt, _ := nextOutput(t, ch, "before cancellation")[0].Content()
+	require.Equal(t, "foo", txt)
+
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "StreamContext did not return on cancellation")
+	}
+	tm.OutputText("bar")
+	assertNoOutput(t, ch, "after cancellation")
+}
+
+func TestModuleContext(t *testing.T) {
+	c := newCtxModule()
+	m := NewModule(c)
+	ch, sink := sink.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	go func() {
+		m.StreamContext(ctx, sink)
+		close(returned)
+	}()
+
+	c.outputs <- outputs.Text("foo")
+	txt, _ := nextOutput(t, ch)[0].Content()
+	require.Equal(t, "foo", txt)
+
+	cancel()
+	c.assertStopped(t, "on cancellation")
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "StreamContext did not return on cancellation")
+	}
+	assertNoOutput(t, ch, "after cancellation")
+}
+
+func TestModuleContextAfterFinish(t *testing.T) {
+	tm := testModule.New(t)
+	m := NewModule(tm)
+	ch, sink := sink.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	go func() {
+		m.StreamContext(ctx, sink)
+		close(returned)
+	}()
+	tm.AssertStarted()
+	tm.OutputText("foo")
+	nextOutput(t, ch)
+	tm.Close()
+	nextOutput(t, ch, "restart handlers on finish")
+
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "StreamContext did not return on cancellation")
+	}
+}
+
+func TestModuleSetContext(t *testing.T) {
+	c0, c1 := newCtxModule(), newCtxModule()
+	ms := NewModuleSet([]bar.Module{c0, c1})
+	ctx, cancel := context.WithCancel(context.Background())
+	updateCh := ms.StreamContext(ctx)
+
+	c1.outputs <- outputs.Text("foo")
+	require.Equal(t, 1, nextUpdate(t, updateCh))
+
+	cancel()
+	c0.assertStopped(t, "on cancellation")
+	c1.assertStopped(t, "on cancellation")
+	select {
+	case _, ok := <-updateCh:
+		require.False(t, ok, "update channel closed")
+	case <-time.After(time.Second):
+		require.Fail(t, "update channel not closed on cancellation")
+	}
+}
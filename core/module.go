@@ -17,7 +17,9 @@
 package core
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/soumya92/barista/bar"
@@ -38,6 +40,7 @@ type Module struct {
 	replayFn  func()
 	restartCh <-chan struct{}
 	restartFn func()
+	finished  int32 // atomic bool.
 }
 
 // NewModule wraps an existing bar.Module with core barista functionality,
@@ -55,16 +58,24 @@ func NewModule(original bar.Module) *Module {
 // Stream runs the module with the given sink, automatically handling
 // terminations/restarts of the wrapped module.
 func (m *Module) Stream(sink bar.Sink) {
-	for {
-		m.runLoop(sink)
+	m.StreamContext(context.Background(), sink)
+}
+
+// StreamContext runs the module with the given sink until the context is
+// cancelled, automatically handling terminations/restarts of the wrapped
+// module. The wrapped module is stopped before StreamContext returns.
+func (m *Module) StreamContext(ctx context.Context, sink bar.Sink) {
+	for ctx.Err() == nil {
+		m.runLoop(ctx, sink)
 	}
 }
 
 // runLoop is one iteration of the wrapped module. It starts the wrapped
 // module, and multiplexes events, replay notifications, and module output.
 // It returns when the underlying module is ready to be restarted (i.e. it
-// was stopped and an eligible click event was received).
-func (m *Module) runLoop(realSink bar.Sink) {
+// was stopped and an eligible click event was received), or when the context
+// is cancelled and the underlying module has stopped.
+func (m *Module) runLoop(ctx context.Context, realSink bar.Sink) {
 	started := false
 	finished := false
 	var refreshFn func()
@@ -72,14 +83,22 @@ func (m *Module) runLoop(realSink bar.Sink) {
 		refreshFn = r.Refresh
 	}
 	timedSink := newTimedSink(realSink, refreshFn)
+	defer timedSink.Close()
 	l.Attach(m.original, timedSink, "~internal-sink")
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	outputCh := make(chan bar.Output)
-	innerSink := func(o bar.Output) { outputCh <- o }
+	innerSink := func(o bar.Output) {
+		select {
+		case outputCh <- o:
+		case <-runCtx.Done():
+		}
+	}
 	doneCh := make(chan struct{})
 
 	go func(m bar.Module, innerSink bar.Sink, doneCh chan<- struct{}) {
 		l.Fine("%s started", l.ID(m))
-		m.Stream(innerSink)
+		WithContext(m).StreamContext(runCtx, innerSink)
 		l.Fine("%s finished", l.ID(m))
 		doneCh <- struct{}{}
 	}(m.original, innerSink, doneCh)
@@ -87,11 +106,24 @@ func (m *Module) runLoop(realSink bar.Sink) {
 	var out bar.Output
 	for {
 		select {
+		case <-ctx.Done():
+			l.Fine("%s stopping", l.ID(m.original))
+			cancel()
+			if !finished {
+				<-doneCh
+			}
+			return
 		case out = <-outputCh:
 			started = true
 			timedSink.Output(out, true)
 		case <-doneCh:
+			if ctx.Err() != nil {
+				// The module returned because of the cancellation, so the
+				// sink may no longer be read from.
+				return
+			}
 			finished = true
+			atomic.StoreInt32(&m.finished, 1)
 			timedSink.Stop()
 			out = toSegments(out)
 			l.Fine("%s: set restart handlers", l.ID(m))
@@ -105,6 +137,7 @@ func (m *Module) runLoop(realSink bar.Sink) {
 			if finished {
 				l.Fine("%s restarted", l.ID(m.original))
 				timedSink.Output(stripErrors(out, l.ID(m)), false)
+				atomic.StoreInt32(&m.finished, 0)
 				return // Stream will restart the run loop.
 			}
 		}
@@ -116,6 +149,23 @@ func (m *Module) Replay() {
 	m.replayFn()
 }
 
+// Restart restarts the wrapped module if it has finished, as if the user had
+// clicked on its output. It does nothing if the wrapped module is running.
+func (m *Module) Restart() {
+	m.restartFn()
+}
+
+// Finished returns true if the wrapped module has finished, and is waiting
+// to be restarted.
+func (m *Module) Finished() bool {
+	return atomic.LoadInt32(&m.finished) == 1
+}
+
+// Original returns the wrapped module.
+func (m *Module) Original() bar.Module {
+	return m.original
+}
+
 // isRestartableClick checks whether a click event should restart the
 // wrapped module. A left/right/middle click will restart the module.
 func isRestartableClick(e bar.Event) bool {
@@ -206,6 +256,7 @@ type timedSink struct {
 	bar.Sink
 	*timing.Scheduler
 	refreshFn func()
+	done      chan struct{}
 
 	mu          sync.Mutex
 	out         bar.TimedOutput
@@ -217,6 +268,7 @@ func newTimedSink(original bar.Sink, refreshFn func()) *timedSink {
 		Sink:      original,
 		Scheduler: timing.NewScheduler(),
 		refreshFn: refreshFn,
+		done:      make(chan struct{}),
 	}
 	l.Register(t, "Sink", "Scheduler")
 	go t.runLoop()
@@ -237,11 +289,22 @@ func (t *timedSink) Output(o bar.Output, refreshable bool) {
 }
 
 func (t *timedSink) runLoop() {
-	for t.Tick() {
-		t.render()
+	for {
+		select {
+		case <-t.C:
+			t.render()
+		case <-t.done:
+			return
+		}
 	}
 }
 
+// Close stops any further timed output, and frees the resources used.
+func (t *timedSink) Close() {
+	t.Scheduler.Close()
+	close(t.done)
+}
+
 func (t *timedSink) render() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -127,6 +127,31 @@ func TestRestart(t *testing.T) {
 	tm.AssertStarted("on middle click")
 }
 
+func TestRestartMethod(t *testing.T) {
+	tm := testModule.New(t)
+	m := NewModule(tm)
+	require.Equal(t, tm, m.Original())
+	ch, sink := sink.New()
+
+	go m.Stream(sink)
+	tm.AssertStarted()
+	require.False(t, m.Finished(), "while running")
+
+	m.Restart()
+	assertNoOutput(t, ch, "restart while running is a nop")
+
+	tm.OutputText("test")
+	nextOutput(t, ch)
+	tm.Close()
+	nextOutput(t, ch, "On close (to set click handlers)")
+	require.True(t, m.Finished(), "after close")
+
+	m.Restart()
+	nextOutput(t, ch, "on restart")
+	tm.AssertStarted("on restart")
+	require.False(t, m.Finished(), "after restart")
+}
+
 func TestTimedOutput(t *testing.T) {
 	timing.TestMode()
 	tm := testModule.New(t).SkipClickHandlers()
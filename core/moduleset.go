@@ -15,6 +15,7 @@
 package core
 
 import (
+	"context"
 	"sync"
 
 	"github.com/soumya92/barista/bar"
@@ -29,6 +30,10 @@ type ModuleSet struct {
 	updateCh  chan int
 	outputs   []bar.Segments
 	outputsMu sync.RWMutex
+	// Timed sinks can still output after their module has stopped, so sends
+	// on updateCh are guarded to prevent sending on a closed channel.
+	closed   bool
+	closedMu sync.RWMutex
 }
 
 // NewModuleSet creates a ModuleSet with the given modules.
@@ -48,20 +53,48 @@ func NewModuleSet(modules []bar.Module) *ModuleSet {
 // Stream starts streaming all modules and returns a channel that receives the
 // index of the module any time one updates with new output.
 func (m *ModuleSet) Stream() <-chan int {
+	return m.StreamContext(context.Background())
+}
+
+// StreamContext is like Stream, but stops all modules when the context is
+// cancelled. The returned channel is closed once all modules have stopped.
+func (m *ModuleSet) StreamContext(ctx context.Context) <-chan int {
+	var wg sync.WaitGroup
 	for i, mod := range m.modules {
-		go mod.Stream(m.sinkFn(i))
+		wg.Add(1)
+		go func(mod *Module, sink bar.Sink) {
+			defer wg.Done()
+			mod.StreamContext(ctx, sink)
+		}(mod, m.sinkFn(ctx, i))
 	}
+	go func() {
+		<-ctx.Done()
+		wg.Wait()
+		l.Fine("%s stopped", l.ID(m))
+		m.closedMu.Lock()
+		defer m.closedMu.Unlock()
+		m.closed = true
+		close(m.updateCh)
+	}()
 	return m.updateCh
 }
 
-func (m *ModuleSet) sinkFn(idx int) bar.Sink {
+func (m *ModuleSet) sinkFn(ctx context.Context, idx int) bar.Sink {
 	return sink.Func(func(out bar.Segments) {
 		l.Fine("%s new output from %s",
 			l.ID(m), l.ID(m.modules[idx].original))
 		m.outputsMu.Lock()
 		m.outputs[idx] = out
 		m.outputsMu.Unlock()
-		m.updateCh <- idx
+		m.closedMu.RLock()
+		defer m.closedMu.RUnlock()
+		if m.closed {
+			return
+		}
+		select {
+		case m.updateCh <- idx:
+		case <-ctx.Done():
+		}
 	})
 }
 
@@ -70,6 +103,11 @@ func (m *ModuleSet) Len() int {
 	return len(m.modules)
 }
 
+// Module returns the module at a specific position.
+func (m *ModuleSet) Module(idx int) *Module {
+	return m.modules[idx]
+}
+
 // LastOutput returns the last output from the module at a specific position.
 // If the module has not yet updated, an empty output will be used.
 func (m *ModuleSet) LastOutput(idx int) bar.Segments {
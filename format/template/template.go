@@ -0,0 +1,309 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package template formats module output using text/template, so that outputs
+can be configured at runtime (e.g. from a configuration file) and shared
+between bars, instead of being written as Go functions.
+
+In addition to the standard text/template functions, templates can use:
+
+	unit v            format.Unit, e.g. {{unit .Temperature}}
+	si v "unit"       format.SI, e.g. {{si .Frequency "Hz"}}
+	bytesize v        format.Bytesize, also ibytesize, byterate, and ibyterate
+	duration d        format.Duration, e.g. {{duration .Uptime}}
+	scheme "name"     colors.Scheme, e.g. {{color (scheme "good") "OK"}}
+	threshold v a b   "good" if v < a, "degraded" if v < b, "bad" otherwise,
+	                  or the reverse if a > b, e.g. {{threshold .UsedPct 50 80}}
+	setColor c        sets the segment colour, e.g. {{setColor "bad"}}
+	setBackground c   sets the segment background colour
+	setBorder c       sets the segment border colour
+	setUrgent b       marks the segment as urgent
+
+Colours are either a colors.Scheme name, a hex string starting with '#', or
+a color.Color.
+
+Templates created using Pango output pango markup, and can also use:
+
+	icon "ident"      pango.Icon, e.g. {{icon "mdi-wifi"}}
+	color c text      text in the given colour
+	escape text       escapes text for use in markup, e.g. {{escape .SSID}}
+*/
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"reflect"
+	tmpl "text/template"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/colors"
+	"github.com/soumya92/barista/format"
+	"github.com/soumya92/barista/pango"
+
+	"github.com/martinlindhe/unit"
+)
+
+// Template is a parsed output template. It is safe for concurrent use.
+type Template struct {
+	tmpl  *tmpl.Template
+	pango bool
+}
+
+// Text parses a template that produces plain text output.
+func Text(text string) (*Template, error) {
+	return parse(text, false)
+}
+
+// Pango parses a template that produces pango markup.
+func Pango(text string) (*Template, error) {
+	return parse(text, true)
+}
+
+// Must panics if the template could not be parsed. It is intended for
+// templates defined in code, e.g. template.Must(template.Text("{{.Name}}")).
+func Must(t *Template, err error) *Template {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parse(text string, isPango bool) (*Template, error) {
+	funcs := tmpl.FuncMap{}
+	for name, fn := range commonFuncs {
+		funcs[name] = fn
+	}
+	// Placeholders, replaced by segment-specific functions when executing.
+	for name, fn := range (&segmentFuncs{}).funcs() {
+		funcs[name] = fn
+	}
+	if isPango {
+		for name, fn := range pangoFuncs {
+			funcs[name] = fn
+		}
+	}
+	t, err := tmpl.New("output").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{t, isPango}, nil
+}
+
+// Output executes the template with the given data, and returns a segment
+// with the result. If the template produces only whitespace, Output returns
+// nil to hide the module, and if execution fails, it returns an error segment.
+func (t *Template) Output(data interface{}) bar.Output {
+	s := &segmentFuncs{}
+	// Templates cannot be modified after execution, so each output uses a
+	// clone with its own segment functions.
+	exec, err := t.tmpl.Clone()
+	if err != nil {
+		return bar.ErrorSegment(err)
+	}
+	var out bytes.Buffer
+	if err := exec.Funcs(s.funcs()).Execute(&out, data); err != nil {
+		return bar.ErrorSegment(err)
+	}
+	if len(bytes.TrimSpace(out.Bytes())) == 0 {
+		return nil
+	}
+	var seg *bar.Segment
+	if t.pango {
+		seg = bar.PangoSegment(out.String())
+	} else {
+		seg = bar.TextSegment(out.String())
+	}
+	return s.apply(seg)
+}
+
+var outputType = reflect.TypeOf((*bar.Output)(nil)).Elem()
+
+// SetOutput sets the template as the output of a module, using the module's
+// Output method, which must take a single func(T) bar.Output argument. This
+// allows a template to be used with any module, e.g.
+//
+//	template.SetOutput(battery.All(), t)
+//
+// is equivalent to
+//
+//	battery.All().Output(func(i battery.Info) bar.Output { return t.Output(i) })
+func SetOutput(module interface{}, t *Template) error {
+	method := reflect.ValueOf(module).MethodByName("Output")
+	if !method.IsValid() || method.Type().NumIn() != 1 {
+		return fmt.Errorf("%T does not have an Output method", module)
+	}
+	fnType := method.Type().In(0)
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 1 ||
+		fnType.NumOut() != 1 || fnType.Out(0) != outputType {
+		return fmt.Errorf("%T.Output does not take a func(T) bar.Output", module)
+	}
+	outputFunc := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		out := reflect.New(outputType).Elem()
+		if o := t.Output(args[0].Interface()); o != nil {
+			out.Set(reflect.ValueOf(o))
+		}
+		return []reflect.Value{out}
+	})
+	method.Call([]reflect.Value{outputFunc})
+	return nil
+}
+
+var commonFuncs = tmpl.FuncMap{
+	"unit": func(v interface{}) (format.Values, error) {
+		if vals, ok := format.Unit(v); ok {
+			return vals, nil
+		}
+		return nil, fmt.Errorf("not a unit: %v", v)
+	},
+	"si":        format.SI,
+	"bytesize":  format.Bytesize,
+	"ibytesize": format.IBytesize,
+	"byterate":  format.Byterate,
+	"ibyterate": format.IByterate,
+	"duration": func(d interface{}) (format.Values, error) {
+		switch d := d.(type) {
+		case time.Duration:
+			return format.Duration(d), nil
+		case unit.Duration:
+			return format.Duration(time.Duration(d.Nanoseconds())), nil
+		}
+		return nil, fmt.Errorf("not a duration: %v", d)
+	},
+	"scheme": func(name string) color.Color {
+		if c := colors.Scheme(name); c != nil {
+			return c
+		}
+		return nil
+	},
+	"threshold": threshold,
+}
+
+var pangoFuncs = tmpl.FuncMap{
+	"icon": func(ident string) string {
+		return pango.Icon(ident).String()
+	},
+	"color": func(c interface{}, text string) (string, error) {
+		col, err := toColor(c)
+		if err != nil {
+			return "", err
+		}
+		if col == nil {
+			return pango.Text(text).String(), nil
+		}
+		return pango.New().Color(col).AppendText(text).String(), nil
+	},
+	"escape": func(text string) string {
+		return pango.Text(text).String()
+	},
+}
+
+// threshold returns the scheme colour name for a value, given the thresholds
+// for "degraded" and "bad". If a > b, lower values are worse.
+func threshold(v interface{}, a, b float64) (string, error) {
+	val, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	if a > b {
+		val, a, b = -val, -a, -b
+	}
+	switch {
+	case val < a:
+		return "good", nil
+	case val < b:
+		return "degraded", nil
+	}
+	return "bad", nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), nil
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
+
+// toColor converts a scheme name, hex string, or color.Color to a colour.
+// Scheme names that are not set result in a nil colour.
+func toColor(c interface{}) (color.Color, error) {
+	switch c := c.(type) {
+	case nil:
+		return nil, nil
+	case color.Color:
+		return c, nil
+	case string:
+		if len(c) > 0 && c[0] == '#' {
+			if hex := colors.Hex(c); hex != nil {
+				return hex, nil
+			}
+			return nil, fmt.Errorf("invalid color: %s", c)
+		}
+		if s := colors.Scheme(c); s != nil {
+			return s, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("not a color: %v", c)
+}
+
+// segmentFuncs holds the segment attributes set by a single execution.
+type segmentFuncs struct {
+	color, background, border color.Color
+	urgent                    bool
+}
+
+func (s *segmentFuncs) funcs() tmpl.FuncMap {
+	setter := func(dest *color.Color) func(interface{}) (string, error) {
+		return func(c interface{}) (string, error) {
+			col, err := toColor(c)
+			*dest = col
+			return "", err
+		}
+	}
+	return tmpl.FuncMap{
+		"setColor":      setter(&s.color),
+		"setBackground": setter(&s.background),
+		"setBorder":     setter(&s.border),
+		"setUrgent": func(urgent bool) string {
+			s.urgent = urgent
+			return ""
+		},
+	}
+}
+
+func (s *segmentFuncs) apply(seg *bar.Segment) *bar.Segment {
+	if s.color != nil {
+		seg.Color(s.color)
+	}
+	if s.background != nil {
+		seg.Background(s.background)
+	}
+	if s.border != nil {
+		seg.Border(s.border)
+	}
+	if s.urgent {
+		seg.Urgent(true)
+	}
+	return seg
+}
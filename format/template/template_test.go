@@ -0,0 +1,199 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/colors"
+	"github.com/soumya92/barista/pango"
+	testOutput "github.com/soumya92/barista/testing/output"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/require"
+)
+
+type info struct {
+	Name    string
+	Pct     int
+	Size    unit.Datasize
+	Rate    unit.Datarate
+	Temp    unit.Temperature
+	Elapsed time.Duration
+}
+
+func TestText(t *testing.T) {
+	tpl, err := Text("{{.Name}}: {{.Pct}}%")
+	require.NoError(t, err)
+	require.Equal(t, bar.TextSegment("foo: 50%"), tpl.Output(info{Name: "foo", Pct: 50}))
+	require.Equal(t, bar.TextSegment("<b>: 0%"), tpl.Output(info{Name: "<b>"}),
+		"text templates are not escaped")
+
+	tpl = Must(Text("{{if .Name}}{{.Name}}{{end}}  "))
+	require.Nil(t, tpl.Output(info{}), "whitespace output hides the module")
+
+	tpl = Must(Text("{{.Missing}}"))
+	testOutput.New(t, tpl.Output(info{})).AssertError("execution error")
+
+	_, err = Text("{{.Name")
+	require.Error(t, err, "parse error")
+
+	_, err = Text(`{{icon "fa-foo"}}`)
+	require.Error(t, err, "pango funcs in text template")
+
+	require.Panics(t, func() { Must(Text("{{")) })
+}
+
+func TestFormatFuncs(t *testing.T) {
+	tpl := Must(Text(`{{unit .Temp}} {{bytesize .Size}} {{ibytesize .Size}} ` +
+		`{{byterate .Rate}} {{duration .Elapsed}} {{si 1500 "Hz"}}`))
+	require.Equal(t,
+		bar.TextSegment("20.0℃ 2.0 MB 1.9 MiB 1.0 kB/s 1h30m 1kHz"),
+		tpl.Output(info{
+			Temp:    unit.FromCelsius(20),
+			Size:    2 * unit.Megabyte,
+			Rate:    8 * unit.KilobitPerSecond,
+			Elapsed: 90 * time.Minute,
+		}))
+
+	tpl = Must(Text(`{{unit .Name}}`))
+	testOutput.New(t, tpl.Output(info{})).AssertError("unit of non-unit")
+
+	tpl = Must(Text(`{{duration .Pct}}`))
+	testOutput.New(t, tpl.Output(info{})).AssertError("duration of int")
+}
+
+func TestThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		val    interface{}
+		a, b   float64
+		expect string
+	}{
+		{10, 50, 80, "good"},
+		{50, 50, 80, "degraded"},
+		{79.9, 50, 80, "degraded"},
+		{uint8(80), 50, 80, "bad"},
+		{90, 20, 10, "good"},
+		{15, 20, 10, "degraded"},
+		{5.0, 20, 10, "bad"},
+	} {
+		name, err := threshold(tc.val, tc.a, tc.b)
+		require.NoError(t, err)
+		require.Equal(t, tc.expect, name, "threshold(%v, %v, %v)", tc.val, tc.a, tc.b)
+	}
+	_, err := threshold("foo", 1, 2)
+	require.Error(t, err)
+}
+
+func TestSegmentFuncs(t *testing.T) {
+	colors.LoadFromMap(map[string]string{
+		"good": "#00ff00",
+		"bad":  "#ff0000",
+	})
+	tpl := Must(Text(`{{setColor (threshold .Pct 50 80)}}{{setBackground "#000"}}` +
+		`{{if gt .Pct 90}}{{setUrgent true}}{{end}}{{.Pct}}`))
+
+	out := tpl.Output(info{Pct: 10}).(*bar.Segment)
+	col, _ := out.GetColor()
+	require.Equal(t, colors.Scheme("good"), col)
+	bg, _ := out.GetBackground()
+	require.Equal(t, colors.Hex("#000"), bg)
+	_, isSet := out.IsUrgent()
+	require.False(t, isSet)
+
+	out = tpl.Output(info{Pct: 95}).(*bar.Segment)
+	col, _ = out.GetColor()
+	require.Equal(t, colors.Scheme("bad"), col)
+	urgent, _ := out.IsUrgent()
+	require.True(t, urgent)
+
+	out = tpl.Output(info{Pct: 60}).(*bar.Segment)
+	_, isSet = out.GetColor()
+	require.False(t, isSet, "unset scheme colour")
+
+	tpl = Must(Text(`{{setBorder (scheme "bad")}}x`))
+	out = tpl.Output(nil).(*bar.Segment)
+	border, _ := out.GetBorder()
+	require.Equal(t, colors.Scheme("bad"), border)
+
+	tpl = Must(Text(`{{setColor "#xyz"}}x`))
+	testOutput.New(t, tpl.Output(nil)).AssertError("invalid hex colour")
+
+	tpl = Must(Text(`{{setColor 5}}x`))
+	testOutput.New(t, tpl.Output(nil)).AssertError("not a colour")
+}
+
+func TestPango(t *testing.T) {
+	colors.LoadFromMap(map[string]string{"good": "#00ff00"})
+	pango.AddIconProvider("test", func(name string) *pango.Node {
+		return pango.Text(name).Font("Test Icons")
+	})
+	tpl := Must(Pango(`{{icon "test-wifi"}} {{escape .Name}} {{color "good" "ok"}}` +
+		`{{color "missing" " plain"}}`))
+	require.Equal(t,
+		bar.PangoSegment(`<span face='Test Icons' fallback='false'>wifi</span> `+
+			`a&amp;b <span color='#00ff00'>ok</span> plain`),
+		tpl.Output(info{Name: "a&b"}))
+
+	tpl = Must(Pango(`{{color "missing" .Name}}`))
+	require.Equal(t, bar.PangoSegment(`&lt;b&gt;&amp;`),
+		tpl.Output(info{Name: "<b>&"}), "escaped without a colour")
+
+	tpl = Must(Pango(`{{color "#xyz" "x"}}`))
+	testOutput.New(t, tpl.Output(nil)).AssertError("invalid hex colour")
+}
+
+type fakeModule struct {
+	outputFunc func(info) bar.Output
+}
+
+func (f *fakeModule) Output(outputFunc func(info) bar.Output) *fakeModule {
+	f.outputFunc = outputFunc
+	return f
+}
+
+type badModule struct{}
+
+func (badModule) Output(string) {}
+
+func TestSetOutput(t *testing.T) {
+	tpl := Must(Text("{{.Name}}"))
+	m := &fakeModule{}
+	require.NoError(t, SetOutput(m, tpl))
+	require.Equal(t, bar.TextSegment("foo"), m.outputFunc(info{Name: "foo"}))
+	require.Nil(t, m.outputFunc(info{}))
+
+	require.Error(t, SetOutput(fakeModule{}, tpl), "pointer receiver")
+	require.Error(t, SetOutput(badModule{}, tpl), "wrong signature")
+	require.Error(t, SetOutput(5, tpl), "no output method")
+}
+
+func TestConcurrentOutput(t *testing.T) {
+	tpl := Must(Text(`{{setColor "#f00"}}{{.Pct}}`))
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			for j := 0; j < 10; j++ {
+				tpl.Output(info{Pct: i})
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+}
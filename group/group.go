@@ -17,6 +17,7 @@
 package group
 
 import (
+	"context"
 	"sync"
 
 	"github.com/soumya92/barista/bar"
@@ -65,7 +66,13 @@ func New(g Grouper, m ...bar.Module) bar.Module {
 
 // Stream starts the modules and wraps their before sending it to the bar.
 func (g *group) Stream(sink bar.Sink) {
-	moduleSetCh := g.moduleSet.Stream()
+	g.StreamContext(context.Background(), sink)
+}
+
+// StreamContext is like Stream, but stops all grouped modules when the
+// context is cancelled.
+func (g *group) StreamContext(ctx context.Context, sink bar.Sink) {
+	moduleSetCh := g.moduleSet.StreamContext(ctx)
 	var signalCh <-chan struct{}
 	if sig, ok := g.grouper.(Signaller); ok {
 		signalCh = sig.Signal()
@@ -80,7 +87,12 @@ func (g *group) Stream(sink bar.Sink) {
 		case <-signalCh:
 			idx = -1
 			l.Fine("%s updated from grouper signal", l.ID(g))
-		case idx = <-moduleSetCh:
+		case newIdx, ok := <-moduleSetCh:
+			if !ok {
+				l.Fine("%s stopped", l.ID(g))
+				return
+			}
+			idx = newIdx
 			l.Fine("%s updated from #%d", l.ID(g), idx)
 			if u, ok := g.grouper.(UpdateListener); ok {
 				u.Updated(idx)
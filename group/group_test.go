@@ -15,6 +15,7 @@
 package group
 
 import (
+	"context"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -340,3 +341,25 @@ func TestSimple(t *testing.T) {
 	out.At(1).Click(bar.Event{})
 	m2.AssertClicked("clicks pass through the group")
 }
+
+func TestGroupContext(t *testing.T) {
+	m0 := testModule.New(t)
+	grp := Simple(m0).(bar.ContextModule)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outs := make(chan bar.Output, 10)
+	returned := make(chan struct{})
+	go func() {
+		grp.StreamContext(ctx, func(o bar.Output) { outs <- o })
+		close(returned)
+	}()
+	m0.AssertStarted()
+	<-outs
+
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "group did not stop on cancellation")
+	}
+}
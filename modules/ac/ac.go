@@ -0,0 +1,209 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ac provides an i3bar module that shows whether the system is
+// running on external power, using the Mains and USB power supplies in
+// /sys/class/power_supply, along with whether the lid is closed and the
+// system is docked, as reported by logind.
+package ac
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/format"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+)
+
+// Supply represents an external power supply.
+type Supply struct {
+	// Name of the power supply, e.g. "AC", or "ucsi-source-psy-USBC000:001".
+	Name string
+	// Type of the power supply, either "Mains" or "USB".
+	Type   string
+	Online bool
+	// Power is the power negotiated by the supply (e.g. using USB Power
+	// Delivery), or 0 if not available.
+	Power unit.Power
+}
+
+// Info represents the state of external power, the lid, and docking.
+type Info struct {
+	Supplies  []Supply
+	LidClosed bool
+	Docked    bool
+}
+
+// Online returns true if any external power supply is online.
+func (i Info) Online() bool {
+	for _, s := range i.Supplies {
+		if s.Online {
+			return true
+		}
+	}
+	return false
+}
+
+// Power returns the total power negotiated by all online supplies.
+func (i Info) Power() unit.Power {
+	var p unit.Power
+	for _, s := range i.Supplies {
+		if s.Online {
+			p += s.Power
+		}
+	}
+	return p
+}
+
+// Module represents an AC adapter bar module.
+type Module struct {
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New creates an AC adapter module.
+func New() *Module {
+	m := &Module{scheduler: timing.NewScheduler()}
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(3 * time.Second)
+	m.Output(func(i Info) bar.Output {
+		if !i.Online() {
+			return outputs.Text("BAT")
+		}
+		if p := i.Power(); p > 0 {
+			return outputs.Textf("AC %s", format.SI(p.Watts(), "W"))
+		}
+		return outputs.Text("AC")
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency for power supplies. The
+// lid and docking state are updated as soon as logind reports a change.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Overridden in tests.
+var fs = afero.NewOsFs()
+var busType = dbus.System
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	// logind does not always signal changes to these, so they are also
+	// fetched on each refresh.
+	logind := dbus.WatchProperties(busType,
+		"org.freedesktop.login1", "/org/freedesktop/login1",
+		"org.freedesktop.login1.Manager").
+		Add("LidClosed", "Docked").
+		Fetch("LidClosed", "Docked")
+	defer logind.Unsubscribe()
+
+	info, err := getInfo(logind)
+	for {
+		if s.Error(err) {
+			return
+		}
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+		case <-logind.Updates:
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+			continue
+		}
+		info, err = getInfo(logind)
+	}
+}
+
+const supplyDir = "/sys/class/power_supply"
+
+func getInfo(logind *dbus.PropertiesWatcher) (Info, error) {
+	var info Info
+	dir, err := fs.Open(supplyDir)
+	if err != nil {
+		return info, err
+	}
+	names, err := dir.Readdirnames(-1)
+	dir.Close()
+	if err != nil {
+		return info, err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typ := readString(name, "type")
+		if typ != "Mains" && typ != "USB" {
+			continue
+		}
+		s := Supply{Name: name, Type: typ, Online: readInt(name, "online") == 1}
+		// The negotiated power is the voltage multiplied by the maximum
+		// current the supply can provide at that voltage.
+		voltage := readInt(name, "voltage_now")
+		if voltage == 0 {
+			voltage = readInt(name, "voltage_max")
+		}
+		current := readInt(name, "current_max")
+		s.Power = unit.Power(float64(voltage)*float64(current)/1e12) * unit.Watt
+		info.Supplies = append(info.Supplies, s)
+	}
+	props := logind.Get()
+	info.LidClosed, _ = props["LidClosed"].(bool)
+	info.Docked, _ = props["Docked"].(bool)
+	return info, nil
+}
+
+func readString(supply, file string) string {
+	data, err := afero.ReadFile(fs, filepath.Join(supplyDir, supply, file))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readInt reads an integer value from the supply, returning 0 if the file
+// does not exist, as is the case for most optional properties.
+func readInt(supply, file string) int64 {
+	v, _ := strconv.ParseInt(readString(supply, file), 10, 64)
+	return v
+}
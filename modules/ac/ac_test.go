@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ac
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSupply(name string, files map[string]string) {
+	for file, contents := range files {
+		afero.WriteFile(fs, filepath.Join(supplyDir, name, file), []byte(contents+"\n"), 0644)
+	}
+}
+
+func setupLogind() *dbus.TestBusObject {
+	busType = dbus.Test
+	bus := dbus.SetupTestBus()
+	logind := bus.RegisterService("org.freedesktop.login1")
+	obj := logind.Object("/org/freedesktop/login1", "org.freedesktop.login1.Manager")
+	obj.SetProperties(map[string]interface{}{
+		"LidClosed": false,
+		"Docked":    false,
+	}, dbus.SignalTypeNone)
+	return obj
+}
+
+func TestAC(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	setupLogind()
+	writeSupply("AC", map[string]string{"type": "Mains", "online": "0"})
+	writeSupply("BAT0", map[string]string{"type": "Battery", "online": "1"})
+	writeSupply("ucsi-source-psy-USBC000:001", map[string]string{
+		"type":        "USB",
+		"online":      "0",
+		"voltage_now": "5000000",
+		"current_max": "0",
+	})
+
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput("on start").AssertText([]string{"BAT"})
+
+	writeSupply("AC", map[string]string{"online": "1"})
+	testBar.Tick()
+	testBar.NextOutput("on AC online").AssertText([]string{"AC"})
+
+	writeSupply("AC", map[string]string{"online": "0"})
+	writeSupply("ucsi-source-psy-USBC000:001", map[string]string{
+		"online":      "1",
+		"voltage_now": "20000000",
+		"current_max": "3250000",
+	})
+	testBar.Tick()
+	testBar.NextOutput("on USB-PD online").AssertText([]string{"AC 65W"})
+}
+
+func TestLidAndDock(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	logind := setupLogind()
+	writeSupply("AC", map[string]string{"type": "Mains", "online": "1"})
+	writeSupply("USB0", map[string]string{
+		"type":        "USB",
+		"online":      "1",
+		"voltage_max": "5000000",
+		"current_max": "500000",
+	})
+
+	infos := make(chan Info, 10)
+	testBar.New(t)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		infos <- i
+		if i.Docked {
+			return outputs.Text("docked")
+		}
+		if i.LidClosed {
+			return outputs.Text("closed")
+		}
+		return outputs.Text("open")
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"open"})
+	require.Equal(t, []Supply{
+		{Name: "AC", Type: "Mains", Online: true},
+		{Name: "USB0", Type: "USB", Online: true, Power: 2.5},
+	}, (<-infos).Supplies)
+
+	logind.SetPropertyForTest("LidClosed", true, dbus.SignalTypeChanged)
+	testBar.NextOutput("on lid closed").AssertText([]string{"closed"})
+	<-infos
+
+	logind.SetPropertyForTest("Docked", true, dbus.SignalTypeNone)
+	testBar.AssertNoOutput("without change signal")
+	testBar.Tick()
+	testBar.NextOutput("on refresh").AssertText([]string{"docked"})
+	info := <-infos
+	require.True(t, info.LidClosed)
+	require.True(t, info.Online())
+}
+
+func TestErrors(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	setupLogind()
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput("without power_supply").AssertError()
+}
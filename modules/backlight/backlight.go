@@ -0,0 +1,259 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backlight provides an i3bar module that shows and controls the
+// screen brightness, using /sys/class/backlight. Brightness is set through
+// logind, so it does not require root.
+package backlight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/notifier"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/base/watchers/file"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+	"golang.org/x/time/rate"
+)
+
+// Info represents the current brightness of a backlight device.
+type Info struct {
+	// Device is the name of the backlight device, e.g. "intel_backlight".
+	Device     string
+	Brightness int
+	Max        int
+	set        func(int) error
+	update     func()
+}
+
+// Frac returns the current brightness as a fraction of the maximum.
+func (i Info) Frac() float64 {
+	if i.Max == 0 {
+		return 0
+	}
+	return float64(i.Brightness) / float64(i.Max)
+}
+
+// Pct returns the current brightness in the range 0-100.
+func (i Info) Pct() int {
+	return int((i.Frac() * 100) + 0.5)
+}
+
+// SetBrightness sets the brightness of the device, clamped to the range
+// [0, Max].
+func (i Info) SetBrightness(brightness int) {
+	if brightness > i.Max {
+		brightness = i.Max
+	}
+	if brightness < 0 {
+		brightness = 0
+	}
+	if brightness == i.Brightness || i.set == nil {
+		return
+	}
+	if err := i.set(brightness); err != nil {
+		l.Log("Error setting brightness: %v", err)
+		return
+	}
+	i.update()
+}
+
+// SetPct sets the brightness of the device as a percentage of the maximum.
+func (i Info) SetPct(pct int) {
+	i.SetBrightness((pct*i.Max + 50) / 100)
+}
+
+// Module represents a bar.Module that displays backlight brightness.
+type Module struct {
+	device     string
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New constructs a backlight module for the first backlight device.
+func New() *Module {
+	return Device("")
+}
+
+// Device constructs a backlight module for the named backlight device,
+// e.g. "intel_backlight" or "amdgpu_bl0".
+func Device(name string) *Module {
+	m := &Module{device: name, scheduler: timing.NewScheduler()}
+	if name != "" {
+		l.Label(m, name)
+	}
+	l.Register(m, "scheduler", "outputFunc")
+	// Changes made by firmware (e.g. brightness hotkeys) do not notify file
+	// watchers, so poll for them.
+	m.RefreshInterval(5 * time.Second)
+	m.Output(func(i Info) bar.Output {
+		return outputs.Textf("%d%%", i.Pct())
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency, for changes that are not
+// picked up by watching the brightness file.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// RateLimiter throttles brightness changes from scrolling to once every
+// ~20ms.
+var RateLimiter = rate.NewLimiter(rate.Every(20*time.Millisecond), 1)
+
+// defaultClickHandler raises/lowers the brightness by 5% on scroll.
+func defaultClickHandler(i Info) func(bar.Event) {
+	return func(e bar.Event) {
+		if !RateLimiter.Allow() {
+			return
+		}
+		step := i.Max / 20
+		if step == 0 {
+			step = 1
+		}
+		switch e.Button {
+		case bar.ScrollUp:
+			i.SetBrightness(i.Brightness + step)
+		case bar.ScrollDown:
+			i.SetBrightness(i.Brightness - step)
+		}
+	}
+}
+
+const baseDir = "/sys/class/backlight"
+
+// Overridden in tests.
+var fs = afero.NewOsFs()
+var busType = dbus.System
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	device := m.device
+	if device == "" {
+		var err error
+		if device, err = firstDevice(); s.Error(err) {
+			return
+		}
+	}
+	dir := filepath.Join(baseDir, device)
+	w := file.Watch(filepath.Join(dir, "brightness"))
+	defer w.Unsubscribe()
+	session := dbus.WatchProperties(busType,
+		"org.freedesktop.login1",
+		"/org/freedesktop/login1/session/auto",
+		"org.freedesktop.login1.Session")
+	defer session.Unsubscribe()
+	update, updated := notifier.New()
+
+	info, err := read(dir)
+	for {
+		if s.Error(err) {
+			return
+		}
+		info.Device = device
+		info.set = func(brightness int) error {
+			_, err := session.Call("SetBrightness", "backlight", device, uint32(brightness))
+			return err
+		}
+		info.update = update
+		s.Output(outputs.Group(outputFunc(info)).OnClick(defaultClickHandler(info)))
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.Updates:
+			info, err = read(dir)
+		case werr := <-w.Errors:
+			// Polling still picks up any changes.
+			l.Log("%s: not watching %s: %v", l.ID(m), dir, werr)
+			continue
+		case <-updated:
+			info, err = read(dir)
+		case <-m.scheduler.C:
+			info, err = read(dir)
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+	}
+}
+
+func firstDevice() (string, error) {
+	entries, err := afero.ReadDir(fs, baseDir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) == 0 {
+		return "", errors.New("no backlight devices")
+	}
+	sort.Strings(names)
+	return names[0], nil
+}
+
+func read(dir string) (i Info, err error) {
+	if i.Max, err = readInt(filepath.Join(dir, "max_brightness")); err != nil {
+		return i, err
+	}
+	// actual_brightness is the brightness reported by the hardware, which
+	// may differ from the requested brightness.
+	if i.Brightness, err = readInt(filepath.Join(dir, "actual_brightness")); err == nil {
+		return i, nil
+	}
+	i.Brightness, err = readInt(filepath.Join(dir, "brightness"))
+	return i, err
+}
+
+func readInt(filename string) (int, error) {
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", filename, err)
+	}
+	return v, nil
+}
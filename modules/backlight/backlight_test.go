@@ -0,0 +1,155 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backlight
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func init() {
+	RateLimiter = rate.NewLimiter(rate.Inf, 0)
+}
+
+func setupDevice(t *testing.T, name string, brightness, max int) string {
+	dir := filepath.Join(baseDir, name)
+	require.NoError(t, fs.MkdirAll(dir, 0755))
+	writeInt(t, filepath.Join(dir, "max_brightness"), max)
+	writeInt(t, filepath.Join(dir, "brightness"), brightness)
+	return dir
+}
+
+func writeInt(t *testing.T, filename string, v int) {
+	require.NoError(t, afero.WriteFile(fs, filename, []byte(strconv.Itoa(v)+"\n"), 0644))
+}
+
+func setupLogind(t *testing.T) *dbus.TestBusObject {
+	fs = afero.NewMemMapFs()
+	busType = dbus.Test
+	bus := dbus.SetupTestBus()
+	logind := bus.RegisterService("org.freedesktop.login1")
+	return logind.Object("/org/freedesktop/login1/session/auto",
+		"org.freedesktop.login1.Session")
+}
+
+func TestBacklight(t *testing.T) {
+	session := setupLogind(t)
+	setupDevice(t, "acpi_video0", 3, 10)
+	dir := setupDevice(t, "intel_backlight", 480, 960)
+	session.On("SetBrightness", func(args ...interface{}) ([]interface{}, error) {
+		require.Equal(t, []interface{}{"backlight", "intel_backlight", uint32(528)}, args)
+		writeInt(t, filepath.Join(dir, "brightness"), int(args[2].(uint32)))
+		return nil, nil
+	})
+
+	testBar.New(t)
+	testBar.Run(Device("intel_backlight"))
+	out := testBar.NextOutput("on start")
+	out.AssertText([]string{"50%"})
+
+	writeInt(t, filepath.Join(dir, "brightness"), 240)
+	testBar.Tick()
+	testBar.NextOutput("on refresh").AssertText([]string{"25%"})
+
+	writeInt(t, filepath.Join(dir, "brightness"), 480)
+	testBar.Tick()
+	out = testBar.NextOutput("on refresh")
+	out.AssertText([]string{"50%"})
+
+	// 480 + 960/20.
+	out.At(0).Click(bar.Event{Button: bar.ScrollUp})
+	out = testBar.NextOutput("on scroll")
+	out.AssertText([]string{"55%"})
+}
+
+func TestActualBrightness(t *testing.T) {
+	setupLogind(t)
+	dir := setupDevice(t, "amdgpu_bl0", 100, 255)
+	writeInt(t, filepath.Join(dir, "actual_brightness"), 51)
+
+	testBar.New(t)
+	m := New().Output(func(i Info) bar.Output {
+		return outputs.Textf("%s: %d/%d", i.Device, i.Brightness, i.Max)
+	})
+	testBar.Run(m)
+	testBar.NextOutput("on start").AssertText([]string{"amdgpu_bl0: 51/255"})
+}
+
+func TestSetBrightness(t *testing.T) {
+	session := setupLogind(t)
+	dir := setupDevice(t, "intel_backlight", 50, 100)
+	writeInt(t, filepath.Join(dir, "actual_brightness"), 50)
+	requested := make(chan uint32, 10)
+	session.On("SetBrightness", func(args ...interface{}) ([]interface{}, error) {
+		requested <- args[2].(uint32)
+		if args[2].(uint32) == 0 {
+			return nil, errors.New("denied")
+		}
+		// actual_brightness is not watched, so only the update after setting
+		// the brightness will refresh the output.
+		writeInt(t, filepath.Join(dir, "actual_brightness"), int(args[2].(uint32)))
+		return nil, nil
+	})
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Textf("%d", i.Pct())
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"50"})
+	info := <-infos
+
+	info.SetPct(120)
+	require.Equal(t, uint32(100), <-requested)
+	testBar.NextOutput("on set brightness").AssertText([]string{"100"})
+	info = <-infos
+
+	info.SetBrightness(-5)
+	require.Equal(t, uint32(0), <-requested)
+	testBar.AssertNoOutput("on error setting brightness")
+
+	info.SetPct(100)
+	testBar.AssertNoOutput("on setting to current brightness")
+	require.Empty(t, requested)
+}
+
+func TestErrors(t *testing.T) {
+	setupLogind(t)
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput("no devices").AssertError()
+
+	dir := setupDevice(t, "intel_backlight", 50, 100)
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(dir, "max_brightness"), []byte("foo"), 0644))
+	testBar.New(t)
+	testBar.Run(Device("intel_backlight"))
+	testBar.NextOutput("invalid max_brightness").AssertError()
+
+	testBar.New(t)
+	testBar.Run(Device("nonexistent"))
+	testBar.NextOutput("nonexistent device").AssertError()
+}
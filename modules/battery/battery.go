@@ -18,6 +18,7 @@ package battery
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"strconv"
@@ -64,18 +65,35 @@ type Info struct {
 	EnergyNow float64
 	// Power currently being drawn from the battery, in W.
 	Power float64
+	// ChargeThreshold is the capacity, in percent, at which the battery
+	// stops charging, or 0 if no threshold is configured.
+	ChargeThreshold int
 	// Current voltage of the batter, in V.
 	Voltage float64
 	// Status of the battery, e.g. "Charging", "Full", "Disconnected".
 	Status Status
 	// Technology of the battery, e.g. "Li-Ion", "Li-Poly", "Ni-MH".
 	Technology string
+	// Kind of device the battery powers, e.g. "battery", "ups", "mouse".
+	// Only available from UPower.
+	Kind string
+	// Model of the device. Only available from UPower.
+	Model string
+	// WarningLevel of the device. Only available from UPower.
+	WarningLevel WarningLevel
+	// estimate is the remaining time (to empty or full, depending on the
+	// status) computed by UPower, if available.
+	estimate time.Duration
+	// avgPower is the power averaged over recent energy readings, or 0 if
+	// there are not enough readings.
+	avgPower float64
 }
 
 // Remaining returns the fraction of battery capacity remaining.
 func (i Info) Remaining() float64 {
 	if math.Nextafter(i.EnergyFull, 0) == 0 {
-		return 0
+		// Some devices (e.g. wireless peripherals) only report a percentage.
+		return float64(i.Capacity) / 100
 	}
 	return i.EnergyNow / i.EnergyFull
 }
@@ -85,21 +103,49 @@ func (i Info) RemainingPct() int {
 	return int(i.Remaining() * 100)
 }
 
-// RemainingTime returns the best guess for remaining time.
-// This is based on the current power draw and remaining capacity.
+// AveragePower returns the power drawn from (or supplied to) the battery,
+// averaged over recent readings. If the battery has not been read for long
+// enough, it returns the current power instead.
+func (i Info) AveragePower() float64 {
+	if i.avgPower > 0 {
+		return i.avgPower
+	}
+	return i.Power
+}
+
+// chargeTarget returns the energy at which the battery will stop charging,
+// in Wh, taking into account the charge threshold.
+func (i Info) chargeTarget() float64 {
+	if i.ChargeThreshold > 0 && i.ChargeThreshold < 100 {
+		return i.EnergyFull * float64(i.ChargeThreshold) / 100
+	}
+	return i.EnergyFull
+}
+
+// RemainingTime returns the best guess for remaining time, until the battery
+// is empty when discharging, or until it reaches the charge threshold when
+// charging. This is based on the average power and remaining capacity,
+// unless a smoothed estimate is available from UPower.
 func (i Info) RemainingTime() time.Duration {
+	if i.estimate > 0 {
+		return i.estimate
+	}
+	power := i.AveragePower()
 	// Battery does not report current draw,
 	// cannot estimate remaining time.
-	if math.Nextafter(i.Power, 0) == 0 {
+	if math.Nextafter(power, 0) == 0 {
 		return 0
 	}
 	// According to ACPI spec, these calculations will return hours.
 	hours := 0.0
 	switch i.Status {
 	case Charging:
-		hours = (i.EnergyFull - i.EnergyNow) / i.Power
+		hours = (i.chargeTarget() - i.EnergyNow) / power
 	case Discharging:
-		hours = i.EnergyNow / i.Power
+		hours = i.EnergyNow / power
+	}
+	if hours < 0 {
+		return 0
 	}
 	return time.Duration(int(hours*3600)) * time.Second
 }
@@ -171,6 +217,11 @@ func (m *Module) RefreshInterval(interval time.Duration) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	info := m.updateFunc()
 	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
@@ -178,6 +229,8 @@ func (m *Module) Stream(s bar.Sink) {
 	for {
 		s.Output(outputFunc(info))
 		select {
+		case <-ctx.Done():
+			return
 		case <-m.scheduler.C:
 			info = m.updateFunc()
 		case <-nextOutputFunc:
@@ -249,8 +302,8 @@ func batteryInfo(name string) Info {
 	s.Split(bufio.ScanLines)
 
 	var info Info
-	var energyNow, powerNow, energyFull, energyMax electricValue
-	var energyNowProvided = false
+	var energyNow, powerNow, currentNow, energyFull, energyMax electricValue
+	var energyNowProvided, thresholdProvided = false, false
 	for s.Scan() {
 		line := strings.TrimSpace(s.Text())
 		if !strings.Contains(line, "=") {
@@ -278,7 +331,7 @@ func batteryInfo(name string) Info {
 		case "ENERGY_FULL_DESIGN":
 			energyMax = uwatts(value)
 		case "CURRENT_NOW":
-			powerNow = uamps(value)
+			currentNow = uamps(value)
 		case "POWER_NOW":
 			powerNow = uwatts(value)
 		case "VOLTAGE_NOW":
@@ -289,6 +342,16 @@ func batteryInfo(name string) Info {
 			info.Technology = value
 		case "CAPACITY":
 			info.Capacity, _ = strconv.Atoi(value)
+		case "CHARGE_CONTROL_END_THRESHOLD":
+			info.ChargeThreshold, _ = strconv.Atoi(value)
+			thresholdProvided = true
+		}
+	}
+	if !thresholdProvided {
+		// Older kernels do not include the threshold in the uevent.
+		thresholdPath := fmt.Sprintf("/sys/class/power_supply/%s/charge_control_end_threshold", name)
+		if threshold, err := afero.ReadFile(fs, thresholdPath); err == nil {
+			info.ChargeThreshold, _ = strconv.Atoi(strings.TrimSpace(string(threshold)))
 		}
 	}
 
@@ -304,6 +367,12 @@ func batteryInfo(name string) Info {
 
 	info.EnergyMax = energyMax.toWatts(info.Voltage)
 	info.Power = powerNow.toWatts(info.Voltage)
+	if math.Nextafter(info.Power, 0) == 0 {
+		// Some firmware omits POWER_NOW, or always reports it as 0, but
+		// the power can still be computed from the current and voltage.
+		info.Power = currentNow.toWatts(info.Voltage)
+	}
+	info.avgPower = averagePower(name, info)
 	return info
 }
 
@@ -335,7 +404,8 @@ func allBatteriesInfo() Info {
 	}
 	var allInfo Info
 	var techs []string
-	var voltEnergySum float64
+	var voltEnergySum, chargeTarget, signedAvgPower float64
+	hasThreshold := false
 	for _, info := range infos {
 		allInfo.EnergyFull += info.EnergyFull
 		allInfo.EnergyMax += info.EnergyMax
@@ -344,6 +414,15 @@ func allBatteriesInfo() Info {
 			techs = append(techs, info.Technology)
 		}
 		voltEnergySum += info.Voltage * info.EnergyNow
+		chargeTarget += info.chargeTarget()
+		if info.ChargeThreshold > 0 {
+			hasThreshold = true
+		}
+		if info.Discharging() {
+			signedAvgPower -= info.AveragePower()
+		} else {
+			signedAvgPower += info.AveragePower()
+		}
 		signedPower := allInfo.SignedPower() + info.SignedPower()
 		allInfo.Power = math.Abs(signedPower)
 
@@ -367,5 +446,9 @@ func allBatteriesInfo() Info {
 	allInfo.Voltage = voltEnergySum / allInfo.EnergyNow
 	allInfo.Capacity = int(allInfo.EnergyNow * 100.0 / allInfo.EnergyFull)
 	allInfo.Technology = strings.Join(techs, ",")
+	allInfo.avgPower = math.Abs(signedAvgPower)
+	if hasThreshold {
+		allInfo.ChargeThreshold = int(math.Round(chargeTarget * 100.0 / allInfo.EnergyFull))
+	}
 	return allInfo
 }
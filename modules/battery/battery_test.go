@@ -24,6 +24,7 @@ import (
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/outputs"
 	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
@@ -367,3 +368,118 @@ func TestCombined(t *testing.T) {
 	testBar.NextOutput().AssertText([]string{
 		"Discharging - 50/5h0m0s"})
 }
+
+func TestPowerFromCurrent(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	write(battery{
+		"NAME":        "BAT0",
+		"STATUS":      "Discharging",
+		"VOLTAGE_NOW": 12 * micros,
+		"POWER_NOW":   0,
+		"CURRENT_NOW": int(0.5 * float64(micros)),
+		"ENERGY_FULL": 60 * micros,
+		"ENERGY_NOW":  30 * micros,
+	})
+	info := batteryInfo("BAT0")
+	require.InDelta(t, 6.0, info.Power, 0.01)
+	require.Equal(t, 5*time.Hour, info.RemainingTime())
+}
+
+func TestChargeThreshold(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	bat := battery{
+		"NAME":        "BAT0",
+		"STATUS":      "Charging",
+		"VOLTAGE_NOW": 12 * micros,
+		"POWER_NOW":   10 * micros,
+		"ENERGY_FULL": 60 * micros,
+		"ENERGY_NOW":  30 * micros,
+	}
+	write(bat)
+	info := batteryInfo("BAT0")
+	require.Equal(t, 0, info.ChargeThreshold)
+	require.Equal(t, 3*time.Hour, info.RemainingTime())
+
+	afero.WriteFile(fs, "/sys/class/power_supply/BAT0/charge_control_end_threshold", []byte("80\n"), 0644)
+	info = batteryInfo("BAT0")
+	require.Equal(t, 80, info.ChargeThreshold)
+	// 80% of 60Wh is 48Wh, 18Wh to go at 10W.
+	require.Equal(t, 108*time.Minute, info.RemainingTime())
+
+	bat["CHARGE_CONTROL_END_THRESHOLD"] = 60
+	write(bat)
+	info = batteryInfo("BAT0")
+	require.Equal(t, 60, info.ChargeThreshold)
+	require.Equal(t, 36*time.Minute, info.RemainingTime())
+
+	bat["ENERGY_NOW"] = 40 * micros
+	write(bat)
+	require.Equal(t, time.Duration(0), batteryInfo("BAT0").RemainingTime(),
+		"above threshold")
+
+	write(battery{
+		"NAME":                         "BAT1",
+		"STATUS":                       "Charging",
+		"VOLTAGE_NOW":                  12 * micros,
+		"POWER_NOW":                    10 * micros,
+		"ENERGY_FULL":                  40 * micros,
+		"ENERGY_NOW":                   20 * micros,
+		"CHARGE_CONTROL_END_THRESHOLD": 100,
+	})
+	bat["ENERGY_NOW"] = 30 * micros
+	write(bat)
+	info = allBatteriesInfo()
+	// 36Wh + 40Wh of 100Wh.
+	require.Equal(t, 76, info.ChargeThreshold)
+	// 26Wh to go at 20W.
+	require.Equal(t, 78*time.Minute, info.RemainingTime())
+}
+
+func TestAveragePower(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	timing.TestMode()
+	bat := battery{
+		"NAME":        "BAT0",
+		"STATUS":      "Discharging",
+		"VOLTAGE_NOW": 12 * micros,
+		"POWER_NOW":   30 * micros,
+		"ENERGY_FULL": 60 * micros,
+		"ENERGY_NOW":  30 * micros,
+	}
+	write(bat)
+	info := batteryInfo("BAT0")
+	require.InDelta(t, 30.0, info.AveragePower(), 0.01, "no history")
+	require.Equal(t, time.Hour, info.RemainingTime())
+
+	timing.AdvanceBy(10 * time.Second)
+	bat["POWER_NOW"] = 2 * micros
+	bat["ENERGY_NOW"] = int(29.98 * float64(micros))
+	write(bat)
+	info = batteryInfo("BAT0")
+	require.InDelta(t, 2.0, info.AveragePower(), 0.01, "not enough history")
+
+	for i := 0; i < 10; i++ {
+		timing.AdvanceBy(10 * time.Second)
+		bat["POWER_NOW"] = (5 + i%2*20) * micros
+		bat["ENERGY_NOW"] = int((29.98 - float64(i+1)*0.03) * float64(micros))
+		write(bat)
+		info = batteryInfo("BAT0")
+	}
+	// 0.32Wh over 110s.
+	require.InDelta(t, 10.47, info.AveragePower(), 0.01, "average over history")
+	require.InDelta(t, 25.0, info.Power, 0.01)
+	require.Equal(t, 2*time.Hour+50*time.Minute+2*time.Second, info.RemainingTime())
+
+	timing.AdvanceBy(10 * time.Minute)
+	bat["ENERGY_NOW"] = int(27.68 * float64(micros))
+	write(bat)
+	info = batteryInfo("BAT0")
+	require.InDelta(t, 25.0, info.AveragePower(), 0.01, "old readings dropped")
+
+	timing.AdvanceBy(time.Minute)
+	bat["STATUS"] = "Charging"
+	bat["ENERGY_NOW"] = 29 * micros
+	write(bat)
+	info = batteryInfo("BAT0")
+	require.InDelta(t, 25.0, info.AveragePower(), 0.01, "history reset on status change")
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package battery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/timing"
+)
+
+const (
+	// historyWindow is how long energy readings are kept for computing the
+	// average power of a battery.
+	historyWindow = 5 * time.Minute
+	// minHistory is the minimum span of energy readings needed to compute
+	// the average power. Energy is usually reported in fairly coarse steps,
+	// so shorter spans give wildly inaccurate results.
+	minHistory = 30 * time.Second
+)
+
+type energyReading struct {
+	when   time.Time
+	energy float64
+}
+
+// history tracks recent energy readings of a battery while it is
+// continuously charging or discharging.
+type history struct {
+	status   Status
+	readings []energyReading
+}
+
+var (
+	historyMu sync.Mutex
+	histories = map[string]*history{}
+)
+
+// averagePower records the energy of the named battery, and returns the
+// average power over its recent history, or 0 if there is not enough history
+// to compute an average.
+func averagePower(name string, info Info) float64 {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	now := timing.Now()
+	h := histories[name]
+	if h == nil || h.status != info.Status {
+		h = &history{status: info.Status}
+		histories[name] = h
+	}
+	if info.Status != Charging && info.Status != Discharging {
+		return 0
+	}
+	if n := len(h.readings); n > 0 {
+		last := h.readings[n-1]
+		if now.Before(last.when) {
+			h.readings = nil
+		} else if now.Equal(last.when) {
+			h.readings = h.readings[:n-1]
+		}
+	}
+	for len(h.readings) > 0 && now.Sub(h.readings[0].when) > historyWindow {
+		h.readings = h.readings[1:]
+	}
+	h.readings = append(h.readings, energyReading{now, info.EnergyNow})
+
+	first := h.readings[0]
+	elapsed := now.Sub(first.when)
+	if elapsed < minHistory {
+		return 0
+	}
+	delta := info.EnergyNow - first.energy
+	if info.Status == Discharging {
+		delta = -delta
+	}
+	if delta <= 0 {
+		// Energy has not changed enough to be reported, or is changing in the
+		// opposite direction (e.g. the status is stale).
+		return 0
+	}
+	return delta / elapsed.Hours()
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package battery
+
+import (
+	"context"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+)
+
+// WarningLevel represents the warning level of a device, as computed by
+// UPower based on its configured thresholds.
+type WarningLevel uint32
+
+const (
+	// WarningUnknown is used when the warning level is not available, e.g.
+	// for batteries read from sysfs.
+	WarningUnknown WarningLevel = iota
+	// WarningNone represents a device that is not low on charge.
+	WarningNone
+	// WarningDischarging represents a UPS that is discharging.
+	WarningDischarging
+	// WarningLow represents a device that is low on charge.
+	WarningLow
+	// WarningCritical represents a device that is critically low on charge.
+	WarningCritical
+	// WarningAction represents a device so low on charge that the system
+	// is about to take action (e.g. hibernate).
+	WarningAction
+)
+
+// upowerKinds maps UPower device types to names, following upower(1).
+var upowerKinds = []string{
+	"unknown", "line-power", "battery", "ups", "monitor", "mouse",
+	"keyboard", "pda", "phone", "media-player", "tablet", "computer",
+	"gaming-input", "pen", "touchpad", "modem", "network", "headset",
+	"speakers", "headphones", "video", "other-audio", "remote-control",
+	"printer", "scanner", "camera", "wearable", "toy", "bluetooth-generic",
+}
+
+// upowerTechnologies maps UPower battery technologies to the names used by
+// the kernel's power_supply class.
+var upowerTechnologies = []string{
+	"", "Li-ion", "Li-poly", "LiFe", "Lead-acid", "NiCd", "NiMH",
+}
+
+const upowerDevicePrefix = "/org/freedesktop/UPower/devices/"
+
+var busType = dbus.System // Overridden in tests.
+
+// UPowerModule represents a battery bar module that gets its information from
+// UPower over D-Bus. Unlike Module, it does not poll, and updates whenever
+// UPower reports a change.
+type UPowerModule struct {
+	path       string
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+func newUPowerModule(path string) *UPowerModule {
+	m := &UPowerModule{path: path}
+	l.Register(m, "outputFunc")
+	m.Output(func(i Info) bar.Output {
+		return outputs.Textf("BATT %d%%", i.RemainingPct())
+	})
+	return m
+}
+
+// UPower constructs a battery module for the named UPower device. The name
+// is the last element of the device's object path, e.g. "battery_BAT0", or
+// "mouse_hidpp_battery_0". Run `upower -e` to list the available devices.
+func UPower(device string) *UPowerModule {
+	m := newUPowerModule(upowerDevicePrefix + device)
+	l.Label(m, device)
+	return m
+}
+
+// UPowerDisplay constructs a battery module for UPower's display device, which
+// aggregates all batteries that power the system.
+func UPowerDisplay() *UPowerModule {
+	return newUPowerModule(upowerDevicePrefix + "DisplayDevice")
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *UPowerModule) Output(outputFunc func(Info) bar.Output) *UPowerModule {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// Stream starts the module.
+func (m *UPowerModule) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *UPowerModule) StreamContext(ctx context.Context, s bar.Sink) {
+	w := dbus.WatchProperties(busType,
+		"org.freedesktop.UPower", m.path, "org.freedesktop.UPower.Device").
+		Add("Type", "Model", "IsPresent", "State", "Percentage",
+			"Energy", "EnergyFull", "EnergyFullDesign", "EnergyRate",
+			"Voltage", "TimeToEmpty", "TimeToFull", "Technology", "WarningLevel",
+			"ChargeThresholdEnabled", "ChargeEndThreshold")
+	defer w.Unsubscribe()
+
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	info := upowerInfo(w.Get())
+	for {
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.Updates:
+			info = upowerInfo(w.Get())
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+	}
+}
+
+func upowerInfo(props map[string]interface{}) Info {
+	if present, _ := props["IsPresent"].(bool); !present {
+		return Info{Status: Disconnected}
+	}
+	var info Info
+	if kind, ok := props["Type"].(uint32); ok && int(kind) < len(upowerKinds) {
+		info.Kind = upowerKinds[kind]
+	}
+	info.Model, _ = props["Model"].(string)
+	pct, _ := props["Percentage"].(float64)
+	info.Capacity = int(pct)
+	info.EnergyNow, _ = props["Energy"].(float64)
+	info.EnergyFull, _ = props["EnergyFull"].(float64)
+	info.EnergyMax, _ = props["EnergyFullDesign"].(float64)
+	info.Power, _ = props["EnergyRate"].(float64)
+	info.Voltage, _ = props["Voltage"].(float64)
+	if tech, ok := props["Technology"].(uint32); ok && int(tech) < len(upowerTechnologies) {
+		info.Technology = upowerTechnologies[tech]
+	}
+	if level, ok := props["WarningLevel"].(uint32); ok {
+		info.WarningLevel = WarningLevel(level)
+	}
+	if enabled, _ := props["ChargeThresholdEnabled"].(bool); enabled {
+		threshold, _ := props["ChargeEndThreshold"].(uint32)
+		info.ChargeThreshold = int(threshold)
+	}
+	toEmpty, _ := props["TimeToEmpty"].(int64)
+	toFull, _ := props["TimeToFull"].(int64)
+	state, _ := props["State"].(uint32)
+	switch state {
+	case 1: // Charging
+		info.Status = Charging
+		info.estimate = time.Duration(toFull) * time.Second
+	case 2, 3: // Discharging, Empty
+		info.Status = Discharging
+		info.estimate = time.Duration(toEmpty) * time.Second
+	case 4: // Fully charged
+		info.Status = Full
+	case 5, 6: // Pending charge, Pending discharge
+		info.Status = NotCharging
+	default:
+		info.Status = Unknown
+	}
+	return info
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package battery
+
+import (
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupUPowerDevice(device string) *dbus.TestBusObject {
+	busType = dbus.Test
+	bus := dbus.SetupTestBus()
+	upower := bus.RegisterService("org.freedesktop.UPower")
+	return upower.Object(godbus.ObjectPath(upowerDevicePrefix+device), "org.freedesktop.UPower.Device")
+}
+
+func TestUPower(t *testing.T) {
+	dev := setupUPowerDevice("battery_BAT0")
+	dev.SetProperties(map[string]interface{}{
+		"Type":             uint32(2),
+		"Model":            "5B10W13930",
+		"IsPresent":        true,
+		"State":            uint32(2),
+		"Percentage":       float64(50),
+		"Energy":           25.0,
+		"EnergyFull":       50.0,
+		"EnergyFullDesign": 57.0,
+		"EnergyRate":       10.0,
+		"Voltage":          11.9,
+		"TimeToEmpty":      int64(3 * 3600),
+		"TimeToFull":       int64(0),
+		"Technology":       uint32(2),
+		"WarningLevel":     uint32(1),
+	}, dbus.SignalTypeNone)
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	testBar.Run(UPower("battery_BAT0").Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Textf("%d%% %v", i.RemainingPct(), i.RemainingTime())
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"50% 3h0m0s"})
+
+	info := <-infos
+	require.Equal(t, "battery", info.Kind)
+	require.Equal(t, "5B10W13930", info.Model)
+	require.Equal(t, Discharging, info.Status)
+	require.Equal(t, "Li-poly", info.Technology)
+	require.Equal(t, WarningNone, info.WarningLevel)
+	require.Equal(t, 50, info.Capacity)
+	require.InDelta(t, 57.0, info.EnergyMax, 0.001)
+	require.InDelta(t, 11.9, info.Voltage, 0.001)
+	require.InDelta(t, -10.0, info.SignedPower(), 0.001)
+
+	dev.SetProperties(map[string]interface{}{
+		"Percentage":   float64(5),
+		"Energy":       2.5,
+		"TimeToEmpty":  int64(900),
+		"WarningLevel": uint32(4),
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on properties changed").AssertText([]string{"5% 15m0s"})
+	require.Equal(t, WarningCritical, (<-infos).WarningLevel)
+
+	dev.SetProperties(map[string]interface{}{
+		"State":       uint32(1),
+		"TimeToEmpty": int64(0),
+		"TimeToFull":  int64(0),
+	}, dbus.SignalTypeChanged)
+	// Without an estimate from UPower, falls back to computing remaining time.
+	testBar.NextOutput("on charging").AssertText([]string{"5% 4h45m0s"})
+	require.Equal(t, Charging, (<-infos).Status)
+
+	dev.SetProperties(map[string]interface{}{
+		"State":      uint32(4),
+		"Percentage": float64(100),
+		"Energy":     50.0,
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on full").AssertText([]string{"100% 0s"})
+	require.True(t, (<-infos).PluggedIn())
+}
+
+func TestUPowerPeripheral(t *testing.T) {
+	dev := setupUPowerDevice("mouse_hidpp_battery_0")
+	dev.SetProperties(map[string]interface{}{
+		"Type":         uint32(5),
+		"Model":        "MX Master 3",
+		"IsPresent":    true,
+		"State":        uint32(2),
+		"Percentage":   float64(70),
+		"WarningLevel": uint32(1),
+	}, dbus.SignalTypeNone)
+
+	testBar.New(t)
+	testBar.Run(UPower("mouse_hidpp_battery_0").Output(func(i Info) bar.Output {
+		if i.Status == Disconnected {
+			return outputs.Text("disconnected")
+		}
+		return outputs.Textf("%s %s: %d%%", i.Kind, i.Model, i.RemainingPct())
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"mouse MX Master 3: 70%"})
+
+	dev.SetPropertyForTest("IsPresent", false, dbus.SignalTypeChanged)
+	testBar.NextOutput("on disconnect").AssertText([]string{"disconnected"})
+}
+
+func TestUPowerDisplay(t *testing.T) {
+	dev := setupUPowerDevice("DisplayDevice")
+	testBar.New(t)
+	testBar.Run(UPowerDisplay())
+	testBar.NextOutput("on start").AssertText([]string{"BATT 0%"})
+
+	dev.SetProperties(map[string]interface{}{
+		"Type":       uint32(2),
+		"IsPresent":  true,
+		"State":      uint32(4),
+		"Percentage": float64(100),
+		"Energy":     80.0,
+		"EnergyFull": 80.0,
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on properties changed").AssertText([]string{"BATT 100%"})
+}
@@ -16,6 +16,8 @@
 package bluetooth
 
 import (
+	"context"
+
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/value"
 	"github.com/soumya92/barista/base/watchers/dbus"
@@ -59,6 +61,11 @@ func (bt *AdapterModule) Output(outputFunc func(AdapterInfo) bar.Output) *Adapte
 
 // Stream starts the module.
 func (bt *AdapterModule) Stream(sink bar.Sink) {
+	bt.StreamContext(context.Background(), sink)
+}
+
+// StreamContext implements bar.ContextModule.
+func (bt *AdapterModule) StreamContext(ctx context.Context, sink bar.Sink) {
 	w := dbus.WatchProperties(
 		busType,
 		"org.bluez",
@@ -76,6 +83,8 @@ func (bt *AdapterModule) Stream(sink bar.Sink) {
 	for {
 		sink.Output(outputFunc(info))
 		select {
+		case <-ctx.Done():
+			return
 		case <-w.Updates:
 			info = getAdapterInfo(w)
 		case <-nextOutputFunc:
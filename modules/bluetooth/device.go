@@ -16,6 +16,7 @@
 package bluetooth
 
 import (
+	"context"
 	"strings"
 
 	godbus "github.com/godbus/dbus/v5"
@@ -58,6 +59,11 @@ func (m *DeviceModule) Output(outputFunc func(DeviceInfo) bar.Output) *DeviceMod
 
 // Stream starts the module.
 func (m *DeviceModule) Stream(sink bar.Sink) {
+	m.StreamContext(context.Background(), sink)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *DeviceModule) StreamContext(ctx context.Context, sink bar.Sink) {
 	w := dbus.WatchProperties(
 		busType,
 		"org.bluez",
@@ -83,6 +89,8 @@ func (m *DeviceModule) Stream(sink bar.Sink) {
 	for {
 		sink.Output(outputFunc(info))
 		select {
+		case <-ctx.Done():
+			return
 		case <-w.Updates:
 			info = getDeviceInfo(w, batt)
 		case <-batt.Updates:
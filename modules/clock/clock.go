@@ -16,6 +16,7 @@
 package clock
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -125,6 +126,11 @@ func (m *Module) Timezone(timezone *time.Location) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	sch, err := timing.NewRealtimeScheduler()
 	if s.Error(err) {
 		return
@@ -154,6 +160,8 @@ func (m *Module) Stream(s bar.Sink) {
 		s.Output(cfg.outputFunc(now))
 
 		select {
+		case <-ctx.Done():
+			return
 		case <-sch.C:
 		case <-tzChange:
 			tzChange = nil
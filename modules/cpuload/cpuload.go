@@ -14,11 +14,13 @@
 
 // Package cpuload implements an i3bar module that shows load averages.
 // Deprecated in favour of SysInfo, which can show more than just load average.
+//
+// When built with cgo, load averages are read using getloadavg(3). Otherwise,
+// they are read from /proc/loadavg.
 package cpuload
 
-//#include <stdlib.h>
-import "C"
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -80,6 +82,11 @@ func (m *Module) RefreshInterval(interval time.Duration) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	var loads LoadAvg
 	count, err := getloadavg(&loads, 3)
 	outputFunc := m.outputFunc.Get().(func(LoadAvg) bar.Output)
@@ -95,6 +102,8 @@ func (m *Module) Stream(s bar.Sink) {
 		}
 		s.Output(outputFunc(loads))
 		select {
+		case <-ctx.Done():
+			return
 		case <-m.scheduler.C:
 			count, err = getloadavg(&loads, 3)
 		case <-nextOutputFunc:
@@ -102,9 +111,3 @@ func (m *Module) Stream(s bar.Sink) {
 		}
 	}
 }
-
-// To allow tests to mock out getloadavg.
-var getloadavg = func(out *LoadAvg, count int) (int, error) {
-	read, err := C.getloadavg((*C.double)(&out[0]), (C.int)(count))
-	return int(read), err
-}
@@ -25,6 +25,7 @@ import (
 	testBar "github.com/soumya92/barista/testing/bar"
 	"github.com/soumya92/barista/timing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -133,3 +134,38 @@ func TestErrors(t *testing.T) {
 	errs = testBar.NextOutput().AssertError("on restart with error")
 	require.Equal("test", errs[0], "error string is passed through")
 }
+
+func TestProcLoadavg(t *testing.T) {
+	require := require.New(t)
+	fs = afero.NewMemMapFs()
+
+	var loads LoadAvg
+	count, err := procLoadavg(&loads, 3)
+	require.Error(err, "when /proc/loadavg is missing")
+	require.Equal(-1, count)
+
+	afero.WriteFile(fs, "/proc/loadavg", []byte("0.52 1.25 2.00 2/871 12345\n"), 0444)
+	count, err = procLoadavg(&loads, 3)
+	require.NoError(err)
+	require.Equal(3, count)
+	require.Equal(LoadAvg{0.52, 1.25, 2.0}, loads)
+
+	loads = LoadAvg{}
+	count, err = procLoadavg(&loads, 1)
+	require.NoError(err)
+	require.Equal(1, count, "reads only requested samples")
+	require.Equal(LoadAvg{0.52, 0, 0}, loads)
+
+	count, _ = procLoadavg(&loads, 5)
+	require.Equal(3, count, "reads at most 3 samples")
+
+	afero.WriteFile(fs, "/proc/loadavg", []byte("0.52"), 0444)
+	count, err = procLoadavg(&loads, 3)
+	require.NoError(err)
+	require.Equal(1, count, "with truncated file")
+
+	afero.WriteFile(fs, "/proc/loadavg", []byte("0.52 abc 2.00"), 0444)
+	count, err = procLoadavg(&loads, 3)
+	require.Error(err, "with non-numeric value")
+	require.Equal(-1, count)
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+// +build cgo
+
+package cpuload
+
+//#include <stdlib.h>
+import "C"
+
+// To allow tests to mock out getloadavg.
+var getloadavg = func(out *LoadAvg, count int) (int, error) {
+	read, err := C.getloadavg((*C.double)(&out[0]), (C.int)(count))
+	return int(read), err
+}
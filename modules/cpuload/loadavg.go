@@ -0,0 +1,45 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpuload
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var fs = afero.NewOsFs()
+
+// procLoadavg is a pure Go implementation of getloadavg, reading up to
+// count load averages from /proc/loadavg. Like getloadavg, it returns the
+// number of samples read, or -1 on error.
+func procLoadavg(out *LoadAvg, count int) (int, error) {
+	data, err := afero.ReadFile(fs, "/proc/loadavg")
+	if err != nil {
+		return -1, err
+	}
+	fields := strings.Fields(string(data))
+	if count > len(out) {
+		count = len(out)
+	}
+	read := 0
+	for ; read < count && read < len(fields); read++ {
+		if out[read], err = strconv.ParseFloat(fields[read], 64); err != nil {
+			return -1, err
+		}
+	}
+	return read, nil
+}
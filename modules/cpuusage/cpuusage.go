@@ -0,0 +1,242 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cpuusage provides an i3bar module that shows CPU utilisation, both
+// in total and for each core, based on /proc/stat.
+package cpuusage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+)
+
+// Usage is a breakdown of CPU time between samples. All values are fractions
+// of the total time, in the range [0, 1].
+type Usage struct {
+	User    float64
+	Nice    float64
+	System  float64
+	Idle    float64
+	IOWait  float64
+	IRQ     float64
+	SoftIRQ float64
+	Steal   float64
+}
+
+// Busy returns the fraction of time the CPU was busy. Time spent waiting for
+// IO is not counted, since the CPU is idle (and available) during that time.
+func (u Usage) Busy() float64 {
+	return u.User + u.Nice + u.System + u.IRQ + u.SoftIRQ + u.Steal
+}
+
+// Info represents CPU utilisation since the previous sample.
+type Info struct {
+	Total Usage
+	// Cores contains the utilisation of each online CPU, in order.
+	Cores []Usage
+}
+
+// currentInfo stores the last value read by the updater.
+// This allows newly created modules to start with data.
+var currentInfo = new(value.ErrorValue) // of Info
+
+var once sync.Once
+var updater *timing.Scheduler
+
+// construct initialises cpuusage's global updating. All cpuusage
+// modules are updated with just one read of /proc/stat.
+func construct() {
+	once.Do(func() {
+		updater = timing.NewScheduler()
+		l.Attach(nil, &currentInfo, "cpuusage.currentInfo")
+		l.Attach(nil, updater, "cpuusage.updater")
+		updater.Every(3 * time.Second)
+		update()
+		go func(updater *timing.Scheduler) {
+			for range updater.C {
+				update()
+			}
+		}(updater)
+	})
+}
+
+// RefreshInterval configures the polling frequency. Utilisation is computed
+// over this interval.
+func RefreshInterval(interval time.Duration) {
+	construct()
+	updater.Every(interval)
+}
+
+// Module represents a bar.Module that displays CPU utilisation.
+type Module struct {
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+func defaultOutput(i Info) bar.Output {
+	return outputs.Textf("CPU: %.0f%%", i.Total.Busy()*100)
+}
+
+// New creates a new cpuusage module.
+func New() *Module {
+	construct()
+	m := new(Module)
+	l.Register(m, "outputFunc")
+	m.Output(defaultOutput)
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// Stream subscribes to CPU usage and updates the module's output accordingly.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	i, err := currentInfo.Get()
+	nextInfo, done := currentInfo.Subscribe()
+	defer done()
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+	for {
+		if err != nil {
+			s.Error(err)
+		} else if info, ok := i.(Info); ok {
+			s.Output(outputFunc(info))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		case <-nextInfo:
+			i, err = currentInfo.Get()
+		}
+	}
+}
+
+// times holds the cumulative time counters for a single cpu line.
+type times [8]uint64
+
+// usageSince computes the usage between two samples. Counters that went
+// backwards (e.g. a core was hotplugged) are ignored, and if no time passed
+// the usage is treated as idle. If the core was missing from the previous
+// sample, prev is zero, so this returns the usage since boot.
+func (t times) usageSince(prev times) Usage {
+	var d [8]float64
+	total := float64(0)
+	for i := range t {
+		if t[i] >= prev[i] {
+			d[i] = float64(t[i] - prev[i])
+			total += d[i]
+		}
+	}
+	if total == 0 {
+		return Usage{Idle: 1}
+	}
+	return Usage{
+		User:    d[0] / total,
+		Nice:    d[1] / total,
+		System:  d[2] / total,
+		Idle:    d[3] / total,
+		IOWait:  d[4] / total,
+		IRQ:     d[5] / total,
+		SoftIRQ: d[6] / total,
+		Steal:   d[7] / total,
+	}
+}
+
+var fs = afero.NewOsFs()
+
+// previous holds the counters from the last read of /proc/stat, by cpu name.
+var previous map[string]times
+
+func parseTimes(fields []string) (t times, err error) {
+	// user nice system idle iowait irq softirq steal guest guest_nice.
+	var vals [10]uint64
+	for i := 0; i < len(vals) && i < len(fields); i++ {
+		if vals[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+			return t, err
+		}
+	}
+	copy(t[:], vals[:8])
+	// Guest time is also included in user (and nice) time.
+	if t[0] >= vals[8] {
+		t[0] -= vals[8]
+	}
+	if t[1] >= vals[9] {
+		t[1] -= vals[9]
+	}
+	return t, nil
+}
+
+func update() {
+	f, err := fs.Open("/proc/stat")
+	if currentInfo.Error(err) {
+		return
+	}
+	defer f.Close()
+	current := map[string]times{}
+	var names []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		t, err := parseTimes(fields[1:])
+		if currentInfo.Error(err) {
+			return
+		}
+		current[fields[0]] = t
+		if fields[0] != "cpu" {
+			names = append(names, fields[0])
+		}
+	}
+	total, ok := current["cpu"]
+	if !ok {
+		currentInfo.Error(fmt.Errorf("no cpu line in /proc/stat"))
+		return
+	}
+	prev := previous
+	previous = current
+	if prev == nil {
+		// Usage needs two samples, so wait for the next update.
+		return
+	}
+	info := Info{Total: total.usageSince(prev["cpu"])}
+	for _, name := range names {
+		info.Cores = append(info.Cores, current[name].usageSince(prev[name]))
+	}
+	currentInfo.Set(info)
+}
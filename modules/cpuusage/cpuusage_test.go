@@ -0,0 +1,145 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpuusage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func shouldReturn(lines ...string) {
+	stat := strings.Join(lines, "\n") + "\nintr 12345 0 0\nctxt 67890\nbtime 1500000000\n"
+	afero.WriteFile(fs, "/proc/stat", []byte(stat), 0644)
+}
+
+func resetForTest() {
+	currentInfo = &value.ErrorValue{}
+	previous = nil
+	once = sync.Once{}
+	construct()
+}
+
+func usageText(u Usage) string {
+	return fmt.Sprintf("%.0f/%.0f/%.0f/%.0f", u.Busy()*100, u.User*100, u.System*100, u.IOWait*100)
+}
+
+func TestCPUUsage(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	shouldReturn(
+		"cpu  100 0 100 800 0 0 0 0 0 0",
+		"cpu0 50 0 50 400 0 0 0 0 0 0",
+		"cpu1 50 0 50 400 0 0 0 0 0 0",
+	)
+	testBar.New(t)
+	resetForTest()
+
+	def := New()
+	total := New().Output(func(i Info) bar.Output {
+		return outputs.Text(usageText(i.Total))
+	})
+	cores := New().Output(func(i Info) bar.Output {
+		var out []string
+		for _, c := range i.Cores {
+			out = append(out, fmt.Sprintf("%.0f", c.Busy()*100))
+		}
+		return outputs.Text(strings.Join(out, ","))
+	})
+	testBar.Run(def, total, cores)
+	testBar.AssertNoOutput("until second sample")
+
+	shouldReturn(
+		"cpu  150 10 140 1000 100 10 10 30 20 0",
+		"cpu0 100 10 90 400 100 5 5 15 20 0",
+		"cpu1 50 0 50 600 0 5 5 15 0 0",
+	)
+	testBar.Tick()
+	// delta (total): user 30 (50 - 20 guest), nice 10, system 40, idle 200,
+	// iowait 100, irq 10, softirq 10, steal 30 = 430.
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 30%", "30/7/9/23", "51,11"}, "on tick")
+
+	shouldReturn(
+		"cpu  150 10 140 1100 100 10 10 30 20 0",
+		"cpu0 100 10 90 450 100 5 5 15 20 0",
+		"cpu1 50 0 50 650 0 5 5 15 0 0",
+	)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 0%", "0/0/0/0", "0,0"}, "when idle")
+
+	shouldReturn(
+		"cpu  250 10 140 1100 100 10 10 30 20 0",
+		"cpu0 200 10 90 450 100 5 5 15 20 0",
+	)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 100%", "100/100/0/0", "100"}, "when a core goes offline")
+
+	shouldReturn(
+		"cpu  250 10 140 1200 100 10 10 30 20 0",
+		"cpu0 200 10 90 500 100 5 5 15 20 0",
+		"cpu1 50 0 50 700 0 5 5 15 0 0",
+	)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 0%", "0/0/0/0", "0,15"},
+		"when a core comes online, usage is since boot")
+
+	beforeTick := timing.Now()
+	RefreshInterval(time.Minute)
+	testBar.Tick()
+	require.Equal(t, time.Minute, timing.Now().Sub(beforeTick), "RefreshInterval change")
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 0%", "0/0/0/0", "0,0"}, "counters unchanged")
+}
+
+func TestErrors(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	testBar.New(t)
+	resetForTest()
+
+	testBar.Run(New())
+	testBar.LatestOutput().AssertError("on start if missing /proc/stat")
+
+	shouldReturn("cpu0 1 2 3 4 5 6 7 8")
+	testBar.Tick()
+	testBar.LatestOutput().AssertError("missing total cpu line")
+
+	shouldReturn("cpu 1 2 3 4 x 6 7 8")
+	testBar.Tick()
+	testBar.LatestOutput().AssertError("non-numeric value")
+
+	shouldReturn("cpu 1 2 3 4 5 6 7 8")
+	testBar.Tick()
+	shouldReturn("cpu 1 2 3 4 5 6 7 8")
+	testBar.Tick()
+	testBar.LatestOutput().AssertText([]string{"CPU: 0%"}, "when counters are unchanged")
+
+	shouldReturn("cpu 2 2 3 4")
+	testBar.Tick()
+	testBar.LatestOutput().AssertText([]string{"CPU: 100%"}, "with fewer fields")
+}
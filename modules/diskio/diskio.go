@@ -17,6 +17,7 @@ package diskio
 
 import (
 	"bufio"
+	"context"
 	"strconv"
 	"strings"
 	"sync"
@@ -118,12 +119,19 @@ func (m *Module) Output(outputFunc func(IO) bar.Output) *Module {
 // Stream starts the module. Note that diskio updates begin as soon as the
 // first module is constructed, even if no modules are streaming.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	var i IO
 	outputFunc := m.outputFunc.Get().(func(IO) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
 	defer done()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case i = <-m.ioChan:
 		case <-nextOutputFunc:
 			outputFunc = m.outputFunc.Get().(func(IO) bar.Output)
@@ -16,6 +16,7 @@
 package diskspace
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -99,6 +100,11 @@ func (m *Module) RefreshInterval(interval time.Duration) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	info, err := getStatFsInfo(m.path)
 	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
@@ -116,6 +122,8 @@ func (m *Module) Stream(s bar.Sink) {
 			s.Output(outputFunc(info))
 		}
 		select {
+		case <-ctx.Done():
+			return
 		case <-m.scheduler.C:
 			info, err = getStatFsInfo(m.path)
 		case <-nextOutputFunc:
@@ -0,0 +1,356 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hwmon
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/format"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+)
+
+// Kind is the type of a sensor channel.
+type Kind string
+
+// Kinds of sensor channels, named after the hwmon file prefix.
+const (
+	Temperature Kind = "temp"
+	Fan         Kind = "fan"
+	Voltage     Kind = "in"
+	Current     Kind = "curr"
+	Power       Kind = "power"
+)
+
+var kinds = []Kind{Temperature, Fan, Voltage, Current, Power}
+
+// Sensor is a single channel of a hwmon chip.
+type Sensor struct {
+	// Chip is the name of the hwmon chip, e.g. "k10temp" or "nct6775".
+	Chip string
+	// Label is the label of the channel, e.g. "Tctl" or "CPU Fan". For
+	// unlabelled channels, it is the channel name, e.g. "fan2".
+	Label string
+	Kind  Kind
+	// path is the channel prefix, e.g. /sys/class/hwmon/hwmon2/fan2.
+	path string
+}
+
+// ID returns the ID of the sensor, as used in Sensors, e.g. "k10temp/Tctl".
+func (s Sensor) ID() string {
+	return s.Chip + "/" + s.Label
+}
+
+// Reading is the current state of a sensor.
+//
+// Values are typed according to the kind of sensor: unit.Temperature for
+// temperatures, unit.Frequency for fan speeds (see RPM), unit.Voltage,
+// unit.ElectricCurrent, and unit.Power. They can be formatted using
+// format.Unit.
+type Reading struct {
+	Sensor
+	Value interface{}
+	// Min, Max, and Crit are thresholds set for the sensor, or nil if the
+	// sensor does not provide them.
+	Min, Max, Crit interface{}
+	// Alarm is true if the chip has raised an alarm for the sensor, usually
+	// because the value is outside its thresholds.
+	Alarm bool
+}
+
+// RPM returns the speed of a fan sensor in revolutions per minute.
+func (r Reading) RPM() float64 {
+	f, _ := r.Value.(unit.Frequency)
+	return f.Hertz() * 60
+}
+
+// Readings is a list of sensor readings.
+type Readings []Reading
+
+// Find finds a reading by chip name and label.
+func (r Readings) Find(chip, label string) (Reading, bool) {
+	for _, rd := range r {
+		if rd.Chip == chip && rd.Label == label {
+			return rd, true
+		}
+	}
+	return Reading{}, false
+}
+
+var fs = afero.NewOsFs()
+
+const baseDir = "/sys/class/hwmon"
+
+// Discover returns all sensors from all hwmon chips.
+func Discover() []Sensor {
+	dirs, _ := afero.ReadDir(fs, baseDir)
+	sort.Slice(dirs, func(i, j int) bool {
+		return naturalLess(dirs[i].Name(), dirs[j].Name())
+	})
+	var sensors []Sensor
+	for _, d := range dirs {
+		dir := filepath.Join(baseDir, d.Name())
+		name, err := readString(filepath.Join(dir, "name"))
+		if err != nil {
+			continue
+		}
+		sensors = append(sensors, chipSensors(name, dir)...)
+	}
+	return sensors
+}
+
+func chipSensors(chip, dir string) []Sensor {
+	files, _ := afero.ReadDir(fs, dir)
+	var channels []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		channel, attr, ok := strings.Cut(f.Name(), "_")
+		if !ok || seen[channel] || kindOf(channel) == "" {
+			continue
+		}
+		// Some chips only provide average power, not instantaneous power.
+		if attr == "input" || attr == "average" && kindOf(channel) == Power {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return naturalLess(channels[i], channels[j])
+	})
+	var sensors []Sensor
+	for _, channel := range channels {
+		path := filepath.Join(dir, channel)
+		label, err := readString(path + "_label")
+		if err != nil || label == "" {
+			label = channel
+		}
+		sensors = append(sensors, Sensor{
+			Chip:  chip,
+			Label: label,
+			Kind:  kindOf(channel),
+			path:  path,
+		})
+	}
+	return sensors
+}
+
+// kindOf returns the kind of a channel, e.g. "temp" for "temp1".
+func kindOf(channel string) Kind {
+	for _, k := range kinds {
+		num := strings.TrimPrefix(channel, string(k))
+		if num == channel || num == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(num); err == nil {
+			return k
+		}
+	}
+	return ""
+}
+
+// naturalLess sorts names with numeric suffixes in numeric order, so that
+// hwmon2 is before hwmon10.
+func naturalLess(a, b string) bool {
+	aPrefix := strings.TrimRight(a, "0123456789")
+	bPrefix := strings.TrimRight(b, "0123456789")
+	if aPrefix != bPrefix {
+		return aPrefix < bPrefix
+	}
+	aNum, _ := strconv.Atoi(a[len(aPrefix):])
+	bNum, _ := strconv.Atoi(b[len(bPrefix):])
+	return aNum < bNum
+}
+
+func readString(filename string) (string, error) {
+	data, err := afero.ReadFile(fs, filename)
+	return strings.TrimSpace(string(data)), err
+}
+
+func readInt(filename string) (int64, error) {
+	str, err := readString(filename)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
+
+// toUnit converts a raw hwmon value to the typed value for the kind.
+func (k Kind) toUnit(raw int64) interface{} {
+	v := float64(raw)
+	switch k {
+	case Temperature:
+		return unit.FromCelsius(v / 1000)
+	case Fan:
+		return unit.Frequency(v/60) * unit.Hertz
+	case Voltage:
+		return unit.Voltage(v) * unit.Millivolt
+	case Current:
+		return unit.ElectricCurrent(v) * unit.Milliampere
+	case Power:
+		return unit.Power(v) * unit.Microwatt
+	}
+	return nil
+}
+
+// Read reads the current state of the sensor.
+func (s Sensor) Read() (Reading, error) {
+	r := Reading{Sensor: s}
+	raw, err := readInt(s.path + "_input")
+	if err != nil && s.Kind == Power {
+		raw, err = readInt(s.path + "_average")
+	}
+	if err != nil {
+		return r, err
+	}
+	r.Value = s.Kind.toUnit(raw)
+	for attr, dest := range map[string]*interface{}{
+		"min": &r.Min, "max": &r.Max, "crit": &r.Crit,
+	} {
+		if raw, err := readInt(s.path + "_" + attr); err == nil {
+			*dest = s.Kind.toUnit(raw)
+		}
+	}
+	for _, attr := range []string{"alarm", "min_alarm", "max_alarm", "crit_alarm"} {
+		if v, err := readInt(s.path + "_" + attr); err == nil && v != 0 {
+			r.Alarm = true
+		}
+	}
+	return r, nil
+}
+
+// SensorsModule represents a bar.Module that shows readings from one or more
+// hwmon sensors.
+type SensorsModule struct {
+	ids        []string
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Readings) bar.Output
+}
+
+// Sensors constructs a module that shows the given sensors. Each sensor is
+// identified by chip name and label, separated by a slash, e.g.
+// "k10temp/Tctl" or "nct6775/fan2". A chip name alone includes all sensors of
+// that chip, and no arguments include all sensors from all chips.
+func Sensors(ids ...string) *SensorsModule {
+	m := &SensorsModule{ids: ids, scheduler: timing.NewScheduler()}
+	l.Label(m, strings.Join(ids, ","))
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(3 * time.Second)
+	m.Output(func(r Readings) bar.Output {
+		out := outputs.Group()
+		for _, rd := range r {
+			var txt string
+			if rd.Kind == Fan {
+				txt = fmt.Sprintf("%.0f RPM", rd.RPM())
+			} else {
+				v, _ := format.Unit(rd.Value)
+				txt = v.String()
+			}
+			out.Append(outputs.Textf("%s: %s", rd.Label, txt).Urgent(rd.Alarm))
+		}
+		return out
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *SensorsModule) Output(outputFunc func(Readings) bar.Output) *SensorsModule {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency.
+func (m *SensorsModule) RefreshInterval(interval time.Duration) *SensorsModule {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Stream starts the module.
+func (m *SensorsModule) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *SensorsModule) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Readings) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+	// Sensors are discovered when the module starts, since hwmon numbering
+	// can change (e.g. when a device is hotplugged). Restarting the module
+	// after an error discovers them again.
+	sensors, err := m.find()
+	if s.Error(err) {
+		return
+	}
+	readings, err := read(sensors)
+	for {
+		if s.Error(err) {
+			return
+		}
+		s.Output(outputFunc(readings))
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			readings, err = read(sensors)
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Readings) bar.Output)
+		}
+	}
+}
+
+func (m *SensorsModule) find() ([]Sensor, error) {
+	all := Discover()
+	if len(m.ids) == 0 {
+		return all, nil
+	}
+	var sensors []Sensor
+	for _, id := range m.ids {
+		chip, label, hasLabel := strings.Cut(id, "/")
+		found := false
+		for _, s := range all {
+			if s.Chip == chip && (!hasLabel || s.Label == label) {
+				sensors = append(sensors, s)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("hwmon sensor %q not found", id)
+		}
+	}
+	return sensors, nil
+}
+
+func read(sensors []Sensor) (Readings, error) {
+	readings := make(Readings, len(sensors))
+	for i, s := range sensors {
+		var err error
+		if readings[i], err = s.Read(); err != nil {
+			return nil, err
+		}
+	}
+	return readings, nil
+}
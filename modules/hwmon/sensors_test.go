@@ -0,0 +1,199 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hwmon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/format"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSysfs(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	for path, data := range map[string]string{
+		"/sys/class/hwmon/hwmon0/name":        "k10temp\n",
+		"/sys/class/hwmon/hwmon0/temp1_label": "Tctl\n",
+		"/sys/class/hwmon/hwmon0/temp1_input": "63500\n",
+
+		"/sys/class/hwmon/hwmon2/name":             "nct6775\n",
+		"/sys/class/hwmon/hwmon2/fan1_input":       "1200\n",
+		"/sys/class/hwmon/hwmon2/fan1_min":         "300\n",
+		"/sys/class/hwmon/hwmon2/fan1_alarm":       "0\n",
+		"/sys/class/hwmon/hwmon2/fan2_label":       "CPU Fan\n",
+		"/sys/class/hwmon/hwmon2/fan2_input":       "900\n",
+		"/sys/class/hwmon/hwmon2/fan10_input":      "0\n",
+		"/sys/class/hwmon/hwmon2/in0_input":        "1104\n",
+		"/sys/class/hwmon/hwmon2/in0_min":          "800\n",
+		"/sys/class/hwmon/hwmon2/in0_max":          "1200\n",
+		"/sys/class/hwmon/hwmon2/in0_alarm":        "0\n",
+		"/sys/class/hwmon/hwmon2/intrusion0_alarm": "1\n",
+		"/sys/class/hwmon/hwmon2/pwm1":             "128\n",
+
+		"/sys/class/hwmon/hwmon10/name":               "amdgpu\n",
+		"/sys/class/hwmon/hwmon10/power1_label":       "PPT\n",
+		"/sys/class/hwmon/hwmon10/power1_average":     "35000000\n",
+		"/sys/class/hwmon/hwmon10/power1_cap":         "150000000\n",
+		"/sys/class/hwmon/hwmon10/curr1_input":        "2500\n",
+		"/sys/class/hwmon/hwmon10/temp1_label":        "edge\n",
+		"/sys/class/hwmon/hwmon10/temp1_input":        "56000\n",
+		"/sys/class/hwmon/hwmon10/temp1_crit":         "100000\n",
+		"/sys/class/hwmon/hwmon10/temp1_crit_alarm":   "0\n",
+		"/sys/class/hwmon/hwmon10/temp2_label":        "junction\n",
+		"/sys/class/hwmon/hwmon10/temp2_input":        "not a number\n",
+		"/sys/class/hwmon/hwmon10/subsystem/uevent":   "\n",
+		"/sys/class/hwmon/hwmon11/temp1_input":        "30000\n",
+		"/sys/class/hwmon/hwmon10/device/power_state": "D0\n",
+	} {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(data), 0644))
+	}
+}
+
+func ids(sensors []Sensor) []string {
+	var out []string
+	for _, s := range sensors {
+		out = append(out, s.ID())
+	}
+	return out
+}
+
+func TestDiscover(t *testing.T) {
+	setupSysfs(t)
+	sensors := Discover()
+	require.Equal(t, []string{
+		"k10temp/Tctl",
+		"nct6775/fan1", "nct6775/CPU Fan", "nct6775/fan10", "nct6775/in0",
+		"amdgpu/curr1", "amdgpu/PPT", "amdgpu/edge", "amdgpu/junction",
+	}, ids(sensors), "sensors in natural order, skipping chip without name")
+	require.Equal(t, Fan, sensors[2].Kind)
+	require.Equal(t, Voltage, sensors[4].Kind)
+	require.Equal(t, Current, sensors[5].Kind)
+	require.Equal(t, Power, sensors[6].Kind)
+	require.Equal(t, Temperature, sensors[7].Kind)
+}
+
+func TestRead(t *testing.T) {
+	setupSysfs(t)
+	r := Readings{}
+	for _, s := range Discover() {
+		rd, err := s.Read()
+		if s.Label == "junction" {
+			require.Error(t, err, "non-numeric value")
+			continue
+		}
+		require.NoError(t, err)
+		r = append(r, rd)
+	}
+
+	rd, ok := r.Find("k10temp", "Tctl")
+	require.True(t, ok)
+	require.InDelta(t, 63.5, rd.Value.(unit.Temperature).Celsius(), 1e-9)
+	require.Nil(t, rd.Min)
+	require.False(t, rd.Alarm)
+
+	rd, _ = r.Find("nct6775", "fan1")
+	require.InDelta(t, 1200, rd.RPM(), 1e-9)
+	require.InDelta(t, 5, rd.Min.(unit.Frequency).Hertz(), 1e-9)
+	require.Nil(t, rd.Max)
+
+	rd, _ = r.Find("nct6775", "in0")
+	require.InDelta(t, 1.104, rd.Value.(unit.Voltage).Volts(), 1e-9)
+	require.InDelta(t, 0.8, rd.Min.(unit.Voltage).Volts(), 1e-9)
+	require.InDelta(t, 1.2, rd.Max.(unit.Voltage).Volts(), 1e-9)
+
+	rd, _ = r.Find("amdgpu", "PPT")
+	require.InDelta(t, 35, rd.Value.(unit.Power).Watts(), 1e-9, "from average")
+	rd, _ = r.Find("amdgpu", "curr1")
+	require.InDelta(t, 2.5, rd.Value.(unit.ElectricCurrent).Amperes(), 1e-9)
+	rd, _ = r.Find("amdgpu", "edge")
+	require.InDelta(t, 100, rd.Crit.(unit.Temperature).Celsius(), 1e-9)
+
+	_, ok = r.Find("amdgpu", "nope")
+	require.False(t, ok)
+}
+
+func TestSensorsModule(t *testing.T) {
+	setupSysfs(t)
+	testBar.New(t)
+
+	def := Sensors("k10temp/Tctl", "nct6775/CPU Fan")
+	chip := Sensors("nct6775").Output(func(r Readings) bar.Output {
+		var out []string
+		for _, rd := range r {
+			v, _ := format.Unit(rd.Value)
+			out = append(out, rd.Label+"="+v.String())
+		}
+		return outputs.Text(strings.Join(out, " "))
+	})
+	testBar.Run(def, chip)
+	testBar.LatestOutput().AssertText([]string{
+		"Tctl: 63.5℃", "CPU Fan: 900 RPM",
+		"fan1=20.0Hz CPU Fan=15.0Hz fan10=   0Hz in0=1.10V",
+	}, "on start")
+
+	afero.WriteFile(fs, "/sys/class/hwmon/hwmon2/fan2_input", []byte("1800\n"), 0644)
+	afero.WriteFile(fs, "/sys/class/hwmon/hwmon0/temp1_input", []byte("90000\n"), 0644)
+	afero.WriteFile(fs, "/sys/class/hwmon/hwmon0/temp1_max_alarm", []byte("1\n"), 0644)
+	testBar.Tick()
+	out := testBar.LatestOutput()
+	out.AssertText([]string{
+		"Tctl: 90.0℃", "CPU Fan: 1800 RPM",
+		"fan1=20.0Hz CPU Fan=30.0Hz fan10=   0Hz in0=1.10V",
+	}, "on tick")
+	urgent, _ := out.At(0).Segment().IsUrgent()
+	require.True(t, urgent, "urgent when alarm is set")
+
+	beforeTick := timing.Now()
+	def.RefreshInterval(time.Minute)
+	chip.RefreshInterval(time.Minute)
+	testBar.Tick()
+	require.Equal(t, time.Minute, timing.Now().Sub(beforeTick), "RefreshInterval change")
+	testBar.LatestOutput().Expect("on tick")
+
+	fs.Remove("/sys/class/hwmon/hwmon2/fan2_input")
+	testBar.Tick()
+	testBar.LatestOutput().AssertError("when sensor is removed")
+}
+
+func TestSensorsErrors(t *testing.T) {
+	setupSysfs(t)
+	testBar.New(t)
+	testBar.Run(Sensors("k10temp/Tdie"), Sensors("coretemp"), Sensors("amdgpu/junction"))
+	errs := testBar.LatestOutput().AssertError()
+	require.Contains(t, errs[0], `"k10temp/Tdie" not found`)
+	require.Contains(t, errs[1], `"coretemp" not found`)
+
+	testBar.New(t)
+	testBar.Run(Sensors().Output(func(r Readings) bar.Output {
+		return outputs.Textf("%d", len(r))
+	}))
+	testBar.LatestOutput().AssertError("when any sensor cannot be read")
+
+	fs.Remove("/sys/class/hwmon/hwmon10/temp2_input")
+	testBar.New(t)
+	testBar.Run(Sensors().Output(func(r Readings) bar.Output {
+		return outputs.Textf("%d", len(r))
+	}))
+	testBar.LatestOutput().AssertText([]string{"8"}, "all sensors")
+}
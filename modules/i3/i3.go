@@ -0,0 +1,324 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i3 provides a module that shows the state of the i3 or sway window
+// manager: workspaces, the current binding mode, and the focused window. It
+// uses the i3 IPC protocol, which is also supported by sway.
+package i3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/click"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+)
+
+// Workspace represents a single workspace.
+type Workspace struct {
+	ID      int64  `json:"id"`
+	Num     int    `json:"num"`
+	Name    string `json:"name"`
+	Output  string `json:"output"`
+	Focused bool   `json:"focused"`
+	Visible bool   `json:"visible"`
+	Urgent  bool   `json:"urgent"`
+}
+
+// Window represents the focused window.
+type Window struct {
+	ID    int64
+	Title string
+	// Class is the X11 window class. Wayland windows on sway have an AppID
+	// instead.
+	Class string
+	AppID string
+}
+
+// Info represents the current state of the window manager.
+type Info struct {
+	Workspaces []Workspace
+	// Mode is the current binding mode, "default" unless a mode is active.
+	Mode string
+	// Window is the focused window, or the zero value if no window is
+	// focused (e.g. on an empty workspace).
+	Window Window
+
+	command func(string) error
+}
+
+// DefaultMode returns true if no binding mode is active.
+func (i Info) DefaultMode() bool {
+	return i.Mode == "" || i.Mode == "default"
+}
+
+// FocusedWorkspace returns the focused workspace, if any.
+func (i Info) FocusedWorkspace() (Workspace, bool) {
+	for _, w := range i.Workspaces {
+		if w.Focused {
+			return w, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// Command runs an i3 command, e.g. "workspace next".
+func (i Info) Command(cmd string) error {
+	if i.command == nil {
+		return errors.New("not connected")
+	}
+	return i.command(cmd)
+}
+
+// FocusWorkspace switches to the given workspace.
+func (i Info) FocusWorkspace(w Workspace) error {
+	return i.Command("workspace " + strconv.Quote(w.Name))
+}
+
+// Module represents an i3 bar module.
+type Module struct {
+	socket     string
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New constructs an i3 module that connects to the window manager using
+// $I3SOCK, or $SWAYSOCK if $I3SOCK is not set.
+func New() *Module {
+	return Socket("")
+}
+
+// Socket constructs an i3 module that connects to the given IPC socket.
+func Socket(path string) *Module {
+	m := &Module{socket: path}
+	if path != "" {
+		l.Label(m, path)
+	}
+	l.Register(m, "outputFunc")
+	m.Output(defaultOutput)
+	return m
+}
+
+// defaultOutput shows each workspace, clickable to switch to it, followed by
+// the binding mode if one is active.
+func defaultOutput(i Info) bar.Output {
+	out := outputs.Group()
+	for _, w := range i.Workspaces {
+		w := w
+		out.Append(outputs.Text(w.Name).
+			Urgent(w.Urgent).
+			OnClick(click.Left(func() { i.FocusWorkspace(w) })))
+	}
+	if !i.DefaultMode() {
+		out.Append(outputs.Text(i.Mode).Urgent(true))
+	}
+	return out
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+func (m *Module) socketPath() (string, error) {
+	for _, path := range []string{m.socket, os.Getenv("I3SOCK"), os.Getenv("SWAYSOCK")} {
+		if path != "" {
+			return path, nil
+		}
+	}
+	return "", errors.New("neither I3SOCK nor SWAYSOCK is set")
+}
+
+type event struct {
+	typ     uint32
+	payload []byte
+	err     error
+}
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	path, err := m.socketPath()
+	if s.Error(err) {
+		return
+	}
+	cmdConn, err := dial(path)
+	if s.Error(err) {
+		return
+	}
+	defer cmdConn.Close()
+	evtConn, err := dial(path)
+	if s.Error(err) {
+		return
+	}
+	defer evtConn.Close()
+	// Subscribe before fetching the initial state, to avoid missing any
+	// changes in between.
+	if s.Error(evtConn.subscribe("workspace", "mode", "window")) {
+		return
+	}
+	events := make(chan event)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			typ, payload, err := evtConn.read()
+			select {
+			case events <- event{typ, payload, err}:
+			case <-stop:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	info := Info{command: cmdConn.command}
+	if s.Error(fetchWorkspaces(cmdConn, &info)) {
+		return
+	}
+	var mode struct {
+		Name string `json:"name"`
+	}
+	if s.Error(cmdConn.request(msgGetBindingState, nil, &mode)) {
+		return
+	}
+	info.Mode = mode.Name
+
+	for {
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		case e := <-events:
+			if s.Error(e.err) {
+				return
+			}
+			l.Fine("%s: event %x: %s", l.ID(m), e.typ, e.payload)
+			if s.Error(handleEvent(cmdConn, &info, e)) {
+				return
+			}
+		}
+	}
+}
+
+func handleEvent(c *conn, info *Info, e event) error {
+	switch e.typ {
+	case eventWorkspace:
+		// The focused window may change along with the workspace, without a
+		// window event (e.g. when switching to an empty workspace).
+		return fetchWorkspaces(c, info)
+	case eventMode:
+		var mode struct {
+			Change string `json:"change"`
+		}
+		if err := json.Unmarshal(e.payload, &mode); err != nil {
+			return err
+		}
+		info.Mode = mode.Change
+	case eventWindow:
+		var win struct {
+			Change    string `json:"change"`
+			Container node   `json:"container"`
+		}
+		if err := json.Unmarshal(e.payload, &win); err != nil {
+			return err
+		}
+		switch win.Change {
+		case "focus":
+			info.Window = win.Container.window()
+		case "title":
+			if win.Container.Focused {
+				info.Window = win.Container.window()
+			}
+		case "close":
+			if win.Container.ID == info.Window.ID {
+				info.Window = Window{}
+			}
+		}
+	}
+	return nil
+}
+
+// fetchWorkspaces updates the workspaces and focused window.
+func fetchWorkspaces(c *conn, info *Info) error {
+	var workspaces []Workspace
+	if err := c.request(msgGetWorkspaces, nil, &workspaces); err != nil {
+		return err
+	}
+	var tree node
+	if err := c.request(msgGetTree, nil, &tree); err != nil {
+		return err
+	}
+	info.Workspaces = workspaces
+	info.Window = Window{}
+	if n := tree.focused(); n != nil && n.Type != "workspace" {
+		info.Window = n.window()
+	}
+	return nil
+}
+
+// node is a container in the i3 layout tree.
+type node struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Focused          bool   `json:"focused"`
+	AppID            string `json:"app_id"`
+	WindowProperties struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+	Nodes         []node `json:"nodes"`
+	FloatingNodes []node `json:"floating_nodes"`
+}
+
+func (n *node) focused() *node {
+	if n.Focused {
+		return n
+	}
+	for _, children := range [][]node{n.Nodes, n.FloatingNodes} {
+		for i := range children {
+			if f := children[i].focused(); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func (n node) window() Window {
+	return Window{
+		ID:    n.ID,
+		Title: n.Name,
+		Class: n.WindowProperties.Class,
+		AppID: n.AppID,
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i3
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/testing/i3ipc"
+	"github.com/stretchr/testify/require"
+)
+
+func workspaces(focused string, urgent ...string) []Workspace {
+	var out []Workspace
+	for i, name := range []string{"1", "2", "web"} {
+		w := Workspace{ID: int64(i + 10), Num: i + 1, Name: name, Output: "eDP-1"}
+		w.Focused = name == focused
+		w.Visible = w.Focused
+		for _, u := range urgent {
+			w.Urgent = w.Urgent || u == name
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func tree(focused int64) map[string]interface{} {
+	win := func(id int64, title, class string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": title, "type": "con", "focused": id == focused,
+			"window_properties": map[string]string{"class": class},
+		}
+	}
+	return map[string]interface{}{
+		"id": 1, "type": "root", "nodes": []interface{}{
+			map[string]interface{}{
+				"id": 10, "name": "1", "type": "workspace", "focused": focused == 10,
+				"nodes": []interface{}{win(100, "Terminal", "xterm")},
+				"floating_nodes": []interface{}{
+					map[string]interface{}{
+						"id": 101, "type": "floating_con", "nodes": []interface{}{
+							map[string]interface{}{
+								"id": 102, "name": "Editor", "type": "con",
+								"focused": focused == 102, "app_id": "editor",
+							},
+						},
+					},
+				},
+			},
+			map[string]interface{}{"id": 11, "name": "2", "type": "workspace", "focused": focused == 11},
+		},
+	}
+}
+
+func textOutput(i Info) bar.Output {
+	out := outputs.Group()
+	for _, w := range i.Workspaces {
+		txt := w.Name
+		if w.Focused {
+			txt = "[" + txt + "]"
+		}
+		if w.Urgent {
+			txt += "!"
+		}
+		out.Append(outputs.Text(txt))
+	}
+	out.Append(outputs.Text(i.Mode))
+	out.Append(outputs.Textf("%s (%s%s)", i.Window.Title, i.Window.Class, i.Window.AppID))
+	return out
+}
+
+func TestModule(t *testing.T) {
+	srv := i3ipc.New()
+	defer srv.Close()
+	srv.SetReply(i3ipc.GetWorkspaces, workspaces("1"))
+	srv.SetReply(i3ipc.GetTree, tree(100))
+
+	testBar.New(t)
+	m := Socket(srv.Path()).Output(textOutput)
+	testBar.Run(m)
+	testBar.LatestOutput().AssertText(
+		[]string{"[1]", "2", "web", "default", "Terminal (xterm)"})
+
+	srv.Emit("mode", map[string]interface{}{"change": "resize", "pango_markup": false})
+	testBar.NextOutput("on mode change").AssertText(
+		[]string{"[1]", "2", "web", "resize", "Terminal (xterm)"})
+
+	srv.Emit("window", map[string]interface{}{
+		"change": "focus",
+		"container": map[string]interface{}{
+			"id": 102, "name": "Editor", "focused": true, "app_id": "editor",
+		},
+	})
+	testBar.NextOutput("on window focus").AssertText(
+		[]string{"[1]", "2", "web", "resize", "Editor (editor)"})
+
+	srv.Emit("window", map[string]interface{}{
+		"change": "title",
+		"container": map[string]interface{}{
+			"id": 100, "name": "vim", "focused": false,
+		},
+	})
+	testBar.NextOutput("on title change of unfocused window").AssertText(
+		[]string{"[1]", "2", "web", "resize", "Editor (editor)"})
+
+	srv.Emit("window", map[string]interface{}{
+		"change": "title",
+		"container": map[string]interface{}{
+			"id": 102, "name": "Editor - file.go", "focused": true, "app_id": "editor",
+		},
+	})
+	testBar.NextOutput("on title change").AssertText(
+		[]string{"[1]", "2", "web", "resize", "Editor - file.go (editor)"})
+
+	srv.Emit("window", map[string]interface{}{
+		"change":    "close",
+		"container": map[string]interface{}{"id": 102},
+	})
+	testBar.NextOutput("on window close").AssertText(
+		[]string{"[1]", "2", "web", "resize", " ()"})
+
+	srv.Emit("mode", map[string]interface{}{"change": "default"})
+	testBar.NextOutput("on mode change").AssertText(
+		[]string{"[1]", "2", "web", "default", " ()"})
+
+	srv.SetReply(i3ipc.GetWorkspaces, workspaces("2", "web"))
+	srv.SetReply(i3ipc.GetTree, tree(11))
+	srv.Emit("workspace", map[string]interface{}{"change": "focus"})
+	testBar.NextOutput("on workspace change").AssertText(
+		[]string{"1", "[2]", "web!", "default", " ()"})
+
+	srv.SetReply(i3ipc.GetWorkspaces, workspaces("1"))
+	srv.SetReply(i3ipc.GetTree, tree(102))
+	srv.Emit("workspace", map[string]interface{}{"change": "focus"})
+	testBar.NextOutput("on workspace change").AssertText(
+		[]string{"[1]", "2", "web", "default", "Editor (editor)"},
+		"focused window is updated with workspace")
+
+	srv.Emit("binding", map[string]interface{}{"change": "run"})
+	testBar.AssertNoOutput("on unsubscribed event")
+
+	srv.Disconnect()
+	testBar.NextOutput("on disconnect").AssertError()
+}
+
+func TestCommands(t *testing.T) {
+	srv := i3ipc.New()
+	defer srv.Close()
+	srv.SetReply(i3ipc.GetWorkspaces, workspaces("1", "web"))
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	m := Socket(srv.Path())
+	testBar.Run(m)
+
+	out := testBar.LatestOutput()
+	out.AssertText([]string{"1", "2", "web"})
+	urgent, _ := out.At(2).Segment().IsUrgent()
+	require.True(t, urgent)
+
+	out.At(1).LeftClick()
+	select {
+	case cmd := <-srv.Commands():
+		require.Equal(t, `workspace "2"`, cmd)
+	case <-time.After(time.Second):
+		require.Fail(t, "expected a command on click")
+	}
+
+	srv.Emit("mode", map[string]interface{}{"change": "resize"})
+	testBar.NextOutput("on mode change").AssertText([]string{"1", "2", "web", "resize"})
+
+	m.Output(func(i Info) bar.Output {
+		infos <- i
+		return nil
+	})
+	testBar.NextOutput("on output func change").AssertEmpty()
+	info := <-infos
+	require.False(t, info.DefaultMode())
+	ws, ok := info.FocusedWorkspace()
+	require.True(t, ok)
+	require.Equal(t, "1", ws.Name)
+
+	require.NoError(t, info.Command("nop"))
+	require.Equal(t, "nop", <-srv.Commands())
+
+	srv.SetReply(i3ipc.RunCommand, []map[string]interface{}{
+		{"success": true},
+		{"success": false, "error": "Unknown command"},
+	})
+	require.EqualError(t, info.Command("nop; foo"), "Unknown command")
+	<-srv.Commands()
+
+	require.Error(t, Info{}.Command("nop"), "when not connected")
+	_, ok = Info{}.FocusedWorkspace()
+	require.False(t, ok)
+}
+
+func TestSocketFromEnv(t *testing.T) {
+	srv := i3ipc.New()
+	defer srv.Close()
+	srv.SetReply(i3ipc.GetWorkspaces, workspaces("web"))
+
+	for _, env := range []string{"I3SOCK", "SWAYSOCK"} {
+		t.Setenv("I3SOCK", "")
+		t.Setenv("SWAYSOCK", "")
+		t.Setenv(env, srv.Path())
+		testBar.New(t)
+		testBar.Run(New())
+		testBar.LatestOutput().AssertText([]string{"1", "2", "web"}, env)
+	}
+
+	t.Setenv("I3SOCK", "")
+	t.Setenv("SWAYSOCK", "")
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.LatestOutput().AssertError("without socket")
+
+	testBar.New(t)
+	testBar.Run(Socket(fmt.Sprintf("%s.missing", srv.Path())))
+	testBar.LatestOutput().AssertError("with missing socket")
+}
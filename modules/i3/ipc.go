@@ -0,0 +1,140 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i3
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// Message and event types used by the module.
+const (
+	msgRunCommand      uint32 = 0
+	msgGetWorkspaces   uint32 = 1
+	msgSubscribe       uint32 = 2
+	msgGetTree         uint32 = 4
+	msgGetBindingState uint32 = 12
+
+	eventWorkspace uint32 = 0x80000000
+	eventMode      uint32 = 0x80000002
+	eventWindow    uint32 = 0x80000003
+)
+
+const magic = "i3-ipc"
+
+// byteOrder is used for message headers. i3 uses the native byte order, which
+// is little-endian on all platforms supported by barista.
+var byteOrder = binary.LittleEndian
+
+// conn is a connection to the i3 IPC socket.
+type conn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+func dial(path string) (*conn, error) {
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c}, nil
+}
+
+func (c *conn) send(typ uint32, payload []byte) error {
+	msg := make([]byte, len(magic)+8, len(magic)+8+len(payload))
+	copy(msg, magic)
+	byteOrder.PutUint32(msg[len(magic):], uint32(len(payload)))
+	byteOrder.PutUint32(msg[len(magic)+4:], typ)
+	_, err := c.Write(append(msg, payload...))
+	return err
+}
+
+func (c *conn) read() (uint32, []byte, error) {
+	header := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(c, header); err != nil {
+		return 0, nil, err
+	}
+	if string(header[:len(magic)]) != magic {
+		return 0, nil, errors.New("invalid i3 IPC message")
+	}
+	size := byteOrder.Uint32(header[len(magic):])
+	typ := byteOrder.Uint32(header[len(magic)+4:])
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c, payload); err != nil {
+		return 0, nil, err
+	}
+	return typ, payload, nil
+}
+
+// request sends a message and decodes the reply into out. It must not be
+// used on a connection that has subscribed to events.
+func (c *conn) request(typ uint32, payload []byte, out interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.send(typ, payload); err != nil {
+		return err
+	}
+	replyType, reply, err := c.read()
+	if err != nil {
+		return err
+	}
+	if replyType != typ {
+		return errors.New("unexpected i3 IPC reply")
+	}
+	return json.Unmarshal(reply, out)
+}
+
+type result struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+func (r result) err() error {
+	if r.Success {
+		return nil
+	}
+	if r.Error == "" {
+		return errors.New("i3 IPC request failed")
+	}
+	return errors.New(r.Error)
+}
+
+// command runs one or more i3 commands, returning the first error.
+func (c *conn) command(cmd string) error {
+	var results []result
+	if err := c.request(msgRunCommand, []byte(cmd), &results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := r.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribe subscribes to the given events. Events are then read using read.
+func (c *conn) subscribe(events ...string) error {
+	payload, _ := json.Marshal(events)
+	var r result
+	if err := c.request(msgSubscribe, payload, &r); err != nil {
+		return err
+	}
+	return r.err()
+}
@@ -15,6 +15,7 @@
 package temperature
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"time"
@@ -69,6 +70,11 @@ var fs = afero.NewOsFs()
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	temp, err := getTemperature(m.thermalFile)
 	outputFunc := m.outputFunc.Get().(func(unit.Temperature) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
@@ -79,6 +85,8 @@ func (m *Module) Stream(s bar.Sink) {
 		}
 		s.Output(outputFunc(temp))
 		select {
+		case <-ctx.Done():
+			return
 		case <-m.scheduler.C:
 			temp, err = getTemperature(m.thermalFile)
 		case <-nextOutputFunc:
@@ -0,0 +1,181 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lockkeys provides an i3bar module that shows the state of the
+// Caps Lock, Num Lock, and Scroll Lock keys, using the keyboard LEDs exposed
+// by the kernel in /sys/class/leds. It does not depend on X11, so it works
+// on Wayland and on the console.
+//
+// The LEDs are polled, every second by default. The kernel does not notify
+// file watchers when it toggles an LED in response to a key press, so the
+// brightness files cannot be watched. Keyboards that are plugged in or
+// removed are picked up on the next poll.
+package lockkeys
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+)
+
+// Key identifies a lock key.
+type Key string
+
+// Lock keys that have an LED on most keyboards.
+const (
+	CapsLock   Key = "capslock"
+	NumLock    Key = "numlock"
+	ScrollLock Key = "scrolllock"
+)
+
+// LED represents the state of a single lock key LED.
+type LED struct {
+	// Device is the input device the LED belongs to, e.g. "input3".
+	Device string
+	Key    Key
+	On     bool
+}
+
+// Info represents the state of all lock key LEDs, across all keyboards.
+type Info []LED
+
+// On returns true if the LED for the given key is on for any keyboard.
+func (i Info) On(k Key) bool {
+	for _, led := range i {
+		if led.Key == k && led.On {
+			return true
+		}
+	}
+	return false
+}
+
+// Has returns true if any keyboard has an LED for the given key.
+func (i Info) Has(k Key) bool {
+	for _, led := range i {
+		if led.Key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Module represents a lock keys bar module.
+type Module struct {
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New creates a lock keys module.
+func New() *Module {
+	m := &Module{scheduler: timing.NewScheduler()}
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(time.Second)
+	m.Output(func(i Info) bar.Output {
+		out := outputs.Group()
+		for _, k := range []Key{CapsLock, NumLock, ScrollLock} {
+			if i.On(k) {
+				out.Append(outputs.Text(strings.ToUpper(strings.TrimSuffix(string(k), "lock"))))
+			}
+		}
+		return out
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency for the LED state.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+const ledsDir = "/sys/class/leds"
+
+var fs = afero.NewOsFs()
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	info, err := readLEDs()
+	for {
+		if s.Error(err) {
+			return
+		}
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			info, err = readLEDs()
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+	}
+}
+
+// readLEDs reads the state of all lock key LEDs. Keyboards being plugged in
+// or removed are picked up on the next read.
+func readLEDs() (Info, error) {
+	dirs, err := afero.Glob(fs, filepath.Join(ledsDir, "input*::*"))
+	if err != nil {
+		return nil, err
+	}
+	var info Info
+	for _, dir := range dirs {
+		device, key, _ := strings.Cut(filepath.Base(dir), "::")
+		switch Key(key) {
+		case CapsLock, NumLock, ScrollLock:
+		default:
+			continue
+		}
+		data, err := afero.ReadFile(fs, filepath.Join(dir, "brightness"))
+		if os.IsNotExist(err) {
+			// The keyboard was unplugged while reading, or is still being
+			// set up.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		info = append(info, LED{
+			Device: device,
+			Key:    Key(key),
+			On:     strings.TrimSpace(string(data)) != "0",
+		})
+	}
+	return info, nil
+}
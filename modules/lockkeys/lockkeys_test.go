@@ -0,0 +1,109 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockkeys
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func setLED(name string, on bool) {
+	brightness := "0\n"
+	if on {
+		brightness = "1\n"
+	}
+	afero.WriteFile(fs, filepath.Join(ledsDir, name, "brightness"), []byte(brightness), 0644)
+}
+
+func TestLockKeys(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	setLED("input3::capslock", false)
+	setLED("input3::numlock", true)
+	setLED("input3::scrolllock", false)
+	setLED("input3::kana", true)
+	setLED("phy0-led", true)
+
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput("on start").AssertText([]string{"NUM"})
+
+	setLED("input3::capslock", true)
+	testBar.AssertNoOutput("until refresh")
+	testBar.Tick()
+	testBar.NextOutput("on capslock").AssertText([]string{"CAPS", "NUM"})
+
+	setLED("input3::numlock", false)
+	testBar.Tick()
+	testBar.NextOutput("on numlock").AssertText([]string{"CAPS"})
+
+	setLED("input3::capslock", false)
+	testBar.Tick()
+	testBar.NextOutput("on capslock").AssertEmpty()
+
+	testBar.Tick()
+	testBar.NextOutput("on refresh").AssertEmpty()
+}
+
+func TestHotplug(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	testBar.New(t)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		return outputs.Textf("%v", i)
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"[]"})
+
+	setLED("input7::capslock", true)
+	testBar.Tick()
+	testBar.NextOutput("on plug").AssertText([]string{"[{input7 capslock true}]"})
+
+	setLED("input9::capslock", false)
+	testBar.Tick()
+	testBar.NextOutput("on second keyboard").AssertText(
+		[]string{"[{input7 capslock true} {input9 capslock false}]"})
+
+	setLED("input9::capslock", true)
+	testBar.Tick()
+	testBar.NextOutput("on capslock").AssertText(
+		[]string{"[{input7 capslock true} {input9 capslock true}]"})
+
+	fs.Mkdir(filepath.Join(ledsDir, "input8::numlock"), 0755)
+	testBar.Tick()
+	testBar.NextOutput("led without brightness").AssertText(
+		[]string{"[{input7 capslock true} {input9 capslock true}]"})
+
+	require.NoError(t, fs.RemoveAll(filepath.Join(ledsDir, "input7::capslock")))
+	testBar.Tick()
+	testBar.NextOutput("on unplug").AssertText([]string{"[{input9 capslock true}]"})
+}
+
+func TestInfo(t *testing.T) {
+	i := Info{
+		{Device: "input3", Key: CapsLock, On: false},
+		{Device: "input3", Key: NumLock, On: true},
+		{Device: "input9", Key: CapsLock, On: true},
+	}
+	require.True(t, i.On(CapsLock))
+	require.True(t, i.On(NumLock))
+	require.False(t, i.On(ScrollLock))
+	require.True(t, i.Has(NumLock))
+	require.False(t, i.Has(ScrollLock))
+}
@@ -17,6 +17,7 @@ package meminfo
 
 import (
 	"bufio"
+	"context"
 	"strconv"
 	"strings"
 	"sync"
@@ -116,6 +117,11 @@ func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
 
 // Stream subscribes to meminfo and updates the module's output accordingly.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	i, err := currentInfo.Get()
 	nextInfo, done := currentInfo.Subscribe()
 	defer done()
@@ -129,6 +135,8 @@ func (m *Module) Stream(s bar.Sink) {
 			s.Output(outputFunc(info))
 		}
 		select {
+		case <-ctx.Done():
+			return
 		case <-nextOutputFunc:
 			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
 		case <-nextInfo:
@@ -28,6 +28,7 @@ which don't allow for colours or borders. You can add those using reformat:
 package reformat
 
 import (
+	"context"
 	"sync/atomic"
 
 	"github.com/soumya92/barista/bar"
@@ -116,6 +117,12 @@ func (m *Module) Stream(s bar.Sink) {
 	m.wrapped.Stream(wrappedSink(m, s))
 }
 
+// StreamContext is like Stream, but stops the wrapped module when the
+// context is cancelled.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	m.wrapped.StreamContext(ctx, wrappedSink(m, s))
+}
+
 func wrappedSink(m *Module, s bar.Sink) bar.Sink {
 	return sink.Func(func(o bar.Segments) {
 		formatter := m.formatter.Load().(FormatFunc)
@@ -0,0 +1,217 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netinfo
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/base/watchers/file"
+	l "github.com/soumya92/barista/logging"
+)
+
+// To allow tests to use a test bus and resolv.conf file.
+var (
+	busType        = dbus.System
+	resolvConfPath = "/etc/resolv.conf"
+)
+
+// To allow tests to mock out interface lookups. systemd-resolved identifies
+// links by index, but the netlink watcher only provides names.
+var interfaceName = func(index int) string {
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return ""
+	}
+	return iface.Name
+}
+
+// dnsServers holds the global and per-link DNS servers.
+type dnsServers struct {
+	global []net.IP
+	links  map[string][]net.IP
+}
+
+// forLink returns the DNS servers for the named link, falling back to the
+// global servers if the link does not have any of its own.
+func (d dnsServers) forLink(name string) []net.IP {
+	if servers := d.links[name]; len(servers) > 0 {
+		return servers
+	}
+	return d.global
+}
+
+// resolver provides the DNS servers from systemd-resolved if it is running,
+// and from resolv.conf otherwise. DNS servers are global, so a single resolver
+// is shared by all modules.
+type resolver struct {
+	path       string
+	resolved   *dbus.PropertiesWatcher
+	resolvConf *file.Watcher
+	servers    value.Value // of dnsServers
+	updateMu   sync.Mutex
+	stop       chan struct{}
+	refs       int // guarded by resolversMu
+}
+
+var (
+	resolversMu sync.Mutex
+	// Resolvers are keyed by the resolv.conf path, so that tests using a
+	// different path do not share a resolver.
+	resolvers = map[string]*resolver{}
+)
+
+// watchResolver returns the shared resolver, starting it if needed. Each call
+// must be paired with a call to Unsubscribe.
+func watchResolver() *resolver {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	path := resolvConfPath
+	if r, ok := resolvers[path]; ok {
+		r.refs++
+		return r
+	}
+	r := &resolver{
+		path: path,
+		resolved: dbus.WatchProperties(busType,
+			"org.freedesktop.resolve1",
+			"/org/freedesktop/resolve1",
+			"org.freedesktop.resolve1.Manager",
+		).Add("DNS").Fetch("DNS"),
+		resolvConf: file.Watch(path),
+		stop:       make(chan struct{}),
+		refs:       1,
+	}
+	l.Register(r, "resolved", "resolvConf", "servers")
+	r.servers.Set(r.read())
+	go r.watch()
+	resolvers[path] = r
+	return r
+}
+
+func (r *resolver) watch() {
+	for {
+		select {
+		case <-r.stop:
+			r.resolved.Unsubscribe()
+			r.resolvConf.Unsubscribe()
+			return
+		case <-r.resolved.Updates:
+		case <-r.resolvConf.Updates:
+		case err := <-r.resolvConf.Errors:
+			l.Log("%s: not watching %s: %v", l.ID(r), r.path, err)
+			continue
+		}
+		r.update()
+	}
+}
+
+// update reads the DNS servers, and notifies modules only if they actually
+// changed, since updates on link changes usually do not change anything.
+func (r *resolver) update() {
+	r.updateMu.Lock()
+	defer r.updateMu.Unlock()
+	if dns := r.read(); !reflect.DeepEqual(dns, r.get()) {
+		r.servers.Set(dns)
+	}
+}
+
+// get returns the current DNS servers.
+func (r *resolver) get() dnsServers {
+	return r.servers.Get().(dnsServers)
+}
+
+// linksChanged recomputes the DNS servers after a link is added or renamed,
+// since systemd-resolved identifies links by index rather than name.
+func (r *resolver) linksChanged() {
+	r.update()
+}
+
+func (r *resolver) read() dnsServers {
+	if dns, ok := r.resolved.Get()["DNS"]; ok {
+		return resolvedServers(dns)
+	}
+	return resolvConfServers(r.path)
+}
+
+// Unsubscribe releases the resolver, stopping it when it is no longer used by
+// any module.
+func (r *resolver) Unsubscribe() {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if r.refs--; r.refs > 0 {
+		return
+	}
+	delete(resolvers, r.path)
+	close(r.stop)
+}
+
+// resolvedServers converts the systemd-resolved DNS property, an array of
+// (ifindex, address family, address) structs, into dnsServers. Servers with
+// an ifindex of 0 are global.
+func resolvedServers(dns interface{}) dnsServers {
+	d := dnsServers{links: map[string][]net.IP{}}
+	entries, _ := dns.([][]interface{})
+	for _, e := range entries {
+		if len(e) < 3 {
+			continue
+		}
+		index, _ := e[0].(int32)
+		addr, _ := e[2].([]byte)
+		if len(addr) != net.IPv4len && len(addr) != net.IPv6len {
+			continue
+		}
+		ip := net.IP(addr)
+		if index == 0 {
+			d.global = append(d.global, ip)
+			continue
+		}
+		if name := interfaceName(int(index)); name != "" {
+			d.links[name] = append(d.links[name], ip)
+		}
+	}
+	return d
+}
+
+// resolvConfServers reads the nameservers from a resolv.conf file. All
+// servers are treated as global.
+func resolvConfServers(path string) dnsServers {
+	var d dnsServers
+	f, err := os.Open(path)
+	if err != nil {
+		l.Fine("Failed to read %s: %v", path, err)
+		return d
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		// Link-local IPv6 servers may include a zone, e.g. fe80::1%eth0.
+		addr := strings.SplitN(fields[1], "%", 2)[0]
+		if ip := net.ParseIP(addr); ip != nil {
+			d.global = append(d.global, ip)
+		}
+	}
+	return d
+}
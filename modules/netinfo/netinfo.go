@@ -12,10 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package netinfo provides an i3bar module for network information.
+// Package netinfo provides an i3bar module for network information, including
+// default routes and DNS servers.
 package netinfo
 
 import (
+	"context"
+	"net"
+
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/value"
 	"github.com/soumya92/barista/base/watchers/netlink"
@@ -26,6 +30,24 @@ import (
 // State represents the network state.
 type State struct {
 	netlink.Link
+	// DNS is the list of DNS servers used by the link, from systemd-resolved
+	// if it is running, or from /etc/resolv.conf otherwise. If there are no
+	// link-specific servers, it contains the global DNS servers.
+	DNS []net.IP
+}
+
+// Gateway returns the gateway of the link's preferred default route, or nil if
+// there is no default route through the link or it does not use a gateway.
+func (s State) Gateway() net.IP {
+	if len(s.DefaultRoutes) == 0 {
+		return nil
+	}
+	return s.DefaultRoutes[0].Gateway
+}
+
+// HasDefaultRoute returns true if there is a default route through the link.
+func (s State) HasDefaultRoute() bool {
+	return len(s.DefaultRoutes) > 0
 }
 
 // Connecting returns true if a connection is in progress.
@@ -81,6 +103,14 @@ func New() *Module {
 	return m
 }
 
+// DefaultRoute constructs a netinfo module that tracks the interface carrying
+// the preferred default route, i.e. the interface most traffic goes through.
+func DefaultRoute() *Module {
+	m := newWithSubscriber(netlink.DefaultRoute)
+	l.Label(m, "default")
+	return m
+}
+
 // Interface constructs an instance of the netinfo module
 // restricted to the specified interface.
 func Interface(iface string) *Module {
@@ -109,6 +139,11 @@ func (m *Module) Output(outputFunc func(State) bar.Output) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	outputFunc := m.outputFunc.Get().(func(State) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
 	defer done()
@@ -116,12 +151,28 @@ func (m *Module) Stream(s bar.Sink) {
 	linkSub := m.subscriber()
 	defer linkSub.Unsubscribe()
 
-	state := State{linkSub.Get()}
+	res := watchResolver()
+	defer res.Unsubscribe()
+	nextDNS, doneDNS := res.servers.Subscribe()
+	defer doneDNS()
+
+	link := linkSub.Get()
+	dns := res.get()
 	for {
-		s.Output(outputFunc(state))
+		s.Output(outputFunc(State{Link: link, DNS: dns.forLink(link.Name)}))
 		select {
+		case <-ctx.Done():
+			return
 		case <-linkSub.C:
-			state = State{linkSub.Get()}
+			prev := link
+			link = linkSub.Get()
+			// Links appearing or being renamed can change DNS servers.
+			if link.Name != prev.Name ||
+				(link.State == netlink.NotPresent) != (prev.State == netlink.NotPresent) {
+				res.linksChanged()
+			}
+		case <-nextDNS:
+			dns = res.get()
 		case <-nextOutputFunc:
 			outputFunc = m.outputFunc.Get().(func(State) bar.Output)
 		}
@@ -15,15 +15,32 @@
 package netinfo
 
 import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
 	"github.com/soumya92/barista/base/watchers/netlink"
 	"github.com/soumya92/barista/outputs"
 	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/stretchr/testify/require"
 )
 
+func init() {
+	busType = dbus.Test
+	resolvConfPath = "/nonexistent/resolv.conf"
+	interfaceName = func(index int) string {
+		return map[int]string{2: "eth0", 3: "wg0"}[index]
+	}
+}
+
 func TestNetinfo(t *testing.T) {
+	dbus.SetupTestBus()
 	nlt := netlink.TestMode()
 	link0 := nlt.AddLink(netlink.Link{Name: "lo0", State: netlink.Up})
 	link1 := nlt.AddLink(netlink.Link{Name: "eth0", State: netlink.Down})
@@ -75,3 +92,105 @@ func TestNetinfo(t *testing.T) {
 	})
 	testBar.NextOutput().AssertText([]string{"6", "W:down", "E:eth1", "eth1"})
 }
+
+func writeResolvConf(t *testing.T, lines ...string) {
+	t.Helper()
+	tmp := resolvConfPath + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(strings.Join(lines, "\n")), 0644))
+	require.NoError(t, os.Rename(tmp, resolvConfPath))
+}
+
+func TestRoutesAndDNS(t *testing.T) {
+	bus := dbus.SetupTestBus()
+	nlt := netlink.TestMode()
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	writeResolvConf(t,
+		"# Generated by NetworkManager",
+		"search example.com",
+		"nameserver 192.0.2.53",
+		"nameserver fe80::1%eth0",
+		"nameserver not-an-ip",
+	)
+
+	eth0 := nlt.AddLink(netlink.Link{Name: "eth0", State: netlink.Up})
+	wg0 := nlt.AddLink(netlink.Link{Name: "wg0", State: netlink.Up})
+	nlt.AddRoute(eth0, netlink.Route{Gateway: net.IPv4(192, 168, 0, 1), Metric: 100})
+
+	testBar.New(t)
+	n := DefaultRoute().Output(func(s State) bar.Output {
+		if !s.HasDefaultRoute() {
+			return outputs.Text("offline")
+		}
+		out := "via " + s.Name
+		if gw := s.Gateway(); gw != nil {
+			out += " (" + gw.String() + ")"
+		}
+		for _, ip := range s.DNS {
+			out += ", DNS " + ip.String()
+		}
+		return outputs.Text(out)
+	})
+	testBar.Run(n)
+	testBar.NextOutput("on start").AssertText([]string{
+		"via eth0 (192.168.0.1), DNS 192.0.2.53, DNS fe80::1"})
+
+	nlt.AddRoute(wg0, netlink.Route{Table: 51820})
+	testBar.NextOutput("on better route").AssertText([]string{
+		"via wg0, DNS 192.0.2.53, DNS fe80::1"})
+
+	writeResolvConf(t, "nameserver 192.0.2.54")
+	// Replacing the file can notify more than once.
+	testBar.Drain(200*time.Millisecond, "on resolv.conf change").AssertText([]string{
+		"via wg0, DNS 192.0.2.54"})
+
+	svc := bus.RegisterService()
+	obj := svc.Object("/org/freedesktop/resolve1", "org.freedesktop.resolve1.Manager")
+	obj.SetPropertyForTest("DNS", [][]interface{}{
+		{int32(0), int32(2), []byte{1, 1, 1, 1}},
+		{int32(2), int32(2), []byte{192, 168, 0, 1}},
+	}, dbus.SignalTypeNone)
+	svc.AddName("org.freedesktop.resolve1")
+	testBar.NextOutput("on resolved start").AssertText([]string{
+		"via wg0, DNS 1.1.1.1"}, "global servers without link servers")
+
+	obj.SetPropertyForTest("DNS", [][]interface{}{
+		{int32(0), int32(2), []byte{1, 1, 1, 1}},
+		{int32(2), int32(2), []byte{192, 168, 0, 1}},
+		{int32(3), int32(2), []byte{10, 0, 0, 1}},
+		{int32(4), int32(2), []byte{10, 0, 0, 2}},
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on resolved change").AssertText([]string{
+		"via wg0, DNS 10.0.0.1"}, "link-specific servers")
+
+	nlt.RemoveRoute(wg0, netlink.Route{Table: 51820})
+	testBar.NextOutput("on route removed").AssertText([]string{
+		"via eth0 (192.168.0.1), DNS 192.168.0.1"})
+
+	svc.Unregister()
+	testBar.NextOutput("on resolved exit").AssertText([]string{
+		"via eth0 (192.168.0.1), DNS 192.0.2.54"})
+
+	nlt.RemoveRoute(eth0, netlink.Route{Gateway: net.IPv4(192, 168, 0, 1), Metric: 100})
+	testBar.NextOutput("on last route removed").AssertText([]string{"offline"})
+}
+
+func TestSharedResolver(t *testing.T) {
+	dbus.SetupTestBus()
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	writeResolvConf(t, "nameserver 192.0.2.53")
+
+	r1 := watchResolver()
+	r2 := watchResolver()
+	require.Same(t, r1, r2, "resolver is shared")
+	require.Equal(t, []net.IP{net.ParseIP("192.0.2.53")}, r1.get().forLink("eth0"))
+
+	r1.Unsubscribe()
+	resolversMu.Lock()
+	require.Contains(t, resolvers, resolvConfPath, "still used by r2")
+	resolversMu.Unlock()
+
+	r2.Unsubscribe()
+	resolversMu.Lock()
+	require.NotContains(t, resolvers, resolvConfPath, "stopped when unused")
+	resolversMu.Unlock()
+}
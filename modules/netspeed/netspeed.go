@@ -16,6 +16,7 @@
 package netspeed
 
 import (
+	"context"
 	"time"
 
 	"github.com/soumya92/barista/bar"
@@ -92,6 +93,11 @@ var linkByName = netlink.LinkByName
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	lastRead := timing.Now()
 	lastRx, lastTx, _, err := linkRxTxState(m.iface)
 	if s.Error(err) {
@@ -108,6 +114,8 @@ func (m *Module) Stream(s bar.Sink) {
 			s.Output(outputFunc(speeds))
 		}
 		select {
+		case <-ctx.Done():
+			return
 		case <-nextOutputFunc:
 			outputFunc = m.outputFunc.Get().(func(Speeds) bar.Output)
 		case <-m.scheduler.C:
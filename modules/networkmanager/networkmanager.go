@@ -0,0 +1,412 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package networkmanager provides an i3bar module that shows the state of
+// NetworkManager: overall connectivity, active connections, and whether the
+// primary connection is metered. It also supports activating and deactivating
+// connection profiles.
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/notifier"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+)
+
+// Connectivity represents the connectivity state, as determined by
+// NetworkManager's connectivity checks.
+type Connectivity uint32
+
+const (
+	// ConnectivityUnknown is used if connectivity checks are disabled, or
+	// have not run yet.
+	ConnectivityUnknown Connectivity = iota
+	// ConnectivityNone represents a host that is not connected to any network.
+	ConnectivityNone
+	// ConnectivityPortal represents a host behind a captive portal.
+	ConnectivityPortal
+	// ConnectivityLimited represents a host that is connected to a network,
+	// but cannot reach the internet.
+	ConnectivityLimited
+	// ConnectivityFull represents a host with full access to the internet.
+	ConnectivityFull
+)
+
+// State represents the state of an active connection.
+type State uint32
+
+const (
+	// StateUnknown is used if the state of the connection is not known.
+	StateUnknown State = iota
+	// Activating represents a connection that is being established.
+	Activating
+	// Activated represents a connection that is fully established.
+	Activated
+	// Deactivating represents a connection that is being torn down.
+	Deactivating
+	// Deactivated represents a connection that is no longer active.
+	Deactivated
+)
+
+// Connection represents an active connection.
+type Connection struct {
+	// ID is the name of the connection profile, e.g. "Home WiFi".
+	ID   string
+	UUID string
+	// Type of the connection, e.g. "802-11-wireless", "802-3-ethernet",
+	// "vpn", or "wireguard".
+	Type string
+	// Devices lists the interfaces used by the connection.
+	Devices []string
+	State   State
+	VPN     bool
+	// Default is true if the connection owns the default IPv4 or IPv6 route.
+	Default bool
+
+	path godbus.ObjectPath
+	conn busConn
+}
+
+// Deactivate deactivates the connection.
+func (c Connection) Deactivate() error {
+	return c.conn.Object(nmService, nmPath).
+		Call(nmIface+".DeactivateConnection", 0, c.path).Err
+}
+
+// Info represents the state of NetworkManager. Connections can only be
+// activated or deactivated while the module is running.
+type Info struct {
+	Connectivity Connectivity
+	// Metered is true if the primary connection is known or guessed to be
+	// metered (e.g. a mobile hotspot).
+	Metered bool
+	// Connections lists all active connections.
+	Connections []Connection
+
+	primary godbus.ObjectPath
+	conn    busConn
+}
+
+// Primary returns the primary connection, which owns the default route.
+func (i Info) Primary() (Connection, bool) {
+	for _, c := range i.Connections {
+		if c.path == i.primary {
+			return c, true
+		}
+	}
+	return Connection{}, false
+}
+
+// Connection returns the active connection with the given ID.
+func (i Info) Connection(id string) (Connection, bool) {
+	for _, c := range i.Connections {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Connection{}, false
+}
+
+// Activate activates the connection profile with the given ID, letting
+// NetworkManager choose the device to use.
+func (i Info) Activate(id string) error {
+	profile, err := findProfile(i.conn, id)
+	if err != nil {
+		return err
+	}
+	return i.conn.Object(nmService, nmPath).Call(nmIface+".ActivateConnection", 0,
+		profile, godbus.ObjectPath("/"), godbus.ObjectPath("/")).Err
+}
+
+// Deactivate deactivates the active connection with the given ID.
+func (i Info) Deactivate(id string) error {
+	c, ok := i.Connection(id)
+	if !ok {
+		return fmt.Errorf("connection %q is not active", id)
+	}
+	return c.Deactivate()
+}
+
+// Toggle deactivates the connection with the given ID if it is active,
+// otherwise it activates it.
+func (i Info) Toggle(id string) error {
+	if _, ok := i.Connection(id); ok {
+		return i.Deactivate(id)
+	}
+	return i.Activate(id)
+}
+
+// Module represents a NetworkManager bar module.
+type Module struct {
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New creates a NetworkManager module.
+func New() *Module {
+	m := &Module{}
+	l.Register(m, "outputFunc")
+	m.Output(func(i Info) bar.Output {
+		c, ok := i.Primary()
+		if !ok {
+			return nil
+		}
+		switch i.Connectivity {
+		case ConnectivityPortal:
+			return outputs.Textf("%s (portal)", c.ID)
+		case ConnectivityLimited:
+			return outputs.Textf("%s (limited)", c.ID)
+		}
+		return outputs.Text(c.ID)
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+const (
+	nmService       = "org.freedesktop.NetworkManager"
+	nmPath          = "/org/freedesktop/NetworkManager"
+	nmIface         = "org.freedesktop.NetworkManager"
+	activeNamespace = "/org/freedesktop/NetworkManager/ActiveConnection"
+	activeIface     = "org.freedesktop.NetworkManager.Connection.Active"
+	deviceIface     = "org.freedesktop.NetworkManager.Device"
+	settingsPath    = "/org/freedesktop/NetworkManager/Settings"
+	settingsIface   = "org.freedesktop.NetworkManager.Settings"
+	connectionIface = "org.freedesktop.NetworkManager.Settings.Connection"
+	propsIface      = "org.freedesktop.DBus.Properties"
+)
+
+var busType = dbus.System // Overridden in tests.
+
+// busConn is the subset of a DBus connection used for method calls and
+// signals that do not need a PropertiesWatcher.
+type busConn interface {
+	BusObject() godbus.BusObject
+	Object(string, godbus.ObjectPath) godbus.BusObject
+	Signal(chan<- *godbus.Signal)
+	RemoveSignal(chan<- *godbus.Signal)
+	Close() error
+}
+
+// activeProps lists the properties of an active connection that are used.
+var activeProps = []string{
+	"Id", "Uuid", "Type", "Devices", "State", "Vpn", "Default", "Default6",
+}
+
+// activeTracker keeps the properties of active connections up to date, using
+// a single subscription to property changes of all active connections.
+type activeTracker struct {
+	conn    busConn
+	signals chan *godbus.Signal
+	props   map[godbus.ObjectPath]map[string]interface{}
+	devices map[godbus.ObjectPath]string
+
+	mu      sync.Mutex
+	changed map[godbus.ObjectPath]bool
+}
+
+func trackActive(conn busConn, notify func()) *activeTracker {
+	t := &activeTracker{
+		conn:    conn,
+		signals: make(chan *godbus.Signal, 10),
+		props:   map[godbus.ObjectPath]map[string]interface{}{},
+		devices: map[godbus.ObjectPath]string{},
+		changed: map[godbus.ObjectPath]bool{},
+	}
+	conn.BusObject().AddMatchSignal(propsIface, "PropertiesChanged",
+		godbus.WithMatchPathNamespace(activeNamespace),
+		godbus.WithMatchArg(0, activeIface))
+	conn.Signal(t.signals)
+	go func() {
+		for sig := range t.signals {
+			t.mu.Lock()
+			t.changed[sig.Path] = true
+			t.mu.Unlock()
+			notify()
+		}
+	}()
+	return t
+}
+
+// update refreshes the properties of changed connections, and returns the
+// connections for the given paths.
+func (t *activeTracker) update(paths []godbus.ObjectPath) []Connection {
+	t.mu.Lock()
+	changed := t.changed
+	t.changed = map[godbus.ObjectPath]bool{}
+	t.mu.Unlock()
+
+	current := map[godbus.ObjectPath]bool{}
+	var conns []Connection
+	for _, path := range paths {
+		current[path] = true
+		props, ok := t.props[path]
+		if !ok || changed[path] {
+			props = t.fetch(path)
+			t.props[path] = props
+		}
+		conns = append(conns, t.connection(path, props))
+	}
+	inUse := map[godbus.ObjectPath]bool{}
+	for path, props := range t.props {
+		if !current[path] {
+			delete(t.props, path)
+			continue
+		}
+		devPaths, _ := props["Devices"].([]godbus.ObjectPath)
+		for _, dev := range devPaths {
+			inUse[dev] = true
+		}
+	}
+	for dev := range t.devices {
+		if !inUse[dev] {
+			delete(t.devices, dev)
+		}
+	}
+	return conns
+}
+
+func (t *activeTracker) fetch(path godbus.ObjectPath) map[string]interface{} {
+	obj := t.conn.Object(nmService, path)
+	props := map[string]interface{}{}
+	for _, name := range activeProps {
+		if v, err := obj.GetProperty(activeIface + "." + name); err == nil {
+			props[name] = v.Value()
+		}
+	}
+	return props
+}
+
+func (t *activeTracker) connection(path godbus.ObjectPath, props map[string]interface{}) Connection {
+	c := Connection{path: path, conn: t.conn}
+	c.ID, _ = props["Id"].(string)
+	c.UUID, _ = props["Uuid"].(string)
+	c.Type, _ = props["Type"].(string)
+	if state, ok := props["State"].(uint32); ok {
+		c.State = State(state)
+	}
+	c.VPN, _ = props["Vpn"].(bool)
+	default4, _ := props["Default"].(bool)
+	default6, _ := props["Default6"].(bool)
+	c.Default = default4 || default6
+	devPaths, _ := props["Devices"].([]godbus.ObjectPath)
+	for _, dev := range devPaths {
+		name, ok := t.devices[dev]
+		if !ok {
+			// Device names do not change, so they only need to be fetched
+			// once. On error, they are fetched again on the next update.
+			v, err := t.conn.Object(nmService, dev).GetProperty(deviceIface + ".Interface")
+			if err != nil {
+				continue
+			}
+			if name, ok = v.Value().(string); !ok {
+				continue
+			}
+			t.devices[dev] = name
+		}
+		c.Devices = append(c.Devices, name)
+	}
+	sort.Strings(c.Devices)
+	return c
+}
+
+func (t *activeTracker) close() {
+	t.conn.RemoveSignal(t.signals)
+	close(t.signals)
+}
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	nm := dbus.WatchProperties(busType, nmService, nmPath, nmIface).
+		Add("Connectivity", "Metered", "ActiveConnections", "PrimaryConnection")
+	defer nm.Unsubscribe()
+
+	// A single connection is used for all method calls, and to track the
+	// properties of active connections.
+	var conn busConn = busType()
+	defer conn.Close()
+	notifyFn, activeChanged := notifier.New()
+	active := trackActive(conn, notifyFn)
+	defer active.close()
+
+	for {
+		props := nm.Get()
+		info := Info{conn: conn}
+		if c, ok := props["Connectivity"].(uint32); ok {
+			info.Connectivity = Connectivity(c)
+		}
+		metered, _ := props["Metered"].(uint32)
+		// NM_METERED_YES and NM_METERED_GUESS_YES.
+		info.Metered = metered == 1 || metered == 3
+		info.primary, _ = props["PrimaryConnection"].(godbus.ObjectPath)
+		paths, _ := props["ActiveConnections"].([]godbus.ObjectPath)
+		info.Connections = active.update(paths)
+
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-nm.Updates:
+		case <-activeChanged:
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+	}
+}
+
+// findProfile returns the object path of the connection profile with the
+// given ID.
+func findProfile(conn busConn, id string) (godbus.ObjectPath, error) {
+	var paths []godbus.ObjectPath
+	err := conn.Object(nmService, settingsPath).
+		Call(settingsIface+".ListConnections", 0).Store(&paths)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		var settings map[string]map[string]godbus.Variant
+		err := conn.Object(nmService, path).
+			Call(connectionIface+".GetSettings", 0).Store(&settings)
+		if err != nil {
+			return "", err
+		}
+		if profileID, _ := settings["connection"]["id"].Value().(string); profileID == id {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no connection profile named %q", id)
+}
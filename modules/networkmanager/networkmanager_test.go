@@ -0,0 +1,253 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"fmt"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testNM struct {
+	svc *dbus.TestBusService
+	nm  *dbus.TestBusObject
+}
+
+func setupTestNM() *testNM {
+	busType = dbus.Test
+	bus := dbus.SetupTestBus()
+	svc := bus.RegisterService(nmService)
+	t := &testNM{svc: svc, nm: svc.Object(nmPath, nmIface)}
+	t.nm.SetProperties(map[string]interface{}{
+		"Connectivity":      uint32(1),
+		"Metered":           uint32(0),
+		"ActiveConnections": []godbus.ObjectPath{},
+		"PrimaryConnection": godbus.ObjectPath("/"),
+	}, dbus.SignalTypeNone)
+	for i, name := range []string{"wlp3s0", "enp0s31f6", "tun0"} {
+		svc.Object(devicePath(i), deviceIface).
+			SetProperties(map[string]interface{}{"Interface": name}, dbus.SignalTypeNone)
+	}
+	return t
+}
+
+func devicePath(i int) godbus.ObjectPath {
+	return godbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/Devices/%d", i))
+}
+
+func activePath(i int) godbus.ObjectPath {
+	return godbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/ActiveConnection/%d", i))
+}
+
+func (t *testNM) addActive(i int, props map[string]interface{}) *dbus.TestBusObject {
+	obj := t.svc.Object(activePath(i), activeIface)
+	obj.SetProperties(props, dbus.SignalTypeNone)
+	return obj
+}
+
+func TestNetworkManager(t *testing.T) {
+	nm := setupTestNM()
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		infos <- i
+		c, ok := i.Primary()
+		if !ok {
+			return outputs.Text("offline")
+		}
+		return outputs.Textf("%s %v %d %v", c.ID, c.Devices, i.Connectivity, i.Metered)
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"offline"})
+	require.Empty(t, (<-infos).Connections)
+
+	nm.addActive(1, map[string]interface{}{
+		"Id":       "Home",
+		"Uuid":     "2d5a1c1e-0d6b-4c55-8b5d-4c3a4cd8e6a1",
+		"Type":     "802-11-wireless",
+		"Devices":  []godbus.ObjectPath{devicePath(0)},
+		"State":    uint32(2),
+		"Vpn":      false,
+		"Default":  true,
+		"Default6": false,
+	})
+	nm.nm.SetProperties(map[string]interface{}{
+		"Connectivity":      uint32(4),
+		"ActiveConnections": []godbus.ObjectPath{activePath(1)},
+		"PrimaryConnection": activePath(1),
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on connect").AssertText([]string{"Home [wlp3s0] 4 false"})
+	info := <-infos
+	require.Equal(t, 1, len(info.Connections))
+	c := info.Connections[0]
+	require.Equal(t, "802-11-wireless", c.Type)
+	require.Equal(t, Activated, c.State)
+	require.True(t, c.Default)
+	require.False(t, c.VPN)
+
+	vpn := nm.addActive(2, map[string]interface{}{
+		"Id":      "Work VPN",
+		"Type":    "vpn",
+		"Devices": []godbus.ObjectPath{devicePath(2)},
+		"State":   uint32(1),
+		"Vpn":     true,
+	})
+	nm.nm.SetProperties(map[string]interface{}{
+		"ActiveConnections": []godbus.ObjectPath{activePath(1), activePath(2)},
+		"Metered":           uint32(3),
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on vpn connecting").AssertText([]string{"Home [wlp3s0] 4 true"})
+	info = <-infos
+	c, ok := info.Connection("Work VPN")
+	require.True(t, ok)
+	require.Equal(t, Activating, c.State)
+	require.Equal(t, []string{"tun0"}, c.Devices)
+
+	vpn.SetPropertyForTest("State", uint32(2), dbus.SignalTypeChanged)
+	testBar.NextOutput("on vpn activated").Expect("on vpn activated")
+	c, _ = (<-infos).Connection("Work VPN")
+	require.Equal(t, Activated, c.State)
+
+	nm.nm.SetProperties(map[string]interface{}{
+		"Connectivity":      uint32(2),
+		"ActiveConnections": []godbus.ObjectPath{activePath(2)},
+		"PrimaryConnection": activePath(2),
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on primary change").AssertText([]string{"Work VPN [tun0] 2 true"})
+	info = <-infos
+	require.Equal(t, ConnectivityPortal, info.Connectivity)
+	_, ok = info.Connection("Home")
+	require.False(t, ok)
+}
+
+func TestDefaultOutput(t *testing.T) {
+	nm := setupTestNM()
+	nm.addActive(1, map[string]interface{}{"Id": "Cafe", "State": uint32(2)})
+	nm.nm.SetProperties(map[string]interface{}{
+		"Connectivity":      uint32(2),
+		"ActiveConnections": []godbus.ObjectPath{activePath(1)},
+		"PrimaryConnection": activePath(1),
+	}, dbus.SignalTypeNone)
+
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput("on start").AssertText([]string{"Cafe (portal)"})
+
+	nm.nm.SetPropertyForTest("Connectivity", uint32(3), dbus.SignalTypeChanged)
+	testBar.NextOutput("on limited").AssertText([]string{"Cafe (limited)"})
+
+	nm.nm.SetPropertyForTest("Connectivity", uint32(4), dbus.SignalTypeChanged)
+	testBar.NextOutput("on full").AssertText([]string{"Cafe"})
+
+	nm.nm.SetProperties(map[string]interface{}{
+		"ActiveConnections": []godbus.ObjectPath{},
+		"PrimaryConnection": godbus.ObjectPath("/"),
+	}, dbus.SignalTypeChanged)
+	testBar.NextOutput("on disconnect").AssertEmpty()
+}
+
+func TestDeviceNames(t *testing.T) {
+	nm := setupTestNM()
+	active := nm.addActive(1, map[string]interface{}{
+		"Id":      "Home",
+		"Devices": []godbus.ObjectPath{devicePath(3)},
+		"State":   uint32(1),
+	})
+	nm.nm.SetProperties(map[string]interface{}{
+		"ActiveConnections": []godbus.ObjectPath{activePath(1)},
+		"PrimaryConnection": activePath(1),
+	}, dbus.SignalTypeNone)
+
+	testBar.New(t)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		c, _ := i.Primary()
+		return outputs.Textf("%s %v", c.ID, c.Devices)
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"Home []"},
+		"device without a name")
+
+	nm.svc.Object(devicePath(3), deviceIface).
+		SetProperties(map[string]interface{}{"Interface": "wlan1"}, dbus.SignalTypeNone)
+	active.SetPropertyForTest("State", uint32(2), dbus.SignalTypeChanged)
+	testBar.NextOutput("on change").AssertText([]string{"Home [wlan1]"},
+		"failed device names are fetched again")
+}
+
+func TestActivation(t *testing.T) {
+	nm := setupTestNM()
+	nm.addActive(1, map[string]interface{}{"Id": "Home", "State": uint32(2)})
+	nm.nm.SetProperties(map[string]interface{}{
+		"ActiveConnections": []godbus.ObjectPath{activePath(1)},
+	}, dbus.SignalTypeNone)
+
+	profiles := map[godbus.ObjectPath]string{
+		"/org/freedesktop/NetworkManager/Settings/1": "Home",
+		"/org/freedesktop/NetworkManager/Settings/2": "Work VPN",
+	}
+	nm.svc.Object(settingsPath, settingsIface).On("ListConnections",
+		func(...interface{}) ([]interface{}, error) {
+			var paths []godbus.ObjectPath
+			for p := range profiles {
+				paths = append(paths, p)
+			}
+			return []interface{}{paths}, nil
+		})
+	for path, id := range profiles {
+		id := id
+		nm.svc.Object(path, connectionIface).On("GetSettings",
+			func(...interface{}) ([]interface{}, error) {
+				return []interface{}{map[string]map[string]godbus.Variant{
+					"connection": {"id": godbus.MakeVariant(id)},
+				}}, nil
+			})
+	}
+	calls := make(chan []interface{}, 10)
+	nm.nm.On("ActivateConnection", func(args ...interface{}) ([]interface{}, error) {
+		calls <- append([]interface{}{"activate"}, args...)
+		return []interface{}{activePath(2)}, nil
+	})
+	nm.nm.On("DeactivateConnection", func(args ...interface{}) ([]interface{}, error) {
+		calls <- append([]interface{}{"deactivate"}, args...)
+		return nil, nil
+	})
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	testBar.Run(New().Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Text("nm")
+	}))
+	testBar.NextOutput("on start").Expect("on start")
+	info := <-infos
+
+	require.NoError(t, info.Toggle("Work VPN"))
+	require.Equal(t, []interface{}{"activate",
+		godbus.ObjectPath("/org/freedesktop/NetworkManager/Settings/2"),
+		godbus.ObjectPath("/"), godbus.ObjectPath("/")}, <-calls)
+
+	require.NoError(t, info.Toggle("Home"))
+	require.Equal(t, []interface{}{"deactivate", activePath(1)}, <-calls)
+
+	require.Error(t, info.Activate("Unknown"))
+	require.Error(t, info.Deactivate("Work VPN"))
+	require.Empty(t, calls)
+}
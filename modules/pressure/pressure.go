@@ -0,0 +1,270 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pressure provides an i3bar module that shows pressure stall
+// information (PSI) for CPU, memory, and IO, either for the whole system or
+// for a single cgroup.
+package pressure
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+)
+
+// Resource is a resource that pressure is tracked for.
+type Resource string
+
+// Resources with pressure information.
+const (
+	CPU    Resource = "cpu"
+	Memory Resource = "memory"
+	IO     Resource = "io"
+)
+
+// Stall represents the time that tasks were stalled on a resource.
+type Stall struct {
+	// Avg10, Avg60, and Avg300 are the percentage of time stalled over
+	// the last 10, 60, and 300 seconds respectively.
+	Avg10, Avg60, Avg300 float64
+	// Total is the total time stalled.
+	Total time.Duration
+}
+
+// Pressure represents the pressure on a single resource.
+type Pressure struct {
+	// Some tracks the time that at least some tasks were stalled.
+	Some Stall
+	// Full tracks the time that all non-idle tasks were stalled at the same
+	// time. It is always zero for system-wide CPU pressure.
+	Full Stall
+}
+
+// Info represents pressure stall information for all resources.
+type Info struct {
+	CPU, Memory, IO Pressure
+}
+
+// trigger is a PSI trigger threshold, see Module.Trigger.
+type trigger struct {
+	resource Resource
+	full     bool
+	stall    time.Duration
+	window   time.Duration
+}
+
+func (t trigger) spec() string {
+	kind := "some"
+	if t.full {
+		kind = "full"
+	}
+	return fmt.Sprintf("%s %d %d", kind, t.stall.Microseconds(), t.window.Microseconds())
+}
+
+// Module represents a bar.Module that displays pressure stall information.
+type Module struct {
+	dir        string
+	suffix     string
+	scheduler  *timing.Scheduler
+	triggers   value.Value // of []trigger
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+func newModule(dir, suffix string) *Module {
+	m := &Module{dir: dir, suffix: suffix, scheduler: timing.NewScheduler()}
+	l.Register(m, "scheduler", "triggers", "outputFunc")
+	m.triggers.Set([]trigger(nil))
+	m.RefreshInterval(3 * time.Second)
+	m.Output(func(i Info) bar.Output {
+		return outputs.Textf("CPU %.0f%% Mem %.0f%% IO %.0f%%",
+			i.CPU.Some.Avg10, i.Memory.Some.Avg10, i.IO.Some.Avg10)
+	})
+	return m
+}
+
+// New constructs a module that shows system-wide pressure, from
+// /proc/pressure.
+func New() *Module {
+	return newModule("/proc/pressure", "")
+}
+
+// Cgroup constructs a module that shows the pressure for a cgroup, from the
+// *.pressure files in its directory. The cgroup path is relative to the
+// cgroup2 mount at /sys/fs/cgroup, e.g. "/user.slice".
+func Cgroup(cgroup string) *Module {
+	m := newModule(path.Join("/sys/fs/cgroup", cgroup), ".pressure")
+	l.Label(m, cgroup)
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Trigger adds a PSI trigger, which updates the module immediately when tasks
+// are stalled on the resource for longer than stall within any window,
+// instead of waiting for the next refresh. If full is true, the threshold
+// applies to the time that all tasks were stalled, otherwise it applies to the
+// time that some tasks were stalled.
+//
+// The window must be between 500ms and 10s, and for unprivileged users it
+// must be a multiple of 2s. Triggers take effect when the module is next
+// started.
+func (m *Module) Trigger(r Resource, full bool, stall, window time.Duration) *Module {
+	triggers := m.triggers.Get().([]trigger)
+	triggers = append(triggers[:len(triggers):len(triggers)],
+		trigger{resource: r, full: full, stall: stall, window: window})
+	m.triggers.Set(triggers)
+	return m
+}
+
+func (m *Module) file(r Resource) string {
+	return path.Join(m.dir, string(r)+m.suffix)
+}
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	triggered := make(chan struct{}, 1)
+	triggerErr := make(chan error, 1)
+	for _, t := range m.triggers.Get().([]trigger) {
+		w, err := openTrigger(m.file(t.resource), t.spec())
+		if s.Error(err) {
+			return
+		}
+		defer w.Close()
+		go func(w triggerWatcher) {
+			for {
+				if err := w.Wait(); err != nil {
+					select {
+					case triggerErr <- err:
+					default:
+					}
+					return
+				}
+				select {
+				case triggered <- struct{}{}:
+				default:
+				}
+			}
+		}(w)
+	}
+
+	info, err := m.read()
+	for {
+		if s.Error(err) {
+			return
+		}
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			info, err = m.read()
+		case <-triggered:
+			l.Fine("%s: triggered", l.ID(m))
+			info, err = m.read()
+		case err = <-triggerErr:
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+	}
+}
+
+var fs = afero.NewOsFs()
+
+func (m *Module) read() (i Info, err error) {
+	if i.CPU, err = readPressure(m.file(CPU)); err != nil {
+		return i, err
+	}
+	if i.Memory, err = readPressure(m.file(Memory)); err != nil {
+		return i, err
+	}
+	i.IO, err = readPressure(m.file(IO))
+	return i, err
+}
+
+// readPressure parses a pressure file, which looks like:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressure(filename string) (p Pressure, err error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return p, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var stall *Stall
+		switch fields[0] {
+		case "some":
+			stall = &p.Some
+		case "full":
+			stall = &p.Full
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, val, _ := strings.Cut(field, "=")
+			num, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return p, fmt.Errorf("%s: %s: %w", filename, key, err)
+			}
+			switch key {
+			case "avg10":
+				stall.Avg10 = num
+			case "avg60":
+				stall.Avg60 = num
+			case "avg300":
+				stall.Avg300 = num
+			case "total":
+				stall.Total = time.Duration(num) * time.Microsecond
+			}
+		}
+	}
+	return p, s.Err()
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pressure
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func writePressure(filename string, some, full float64, total int) {
+	afero.WriteFile(fs, filename, []byte(fmt.Sprintf(
+		"some avg10=%.2f avg60=%.2f avg300=%.2f total=%d\n"+
+			"full avg10=%.2f avg60=%.2f avg300=%.2f total=%d\n",
+		some, some/2, some/4, total, full, full/2, full/4, total/2)), 0444)
+}
+
+type mockTrigger struct {
+	file, spec string
+	fire       chan error
+	closed     chan struct{}
+	once       sync.Once
+}
+
+func (m *mockTrigger) Wait() error {
+	select {
+	case err := <-m.fire:
+		return err
+	case <-m.closed:
+		return errors.New("closed")
+	}
+}
+
+func (m *mockTrigger) Close() error {
+	m.once.Do(func() { close(m.closed) })
+	return nil
+}
+
+func mockTriggers() <-chan *mockTrigger {
+	ch := make(chan *mockTrigger, 10)
+	openTrigger = func(file, spec string) (triggerWatcher, error) {
+		if file == "/proc/pressure/missing" {
+			return nil, os.ErrNotExist
+		}
+		t := &mockTrigger{file: file, spec: spec,
+			fire: make(chan error), closed: make(chan struct{})}
+		ch <- t
+		return t, nil
+	}
+	return ch
+}
+
+func TestPressure(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	writePressure("/proc/pressure/cpu", 1, 0, 1000)
+	writePressure("/proc/pressure/memory", 2, 1, 2000)
+	writePressure("/proc/pressure/io", 10, 5, 3000000)
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	def := New()
+	custom := New().Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Textf("%.1f/%.1f/%.1f %v",
+			i.IO.Some.Avg10, i.IO.Some.Avg60, i.IO.Full.Avg300, i.IO.Some.Total)
+	})
+	testBar.Run(def, custom)
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU 1% Mem 2% IO 10%", "10.0/5.0/1.2 3s"}, "on start")
+	require.Equal(t, Pressure{
+		Some: Stall{Avg10: 2, Avg60: 1, Avg300: 0.5, Total: 2 * time.Millisecond},
+		Full: Stall{Avg10: 1, Avg60: 0.5, Avg300: 0.25, Total: time.Millisecond},
+	}, (<-infos).Memory)
+
+	writePressure("/proc/pressure/io", 50.5, 40, 4000000)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU 1% Mem 2% IO 50%", "50.5/25.2/10.0 4s"}, "on tick")
+
+	beforeTick := timing.Now()
+	def.RefreshInterval(time.Minute)
+	custom.RefreshInterval(time.Minute)
+	testBar.Tick()
+	require.Equal(t, time.Minute, timing.Now().Sub(beforeTick), "RefreshInterval change")
+	testBar.LatestOutput().Expect("on tick")
+
+	fs.Remove("/proc/pressure/memory")
+	testBar.Tick()
+	testBar.LatestOutput().AssertError("when pressure file is missing")
+}
+
+func TestCgroup(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	dir := "/sys/fs/cgroup/user.slice/user-1000.slice"
+	writePressure(dir+"/cpu.pressure", 3, 1, 1000)
+	writePressure(dir+"/memory.pressure", 4, 2, 1000)
+	writePressure(dir+"/io.pressure", 5, 3, 1000)
+
+	testBar.New(t)
+	testBar.Run(Cgroup("/user.slice/user-1000.slice").Output(func(i Info) bar.Output {
+		return outputs.Textf("%.0f/%.0f %.0f/%.0f %.0f/%.0f",
+			i.CPU.Some.Avg10, i.CPU.Full.Avg10,
+			i.Memory.Some.Avg10, i.Memory.Full.Avg10,
+			i.IO.Some.Avg10, i.IO.Full.Avg10)
+	}))
+	testBar.LatestOutput().AssertText([]string{"3/1 4/2 5/3"})
+}
+
+func TestTriggers(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	writePressure("/proc/pressure/cpu", 1, 0, 1000)
+	writePressure("/proc/pressure/memory", 2, 1, 2000)
+	writePressure("/proc/pressure/io", 10, 5, 3000000)
+	triggers := mockTriggers()
+
+	testBar.New(t)
+	m := New().
+		Trigger(Memory, true, 100*time.Millisecond, time.Second).
+		Trigger(IO, false, 150*time.Millisecond, 2*time.Second)
+	testBar.Run(m)
+	testBar.LatestOutput().AssertText([]string{"CPU 1% Mem 2% IO 10%"})
+
+	memTrigger := <-triggers
+	require.Equal(t, "/proc/pressure/memory", memTrigger.file)
+	require.Equal(t, "full 100000 1000000", memTrigger.spec)
+	ioTrigger := <-triggers
+	require.Equal(t, "/proc/pressure/io", ioTrigger.file)
+	require.Equal(t, "some 150000 2000000", ioTrigger.spec)
+
+	writePressure("/proc/pressure/memory", 60, 40, 2000)
+	testBar.AssertNoOutput("until triggered")
+	memTrigger.fire <- nil
+	testBar.NextOutput("on trigger").AssertText([]string{"CPU 1% Mem 60% IO 10%"})
+
+	writePressure("/proc/pressure/io", 20, 5, 3000000)
+	ioTrigger.fire <- nil
+	testBar.NextOutput("on trigger").AssertText([]string{"CPU 1% Mem 60% IO 20%"})
+
+	ioTrigger.fire <- errors.New("trigger is no longer valid")
+	out := testBar.NextOutput("on trigger error")
+	out.AssertError()
+	select {
+	case <-memTrigger.closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "triggers should be closed when the module stops")
+	}
+
+	out.At(0).LeftClick()
+	testBar.NextOutput().Expect("on restart, clears error segment")
+	testBar.NextOutput("on restart").AssertText([]string{"CPU 1% Mem 60% IO 20%"})
+	require.Equal(t, "full 100000 1000000", (<-triggers).spec, "trigger re-registered on restart")
+	<-triggers
+
+	testBar.New(t)
+	testBar.Run(New().Trigger("missing", false, time.Second, 10*time.Second))
+	testBar.NextOutput().AssertError("when trigger cannot be opened")
+}
+
+func TestParseErrors(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	afero.WriteFile(fs, "/pressure", []byte(
+		"\nother avg10=abc\nsome avg10=1.00 foo=1 total=12\n"), 0444)
+	p, err := readPressure("/pressure")
+	require.NoError(t, err)
+	require.Equal(t, Pressure{Some: Stall{Avg10: 1, Total: 12 * time.Microsecond}}, p)
+
+	afero.WriteFile(fs, "/pressure", []byte("some avg10=abc\n"), 0444)
+	_, err = readPressure("/pressure")
+	require.Error(t, err)
+}
+
+func TestPollTrigger(t *testing.T) {
+	// Regular files never report POLLPRI, so Wait only returns on Close.
+	file := filepath.Join(t.TempDir(), "cpu")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	w, err := openPollTrigger(file, "some 150000 1000000")
+	require.NoError(t, err)
+	content, _ := os.ReadFile(file)
+	require.Equal(t, "some 150000 1000000\x00", string(content))
+
+	errCh := make(chan error)
+	go func() { errCh <- w.Wait() }()
+	select {
+	case err := <-errCh:
+		require.Fail(t, "unexpected trigger", "%v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, w.Close())
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "Close should stop Wait")
+	}
+
+	_, err = openPollTrigger(filepath.Join(t.TempDir(), "missing"), "some 1 1")
+	require.Error(t, err)
+}
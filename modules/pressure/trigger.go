@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pressure
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// triggerWatcher waits for a PSI trigger to fire.
+type triggerWatcher interface {
+	// Wait blocks until the trigger fires, or returns an error if the
+	// trigger is no longer valid or the watcher was closed.
+	Wait() error
+	Close() error
+}
+
+// To allow tests to mock out PSI triggers.
+var openTrigger = openPollTrigger
+
+// pollTrigger is a PSI trigger, which is registered by writing the trigger
+// to the pressure file, and fires as POLLPRI on the open file.
+type pollTrigger struct {
+	fd      int
+	closeFd [2]int
+}
+
+func openPollTrigger(filename, spec string) (triggerWatcher, error) {
+	fd, err := unix.Open(filename, unix.O_RDWR|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+	if _, err := unix.Write(fd, append([]byte(spec), 0)); err != nil {
+		unix.Close(fd)
+		return nil, &os.PathError{Op: "trigger", Path: filename, Err: err}
+	}
+	t := &pollTrigger{fd: fd}
+	// Closing fd does not interrupt a poll in progress, so also poll on a
+	// pipe that is closed to stop waiting.
+	if err := unix.Pipe2(t.closeFd[:], unix.O_CLOEXEC); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *pollTrigger) Wait() (err error) {
+	defer func() {
+		// The trigger cannot be used after an error, so release it.
+		if err != nil {
+			unix.Close(t.fd)
+			unix.Close(t.closeFd[0])
+		}
+	}()
+	fds := []unix.PollFd{
+		{Fd: int32(t.fd), Events: unix.POLLPRI},
+		{Fd: int32(t.closeFd[0]), Events: unix.POLLIN},
+	}
+	for {
+		_, err := unix.Poll(fds, -1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case fds[1].Revents != 0:
+			return errors.New("trigger closed")
+		case fds[0].Revents&unix.POLLERR != 0:
+			return errors.New("trigger is no longer valid")
+		case fds[0].Revents&unix.POLLPRI != 0:
+			return nil
+		}
+	}
+}
+
+// Close stops any Wait in progress. The trigger is released when Wait returns.
+func (t *pollTrigger) Close() error {
+	return unix.Close(t.closeFd[1])
+}
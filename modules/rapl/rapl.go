@@ -0,0 +1,250 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rapl provides an i3bar module that shows the power consumed by the
+// CPU, using the RAPL energy counters in /sys/class/powercap.
+//
+// On recent kernels, the energy counters are only readable by root. To use
+// this module as a regular user, make energy_uj readable, e.g. with a udev
+// rule or a tmpfiles.d entry.
+package rapl
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	"github.com/soumya92/barista/format"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+)
+
+// Domain is the average power consumed by a single RAPL domain between
+// samples.
+type Domain struct {
+	// Name is the name of the domain, e.g. "package-0", "core", "uncore",
+	// "dram", or "psys".
+	Name string
+	// Parent is the name of the package for subdomains (e.g. "package-0" for
+	// "core"), and empty for top-level domains.
+	Parent string
+	Power  unit.Power
+}
+
+// Info contains the power consumed by each RAPL domain.
+type Info []Domain
+
+// Package returns the total power consumed by all CPU packages.
+func (i Info) Package() unit.Power {
+	var total unit.Power
+	for _, d := range i {
+		if d.Parent == "" && strings.HasPrefix(d.Name, "package-") {
+			total += d.Power
+		}
+	}
+	return total
+}
+
+// Domain returns the total power consumed by all domains with the given
+// name, e.g. "core" or "dram" across all packages, and false if there are no
+// such domains.
+func (i Info) Domain(name string) (unit.Power, bool) {
+	var total unit.Power
+	found := false
+	for _, d := range i {
+		if d.Name == name {
+			total += d.Power
+			found = true
+		}
+	}
+	return total, found
+}
+
+// Module represents a bar.Module that shows RAPL power consumption.
+type Module struct {
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New constructs a RAPL module that shows the power consumed by all domains.
+func New() *Module {
+	m := &Module{scheduler: timing.NewScheduler()}
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(3 * time.Second)
+	m.Output(func(i Info) bar.Output {
+		return outputs.Textf("CPU: %s", format.SI(i.Package().Watts(), "W"))
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency. Power is averaged over
+// this interval.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+	zones, err := discover()
+	if s.Error(err) {
+		return
+	}
+	// Power needs two samples, so the first sample only records the
+	// current counters.
+	if s.Error(sample(zones)) {
+		return
+	}
+	var info Info
+	started := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			if s.Error(sample(zones)) {
+				return
+			}
+			info = make(Info, len(zones))
+			for i, z := range zones {
+				info[i] = z.domain()
+			}
+			started = true
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+		if started {
+			s.Output(outputFunc(info))
+		}
+	}
+}
+
+var fs = afero.NewOsFs()
+
+const baseDir = "/sys/class/powercap"
+
+// zone is a single RAPL domain, with the state needed to compute power.
+type zone struct {
+	dir      string
+	name     string
+	parent   string
+	maxRange uint64
+
+	lastEnergy uint64
+	lastTime   time.Time
+	power      unit.Power
+}
+
+func (z *zone) domain() Domain {
+	return Domain{Name: z.name, Parent: z.parent, Power: z.power}
+}
+
+// discover finds all RAPL zones, e.g. intel-rapl:0 and intel-rapl:0:1.
+func discover() ([]*zone, error) {
+	dirs, err := afero.ReadDir(fs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	var zoneDirs []string
+	for _, d := range dirs {
+		// intel-rapl itself is the control type, not a zone.
+		if !strings.HasPrefix(d.Name(), "intel-rapl:") {
+			continue
+		}
+		name, err := readString(filepath.Join(baseDir, d.Name(), "name"))
+		if err != nil {
+			return nil, err
+		}
+		names[d.Name()] = name
+		zoneDirs = append(zoneDirs, d.Name())
+	}
+	sort.Strings(zoneDirs)
+	var zones []*zone
+	for _, d := range zoneDirs {
+		dir := filepath.Join(baseDir, d)
+		maxRange, err := readUint(filepath.Join(dir, "max_energy_range_uj"))
+		if err != nil {
+			return nil, err
+		}
+		z := &zone{dir: dir, name: names[d], maxRange: maxRange}
+		if idx := strings.LastIndex(d, ":"); idx > len("intel-rapl") {
+			z.parent = names[d[:idx]]
+		}
+		zones = append(zones, z)
+	}
+	if len(zones) == 0 {
+		return nil, errors.New("no RAPL zones found")
+	}
+	return zones, nil
+}
+
+// sample reads the energy counters of all zones, and updates their power.
+func sample(zones []*zone) error {
+	now := timing.Now()
+	for _, z := range zones {
+		energy, err := readUint(filepath.Join(z.dir, "energy_uj"))
+		if err != nil {
+			return err
+		}
+		if elapsed := now.Sub(z.lastTime).Seconds(); !z.lastTime.IsZero() && elapsed > 0 {
+			delta := energy - z.lastEnergy
+			if energy < z.lastEnergy {
+				// The counter wrapped around.
+				delta = z.maxRange - z.lastEnergy + energy
+			}
+			z.power = unit.Power(float64(delta)/elapsed) * unit.Microwatt
+		}
+		z.lastEnergy = energy
+		z.lastTime = now
+	}
+	return nil
+}
+
+func readString(filename string) (string, error) {
+	data, err := afero.ReadFile(fs, filename)
+	return strings.TrimSpace(string(data)), err
+}
+
+func readUint(filename string) (uint64, error) {
+	str, err := readString(filename)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(str, 10, 64)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rapl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func addZone(dir, name string, maxRange uint64) {
+	dir = "/sys/class/powercap/" + dir
+	afero.WriteFile(fs, dir+"/name", []byte(name+"\n"), 0444)
+	afero.WriteFile(fs, dir+"/max_energy_range_uj", []byte(fmt.Sprintf("%d\n", maxRange)), 0444)
+	setEnergy(dir, 0)
+}
+
+func setEnergy(dir string, uj uint64) {
+	if !strings.HasPrefix(dir, "/") {
+		dir = "/sys/class/powercap/" + dir
+	}
+	afero.WriteFile(fs, dir+"/energy_uj", []byte(fmt.Sprintf("%d\n", uj)), 0444)
+}
+
+func setupZones() {
+	fs = afero.NewMemMapFs()
+	fs.MkdirAll("/sys/class/powercap/intel-rapl", 0555)
+	addZone("intel-rapl:0", "package-0", 1000000000)
+	addZone("intel-rapl:0:0", "core", 1000000000)
+	addZone("intel-rapl:0:1", "dram", 1000000000)
+	addZone("intel-rapl:1", "package-1", 1000000000)
+	addZone("intel-rapl:1:0", "core", 1000000000)
+	addZone("intel-rapl:2", "psys", 50000000)
+}
+
+func TestRAPL(t *testing.T) {
+	setupZones()
+	testBar.New(t)
+
+	infos := make(chan Info, 10)
+	def := New()
+	custom := New().Output(func(i Info) bar.Output {
+		infos <- i
+		core, _ := i.Domain("core")
+		dram, _ := i.Domain("dram")
+		psys, _ := i.Domain("psys")
+		return outputs.Textf("%.1f/%.1f/%.1f", core.Watts(), dram.Watts(), psys.Watts())
+	})
+	testBar.Run(def, custom)
+	testBar.AssertNoOutput("until second sample")
+
+	// 3 seconds per tick.
+	setEnergy("intel-rapl:0", 30000000)
+	setEnergy("intel-rapl:0:0", 15000000)
+	setEnergy("intel-rapl:0:1", 3000000)
+	setEnergy("intel-rapl:1", 6000000)
+	setEnergy("intel-rapl:1:0", 3000000)
+	setEnergy("intel-rapl:2", 45000000)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 12W", "6.0/1.0/15.0"}, "on tick")
+	info := <-infos
+	require.Equal(t, Domain{Name: "core", Parent: "package-0", Power: info[1].Power}, info[1])
+	require.Equal(t, "", info[0].Parent)
+	require.InDelta(t, 5, info[1].Power.Watts(), 1e-9)
+	_, ok := info.Domain("uncore")
+	require.False(t, ok)
+
+	setEnergy("intel-rapl:0", 60000000)
+	setEnergy("intel-rapl:1", 12000000)
+	// psys wraps around, 5J to the end of the range and 10J after.
+	setEnergy("intel-rapl:2", 10000000)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 12W", "0.0/0.0/5.0"}, "on counter wraparound")
+
+	beforeTick := timing.Now()
+	def.RefreshInterval(time.Minute)
+	custom.RefreshInterval(time.Minute)
+	testBar.Tick()
+	require.Equal(t, time.Minute, timing.Now().Sub(beforeTick), "RefreshInterval change")
+	testBar.LatestOutput().AssertText(
+		[]string{"CPU: 0W", "0.0/0.0/0.0"}, "when counters are unchanged")
+
+	fs.Remove("/sys/class/powercap/intel-rapl:0:1/energy_uj")
+	testBar.Tick()
+	testBar.LatestOutput().AssertError("when energy cannot be read")
+}
+
+func TestErrors(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput().AssertError("without powercap")
+
+	fs.MkdirAll("/sys/class/powercap/intel-rapl", 0555)
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput().AssertError("without zones")
+
+	afero.WriteFile(fs, "/sys/class/powercap/intel-rapl:0/max_energy_range_uj", []byte("100\n"), 0444)
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput().AssertError("without name")
+
+	addZone("intel-rapl:0", "package-0", 100)
+	fs.Remove("/sys/class/powercap/intel-rapl:0/max_energy_range_uj")
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput().AssertError("without max energy range")
+
+	addZone("intel-rapl:0", "package-0", 100)
+	fs.Remove("/sys/class/powercap/intel-rapl:0/energy_uj")
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.NextOutput().AssertError("without energy")
+
+	setEnergy("intel-rapl:0", 10)
+	testBar.New(t)
+	testBar.Run(New())
+	testBar.AssertNoOutput("until second sample")
+}
@@ -21,6 +21,7 @@ e.g. whoami, date +%s.
 package shell
 
 import (
+	"context"
 	"os/exec"
 	"strings"
 	"time"
@@ -55,20 +56,31 @@ func New(cmd string, args ...string) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
-	out, err := exec.Command(m.cmd, m.args...).Output()
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext kills the command if it is still running when the context is
+// cancelled.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	out, err := exec.CommandContext(ctx, m.cmd, m.args...).Output()
 	outf := m.outf.Get().(func(string) bar.Output)
 	for {
+		if ctx.Err() != nil {
+			return
+		}
 		if s.Error(err) {
 			return
 		}
 		s.Output(outf(strings.TrimSpace(string(out))))
 		select {
+		case <-ctx.Done():
+			return
 		case <-m.outf.Next():
 			outf = m.outf.Get().(func(string) bar.Output)
 		case <-m.notifyCh:
-			out, err = exec.Command(m.cmd, m.args...).Output()
+			out, err = exec.CommandContext(ctx, m.cmd, m.args...).Output()
 		case <-m.scheduler.C:
-			out, err = exec.Command(m.cmd, m.args...).Output()
+			out, err = exec.CommandContext(ctx, m.cmd, m.args...).Output()
 		}
 	}
 }
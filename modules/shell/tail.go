@@ -16,6 +16,7 @@ package shell
 
 import (
 	"bufio"
+	"context"
 	"os/exec"
 	"syscall"
 
@@ -44,6 +45,12 @@ func Tail(cmd string, args ...string) *TailModule {
 
 // Stream starts the module.
 func (m *TailModule) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext is like Stream, but kills the command and returns when the
+// context is cancelled.
+func (m *TailModule) StreamContext(ctx context.Context, s bar.Sink) {
 	cmd := exec.Command(m.cmd, m.args...)
 	// Prevent SIGUSR for bar pause/resume from propagating to the
 	// child process. Some commands don't play nice with signals.
@@ -71,6 +78,17 @@ func (m *TailModule) Stream(s bar.Sink) {
 	}()
 	for {
 		select {
+		case <-ctx.Done():
+			// The command runs in its own process group, so kill the whole
+			// group, then wait for the reader to finish so it does not leak.
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			for {
+				select {
+				case <-outChan:
+				case <-errChan:
+					return
+				}
+			}
 		case e := <-errChan:
 			s.Error(e)
 			return
@@ -15,11 +15,20 @@
 package shell
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/sink"
 	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestTail(t *testing.T) {
@@ -59,3 +68,44 @@ func TestTailInvalidCommand(t *testing.T) {
 	testBar.NextOutput().AssertError(
 		"when starting an invalid command")
 }
+
+func TestTailContext(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	tail := Tail("bash", "-c", "sleep 100 & echo $! > "+pidFile+"; echo started; wait")
+	ch, s := sink.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	go func() {
+		tail.StreamContext(ctx, s)
+		close(returned)
+	}()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		require.Fail(t, "Expected output from command")
+	}
+	pidBytes, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "StreamContext did not return on cancellation")
+	}
+	require.Eventually(t, func() bool { return !running(pid) },
+		time.Second, 10*time.Millisecond, "child processes are killed")
+}
+
+// running returns true if the process exists and is not a zombie, since the
+// killed process may not be reaped if it was reparented.
+func running(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
@@ -16,6 +16,7 @@
 package sysinfo
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -99,6 +100,11 @@ func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
 
 // Stream subscribes to sysinfo and updates the module's output.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	i, err := currentInfo.Get()
 	nextInfo, done := currentInfo.Subscribe()
 	defer done()
@@ -112,6 +118,8 @@ func (m *Module) Stream(s bar.Sink) {
 			s.Output(outputFunc(info))
 		}
 		select {
+		case <-ctx.Done():
+			return
 		case <-nextOutputFunc:
 			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
 		case <-nextInfo:
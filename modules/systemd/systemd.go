@@ -16,6 +16,7 @@
 package systemd
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -146,6 +147,11 @@ const serviceIface = "org.freedesktop.systemd1.Service"
 
 // Stream starts the module.
 func (s *ServiceModule) Stream(sink bar.Sink) {
+	s.StreamContext(context.Background(), sink)
+}
+
+// StreamContext implements bar.ContextModule.
+func (s *ServiceModule) StreamContext(ctx context.Context, sink bar.Sink) {
 	w := watchUnit(s.name+".service", s.busType)
 	defer w.Unsubscribe()
 
@@ -163,6 +169,8 @@ func (s *ServiceModule) Stream(sink bar.Sink) {
 	for {
 		sink.Output(outputFunc(info))
 		select {
+		case <-ctx.Done():
+			return
 		case <-w.Updates:
 			info = getServiceInfo(w)
 		case <-nextOutputFunc:
@@ -270,6 +278,11 @@ const timerIface = "org.freedesktop.systemd1.Timer"
 
 // Stream starts the module.
 func (t *TimerModule) Stream(sink bar.Sink) {
+	t.StreamContext(context.Background(), sink)
+}
+
+// StreamContext implements bar.ContextModule.
+func (t *TimerModule) StreamContext(ctx context.Context, sink bar.Sink) {
 	w := watchUnit(t.name+".timer", t.busType)
 	defer w.Unsubscribe()
 
@@ -287,6 +300,8 @@ func (t *TimerModule) Stream(sink bar.Sink) {
 	for {
 		sink.Output(outputFunc(info))
 		select {
+		case <-ctx.Done():
+			return
 		case <-w.Updates:
 			info = getTimerInfo(w)
 		case <-nextOutputFunc:
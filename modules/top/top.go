@@ -0,0 +1,332 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package top provides an i3bar module that shows the processes using the most
+// CPU and memory, based on /proc/<pid>/stat and /proc/<pid>/status.
+package top
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/user"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+// Process represents a single running process.
+type Process struct {
+	PID  int
+	Comm string
+	// Cmdline is the full command line, with arguments separated by spaces.
+	// It is empty for kernel threads.
+	Cmdline string
+	User    string
+	// CPU is the share of total CPU time (across all cores) used by the
+	// process since the previous sample, in the range [0, 1].
+	CPU float64
+	// RSS is the resident memory used by the process.
+	RSS unit.Datasize
+}
+
+// Signal sends a signal to the process.
+func (p Process) Signal(sig syscall.Signal) error {
+	return kill(p.PID, sig)
+}
+
+// Terminate asks the process to exit, by sending it SIGTERM.
+func (p Process) Terminate() error {
+	return p.Signal(unix.SIGTERM)
+}
+
+// Kill kills the process, by sending it SIGKILL.
+func (p Process) Kill() error {
+	return p.Signal(unix.SIGKILL)
+}
+
+// Info contains the top processes from the most recent sample.
+type Info struct {
+	// ByCPU contains the processes using the most CPU, highest first.
+	ByCPU []Process
+	// ByMemory contains the processes using the most memory, highest first.
+	ByMemory []Process
+}
+
+// Module represents a bar.Module that shows the top processes.
+type Module struct {
+	count      int
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(Info) bar.Output
+}
+
+// New constructs a top module that shows the top count processes. It panics if
+// count is negative.
+func New(count int) *Module {
+	if count < 0 {
+		panic(fmt.Sprintf("top: count must not be negative, got %d", count))
+	}
+	m := &Module{count: count, scheduler: timing.NewScheduler()}
+	l.Label(m, strconv.Itoa(count))
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(3 * time.Second)
+	m.Output(func(i Info) bar.Output {
+		if len(i.ByCPU) == 0 {
+			return nil
+		}
+		p := i.ByCPU[0]
+		return outputs.Textf("%s %.0f%%", p.Comm, p.CPU*100)
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency. CPU usage is computed
+// over this interval.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Stream starts the module.
+func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+	smp := new(sampler)
+	// CPU usage needs two samples, so the first sample only records the
+	// current counters.
+	_, err := smp.sample()
+	if s.Error(err) {
+		return
+	}
+	var info Info
+	started := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			procs, err := smp.sample()
+			if s.Error(err) {
+				return
+			}
+			info = topN(procs, m.count)
+			started = true
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		}
+		if started {
+			s.Output(outputFunc(info))
+		}
+	}
+}
+
+func topN(procs []Process, count int) Info {
+	byCPU := make([]Process, len(procs))
+	copy(byCPU, procs)
+	sort.SliceStable(byCPU, func(i, j int) bool { return byCPU[i].CPU > byCPU[j].CPU })
+	byMem := make([]Process, len(procs))
+	copy(byMem, procs)
+	sort.SliceStable(byMem, func(i, j int) bool { return byMem[i].RSS > byMem[j].RSS })
+	if count < len(procs) {
+		byCPU = byCPU[:count]
+		byMem = byMem[:count]
+	}
+	return Info{ByCPU: byCPU, ByMemory: byMem}
+}
+
+var fs = afero.NewOsFs()
+
+// To allow tests to mock out signals and user lookups.
+var kill = unix.Kill
+var lookupUser = func(uid string) (string, error) {
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+// sampler keeps the CPU counters from the previous sample, to compute the
+// CPU share of each process.
+type sampler struct {
+	total uint64
+	ticks map[int]uint64
+	users map[string]string
+}
+
+func (s *sampler) sample() ([]Process, error) {
+	total, err := totalTicks()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := afero.ReadDir(fs, "/proc")
+	if err != nil {
+		return nil, err
+	}
+	elapsed := float64(total - s.total)
+	ticks := map[int]uint64{}
+	var procs []Process
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		p, t, err := s.readProcess(pid)
+		if err != nil {
+			// The process probably exited while reading it.
+			continue
+		}
+		ticks[pid] = t
+		// Processes that were not in the previous sample started since then,
+		// so all of their CPU time is within this sample.
+		if prev := s.ticks[pid]; s.ticks != nil && t >= prev && elapsed > 0 {
+			p.CPU = float64(t-prev) / elapsed
+		}
+		procs = append(procs, p)
+	}
+	s.total = total
+	s.ticks = ticks
+	return procs, nil
+}
+
+// totalTicks returns the total CPU time across all cores from /proc/stat.
+func totalTicks() (uint64, error) {
+	f, err := fs.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+		var total uint64
+		// user nice system idle iowait irq softirq steal; guest time is
+		// already included in user time.
+		for i := 1; i < len(fields) && i <= 8; i++ {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			total += v
+		}
+		return total, nil
+	}
+	return 0, errors.New("no cpu line in /proc/stat")
+}
+
+// readProcess reads a process's information and its cumulative CPU time.
+func (s *sampler) readProcess(pid int) (p Process, ticks uint64, err error) {
+	dir := path.Join("/proc", strconv.Itoa(pid))
+	p.PID = pid
+	stat, err := afero.ReadFile(fs, path.Join(dir, "stat"))
+	if err != nil {
+		return p, 0, err
+	}
+	// The comm field is in parentheses, and may itself contain spaces and
+	// parentheses, so split on the last ')'.
+	str := string(stat)
+	start, end := strings.Index(str, "("), strings.LastIndex(str, ")")
+	if start < 0 || end < start {
+		return p, 0, errors.New("malformed stat")
+	}
+	p.Comm = str[start+1 : end]
+	// Fields after comm, starting with state (field 3). utime and stime are
+	// fields 14 and 15.
+	fields := strings.Fields(str[end+1:])
+	if len(fields) < 13 {
+		return p, 0, errors.New("malformed stat")
+	}
+	for _, f := range fields[11:13] {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return p, 0, err
+		}
+		ticks += v
+	}
+	status, err := fs.Open(path.Join(dir, "status"))
+	if err != nil {
+		return p, 0, err
+	}
+	defer status.Close()
+	sc := bufio.NewScanner(status)
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(val)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			p.User = s.userName(fields[0])
+		case "VmRSS":
+			kb, err := strconv.ParseUint(fields[0], 10, 64)
+			if err == nil {
+				p.RSS = unit.Datasize(kb) * unit.Kibibyte
+			}
+		}
+	}
+	if cmdline, err := afero.ReadFile(fs, path.Join(dir, "cmdline")); err == nil {
+		p.Cmdline = strings.ReplaceAll(strings.TrimRight(string(cmdline), "\x00"), "\x00", " ")
+	}
+	return p, ticks, nil
+}
+
+// userName returns the name of the user with the given uid, or the uid
+// itself if the user cannot be found.
+func (s *sampler) userName(uid string) string {
+	if name, ok := s.users[uid]; ok {
+		return name
+	}
+	if s.users == nil {
+		s.users = map[string]string{}
+	}
+	name, err := lookupUser(uid)
+	if err != nil {
+		name = uid
+	}
+	s.users[uid] = name
+	return name
+}
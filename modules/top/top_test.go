@@ -0,0 +1,204 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package top
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func setTotal(ticks uint64) {
+	afero.WriteFile(fs, "/proc/stat", []byte(fmt.Sprintf(
+		"cpu  %d 0 0 0 0 0 0 0 0 0\ncpu0 %d 0 0 0 0 0 0 0 0 0\n", ticks, ticks)), 0444)
+}
+
+func setProcess(pid int, comm, cmdline string, uid int, ticks uint64, rssKb int) {
+	dir := fmt.Sprintf("/proc/%d", pid)
+	fs.MkdirAll(dir, 0555)
+	afero.WriteFile(fs, dir+"/stat", []byte(fmt.Sprintf(
+		"%d (%s) S 1 1 1 0 -1 4194560 100 0 0 0 %d %d 0 0 20 0 1 0 1000 10000 %d\n",
+		pid, comm, ticks/2, ticks-ticks/2, rssKb/4)), 0444)
+	afero.WriteFile(fs, dir+"/status", []byte(fmt.Sprintf(
+		"Name:\t%s\nUid:\t%d\t%d\t%d\t%d\nVmRSS:\t%d kB\n",
+		comm, uid, uid, uid, uid, rssKb)), 0444)
+	afero.WriteFile(fs, dir+"/cmdline", []byte(cmdline), 0444)
+}
+
+func init() {
+	lookupUser = func(uid string) (string, error) {
+		switch uid {
+		case "0":
+			return "root", nil
+		case "1000":
+			return "user", nil
+		}
+		return "", errors.New("unknown user")
+	}
+}
+
+func setup() {
+	fs = afero.NewMemMapFs()
+}
+
+func names(procs []Process) string {
+	var out []string
+	for _, p := range procs {
+		out = append(out, fmt.Sprintf("%s:%.0f", p.Comm, p.CPU*100))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestTop(t *testing.T) {
+	setup()
+	setTotal(1000)
+	setProcess(1, "init", "/sbin/init\x00splash\x00", 0, 100, 4096)
+	setProcess(42, "firefox", "/usr/bin/firefox\x00", 1000, 200, 1024000)
+	setProcess(99, "(sd-pam)", "", 1000, 0, 2048)
+	setProcess(120, "kworker/0:1", "", 0, 50, 0)
+	fs.MkdirAll("/proc/self", 0555)
+	fs.MkdirAll("/proc/sys", 0555)
+	afero.WriteFile(fs, "/proc/uptime", []byte("1.0 1.0"), 0444)
+
+	testBar.New(t)
+	infos := make(chan Info, 10)
+	top := New(2)
+	full := New(10).Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Text(names(i.ByCPU) + " " + names(i.ByMemory))
+	})
+	testBar.Run(top, full)
+	testBar.AssertNoOutput("until second sample")
+
+	setTotal(2000)
+	setProcess(1, "init", "/sbin/init\x00splash\x00", 0, 110, 4096)
+	setProcess(42, "firefox", "/usr/bin/firefox\x00", 1000, 600, 1024000)
+	setProcess(99, "(sd-pam)", "", 1000, 0, 2048)
+	setProcess(120, "kworker/0:1", "", 0, 100, 0)
+	testBar.Tick()
+	testBar.LatestOutput().AssertText([]string{
+		"firefox 40%",
+		"firefox:40,kworker/0:1:5,init:1,(sd-pam):0 firefox:40,init:1,(sd-pam):0,kworker/0:1:5",
+	}, "on tick")
+	info := <-infos
+	require.Equal(t, Process{
+		PID:     42,
+		Comm:    "firefox",
+		Cmdline: "/usr/bin/firefox",
+		User:    "user",
+		CPU:     0.4,
+		RSS:     1000 * unit.Mebibyte,
+	}, info.ByCPU[0])
+	require.Equal(t, "/sbin/init splash", info.ByMemory[1].Cmdline)
+	require.Equal(t, "root", info.ByMemory[1].User)
+	require.Equal(t, "(sd-pam)", info.ByMemory[2].Comm, "comm with parentheses")
+
+	top.Output(func(i Info) bar.Output {
+		return outputs.Textf("%d/%d", len(i.ByCPU), len(i.ByMemory))
+	})
+	testBar.NextOutput("on output change").At(0).AssertText("2/2")
+
+	setTotal(3000)
+	fs.RemoveAll("/proc/42")
+	setProcess(200, "make", "make\x00-j8\x00", 1234, 300, 8192)
+	testBar.Tick()
+	testBar.LatestOutput().At(1).AssertText(
+		"make:30,init:0,kworker/0:1:0,(sd-pam):0 make:30,init:0,(sd-pam):0,kworker/0:1:0",
+		"new process shows cpu since start, exited process is removed")
+	info = <-infos
+	require.Equal(t, "1234", info.ByCPU[0].User, "falls back to uid")
+
+	beforeTick := timing.Now()
+	top.RefreshInterval(time.Minute)
+	full.RefreshInterval(time.Minute)
+	testBar.Tick()
+	require.Equal(t, time.Minute, timing.Now().Sub(beforeTick), "RefreshInterval change")
+}
+
+func TestNegativeCount(t *testing.T) {
+	require.Panics(t, func() { New(-1) })
+	require.NotPanics(t, func() { New(0) })
+}
+
+func TestSignals(t *testing.T) {
+	type signal struct {
+		pid int
+		sig syscall.Signal
+	}
+	signals := make(chan signal, 10)
+	kill = func(pid int, sig syscall.Signal) error {
+		signals <- signal{pid, sig}
+		if pid == 1 {
+			return errors.New("operation not permitted")
+		}
+		return nil
+	}
+
+	p := Process{PID: 42}
+	require.NoError(t, p.Terminate())
+	require.Equal(t, signal{42, syscall.SIGTERM}, <-signals)
+	require.NoError(t, p.Kill())
+	require.Equal(t, signal{42, syscall.SIGKILL}, <-signals)
+	require.NoError(t, p.Signal(syscall.SIGSTOP))
+	require.Equal(t, signal{42, syscall.SIGSTOP}, <-signals)
+	require.Error(t, Process{PID: 1}.Kill())
+}
+
+func TestErrors(t *testing.T) {
+	setup()
+	testBar.New(t)
+	testBar.Run(New(3))
+	testBar.NextOutput().AssertError("on start without /proc/stat")
+
+	afero.WriteFile(fs, "/proc/stat", []byte("intr 1 2 3\n"), 0444)
+	testBar.New(t)
+	testBar.Run(New(3))
+	testBar.NextOutput().AssertError("without cpu line")
+
+	afero.WriteFile(fs, "/proc/stat", []byte("cpu 1 2 x\n"), 0444)
+	testBar.New(t)
+	testBar.Run(New(3))
+	testBar.NextOutput().AssertError("with non-numeric value")
+
+	setTotal(100)
+	setProcess(1, "init", "", 0, 10, 100)
+	afero.WriteFile(fs, "/proc/2/stat", []byte("2 init S 1 2 3"), 0444)
+	afero.WriteFile(fs, "/proc/3/stat", []byte("3 (init) S 1 2 3"), 0444)
+	afero.WriteFile(fs, "/proc/4/stat", []byte("4 (init) S 1 1 1 0 -1 0 0 0 0 0 x y 0"), 0444)
+	afero.WriteFile(fs, "/proc/5/stat", []byte("5 (init) S 1 1 1 0 -1 0 0 0 0 0 1 1 0"), 0444)
+	testBar.New(t)
+	testBar.Run(New(3).Output(func(i Info) bar.Output {
+		return outputs.Text(names(i.ByCPU))
+	}))
+	setTotal(200)
+	testBar.Tick()
+	testBar.NextOutput().AssertText([]string{"init:0"}, "skips invalid processes")
+
+	fs.Remove("/proc/stat")
+	testBar.Tick()
+	testBar.NextOutput().AssertError("when /proc/stat is removed")
+}
@@ -15,6 +15,7 @@
 package pulseaudio
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -155,6 +156,10 @@ func makeVolume(channelVolumes proto.ChannelVolumes, mute bool, controller volum
 }
 
 func (m *paModule) Worker(s *value.ErrorValue) {
+	m.WorkerContext(context.Background(), s)
+}
+
+func (m *paModule) WorkerContext(ctx context.Context, s *value.ErrorValue) {
 	client, conn, err := proto.Connect("")
 	if s.Error(err) {
 		return
@@ -209,6 +214,10 @@ func (m *paModule) Worker(s *value.ErrorValue) {
 				return
 			}
 		}
-		<-ch
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
@@ -17,6 +17,7 @@
 package volume
 
 import (
+	"context"
 	"time"
 
 	"github.com/soumya92/barista/bar"
@@ -101,6 +102,15 @@ type Provider interface {
 	Worker(s *value.ErrorValue)
 }
 
+// ContextProvider is a Provider whose worker can be stopped. Modules using a
+// Provider that does not implement ContextProvider leave the worker running
+// when they are cancelled.
+type ContextProvider interface {
+	Provider
+	// WorkerContext is like Worker, but returns when the context is cancelled.
+	WorkerContext(ctx context.Context, s *value.ErrorValue)
+}
+
 // Module represents a bar.Module that displays volume information.
 type Module struct {
 	outputFunc value.Value // of func(Volume) bar.Output
@@ -144,12 +154,24 @@ func defaultClickHandler(v Volume) func(bar.Event) {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext also stops the provider's worker when the context is cancelled,
+// if it implements ContextProvider.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	var vol value.ErrorValue
 
 	v, err := vol.Get()
 	nextV, done := vol.Subscribe()
 	defer done()
-	go m.provider.Worker(&vol)
+	if p, ok := m.provider.(ContextProvider); ok {
+		workerCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go p.WorkerContext(workerCtx, &vol)
+	} else {
+		go m.provider.Worker(&vol)
+	}
 
 	outputFunc := m.outputFunc.Get().(func(Volume) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
@@ -165,6 +187,8 @@ func (m *Module) Stream(s bar.Sink) {
 				OnClick(defaultClickHandler(volume)))
 		}
 		select {
+		case <-ctx.Done():
+			return
 		case <-nextV:
 			v, err = vol.Get()
 		case <-nextOutputFunc:
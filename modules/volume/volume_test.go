@@ -15,15 +15,19 @@
 package volume
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/value"
 	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/sink"
 	testBar "github.com/soumya92/barista/testing/bar"
 
+	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 )
 
@@ -34,6 +38,7 @@ type testVolumeProvider struct {
 	mute          bool
 	volChan       chan int64
 	muteChan      chan bool
+	stopped       chan struct{}
 }
 
 func (t *testVolumeProvider) SetVolume(vol int64) error {
@@ -63,6 +68,10 @@ func (t *testVolumeProvider) setError(e error) {
 }
 
 func (t *testVolumeProvider) Worker(v *value.ErrorValue) {
+	t.WorkerContext(context.Background(), v)
+}
+
+func (t *testVolumeProvider) WorkerContext(ctx context.Context, v *value.ErrorValue) {
 	t.Lock()
 	for {
 		v.SetOrError(Volume{
@@ -74,6 +83,11 @@ func (t *testVolumeProvider) Worker(v *value.ErrorValue) {
 		}, t.error)
 		t.Unlock()
 		select {
+		case <-ctx.Done():
+			if t.stopped != nil {
+				close(t.stopped)
+			}
+			return
 		case newVol := <-t.volChan:
 			t.Lock()
 			t.vol = newVol
@@ -183,3 +197,33 @@ func TestModule(t *testing.T) {
 
 	testBar.NextOutput("on error").AssertError()
 }
+
+func TestModuleContext(t *testing.T) {
+	testBar.New(t)
+	testProvider := &testVolumeProvider{
+		min: 0, max: 100, vol: 20,
+		volChan: make(chan int64, 1), muteChan: make(chan bool, 1),
+		stopped: make(chan struct{}),
+	}
+	ch, s := sink.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	go func() {
+		New(testProvider).StreamContext(ctx, s)
+		close(returned)
+	}()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		require.Fail(t, "Expected an output")
+	}
+
+	cancel()
+	for _, c := range []chan struct{}{returned, testProvider.stopped} {
+		select {
+		case <-c:
+		case <-time.After(time.Second):
+			require.Fail(t, "Module and worker did not stop on cancellation")
+		}
+	}
+}
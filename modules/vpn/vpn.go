@@ -12,10 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package vpn provides an i3bar module for openvpn information.
+// Package vpn provides i3bar modules for openvpn and WireGuard information.
 package vpn
 
 import (
+	"context"
+
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/value"
 	"github.com/soumya92/barista/base/watchers/netlink"
@@ -78,6 +80,11 @@ func (m *Module) Output(outputFunc func(State) bar.Output) *Module {
 
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	outputFunc := m.outputFunc.Get().(func(State) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
 	defer done()
@@ -89,6 +96,8 @@ func (m *Module) Stream(s bar.Sink) {
 	for {
 		s.Output(outputFunc(state))
 		select {
+		case <-ctx.Done():
+			return
 		case <-linkSub.C:
 			state = getState(linkSub.Get().State)
 		case <-nextOutputFunc:
@@ -15,12 +15,16 @@
 package vpn
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/watchers/netlink"
 	"github.com/soumya92/barista/outputs"
 	testBar "github.com/soumya92/barista/testing/bar"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestVpn(t *testing.T) {
@@ -65,3 +69,31 @@ func TestVpn(t *testing.T) {
 	nlt.RemoveLink(link)
 	testBar.NextOutput().AssertText([]string{"NO VPN"})
 }
+
+func TestStreamContext(t *testing.T) {
+	nlt := netlink.TestMode()
+	link := nlt.AddLink(netlink.Link{Name: "tun0", State: netlink.Down})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outs := make(chan bar.Output, 10)
+	returned := make(chan struct{})
+	go func() {
+		DefaultInterface().StreamContext(ctx, func(o bar.Output) { outs <- o })
+		close(returned)
+	}()
+	<-outs
+
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		require.Fail(t, "module did not stop on cancellation")
+	}
+
+	nlt.UpdateLink(link, netlink.Link{Name: "tun0", State: netlink.Up})
+	select {
+	case <-outs:
+		require.Fail(t, "unexpected output after cancellation")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
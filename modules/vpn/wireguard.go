@@ -0,0 +1,370 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpn
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// Constants from linux/wireguard.h.
+const (
+	wgCmdGetDevice = 0
+
+	wgDeviceIfname     = 2
+	wgDevicePublicKey  = 4
+	wgDeviceListenPort = 6
+	wgDevicePeers      = 8
+
+	wgPeerPublicKey         = 1
+	wgPeerEndpoint          = 4
+	wgPeerKeepaliveInterval = 5
+	wgPeerLastHandshakeTime = 6
+	wgPeerRxBytes           = 7
+	wgPeerTxBytes           = 8
+	wgPeerAllowedIPs        = 9
+
+	wgAllowedIPFamily   = 1
+	wgAllowedIPAddr     = 2
+	wgAllowedIPCidrMask = 3
+)
+
+// HandshakeTimeout is how long after the latest handshake a peer is still
+// considered connected. WireGuard re-keys every 2 minutes while there is
+// traffic, and rejects sessions older than 3 minutes.
+const HandshakeTimeout = 3 * time.Minute
+
+// Peer represents a WireGuard peer.
+type Peer struct {
+	// PublicKey is the peer's public key, base64 encoded.
+	PublicKey string
+	// Endpoint is the peer's current address, or nil if not known.
+	Endpoint          *net.UDPAddr
+	AllowedIPs        []net.IPNet
+	KeepaliveInterval time.Duration
+	// LastHandshake is the time of the latest handshake, or the zero time
+	// if there has been no handshake with the peer.
+	LastHandshake time.Time
+	Rx, Tx        unit.Datasize
+}
+
+// HandshakeAge returns the time since the latest handshake, or 0 if there has
+// been no handshake with the peer.
+func (p Peer) HandshakeAge() time.Duration {
+	if p.LastHandshake.IsZero() {
+		return 0
+	}
+	return timing.Now().Sub(p.LastHandshake)
+}
+
+// Connected returns true if there has been a recent handshake with the peer.
+func (p Peer) Connected() bool {
+	return !p.LastHandshake.IsZero() && p.HandshakeAge() < HandshakeTimeout
+}
+
+// WireGuardInfo represents the state of a WireGuard interface.
+type WireGuardInfo struct {
+	// Interface is the name of the WireGuard interface, e.g. "wg0". It is
+	// empty if the interface does not exist.
+	Interface string
+	// PublicKey is the interface's public key, base64 encoded.
+	PublicKey  string
+	ListenPort int
+	Peers      []Peer
+}
+
+// State returns the state of the tunnel. A tunnel is connected if it has
+// recently completed a handshake with any of its peers, and waiting if it
+// exists but has not.
+func (i WireGuardInfo) State() State {
+	if i.Interface == "" {
+		return Disconnected
+	}
+	for _, p := range i.Peers {
+		if p.Connected() {
+			return Connected
+		}
+	}
+	return Waiting
+}
+
+// WireGuardModule represents a WireGuard bar module.
+type WireGuardModule struct {
+	intf       string
+	scheduler  *timing.Scheduler
+	outputFunc value.Value // of func(WireGuardInfo) bar.Output
+}
+
+// WireGuard constructs a module for the named WireGuard interface. Reading the
+// state of a WireGuard interface requires the CAP_NET_ADMIN capability.
+func WireGuard(iface string) *WireGuardModule {
+	m := &WireGuardModule{intf: iface, scheduler: timing.NewScheduler()}
+	l.Label(m, iface)
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(5 * time.Second)
+	// Default output is 'WG' when connected, and an urgent 'WG' if the tunnel
+	// exists but no handshake has completed recently.
+	m.Output(func(i WireGuardInfo) bar.Output {
+		switch i.State() {
+		case Connected:
+			return outputs.Text("WG")
+		case Waiting:
+			return outputs.Text("WG").Urgent(true)
+		}
+		return nil
+	})
+	return m
+}
+
+// Output configures a module to display the output of a user-defined function.
+func (m *WireGuardModule) Output(outputFunc func(WireGuardInfo) bar.Output) *WireGuardModule {
+	m.outputFunc.Set(outputFunc)
+	return m
+}
+
+// RefreshInterval configures the polling frequency for the tunnel state.
+func (m *WireGuardModule) RefreshInterval(interval time.Duration) *WireGuardModule {
+	m.scheduler.Every(interval)
+	return m
+}
+
+// Stream starts the module.
+func (m *WireGuardModule) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *WireGuardModule) StreamContext(ctx context.Context, s bar.Sink) {
+	outputFunc := m.outputFunc.Get().(func(WireGuardInfo) bar.Output)
+	nextOutputFunc, done := m.outputFunc.Subscribe()
+	defer done()
+
+	info, err := getWireGuardInfo(m.intf)
+	for {
+		if s.Error(err) {
+			return
+		}
+		s.Output(outputFunc(info))
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.scheduler.C:
+			info, err = getWireGuardInfo(m.intf)
+		case <-nextOutputFunc:
+			outputFunc = m.outputFunc.Get().(func(WireGuardInfo) bar.Output)
+		}
+	}
+}
+
+// To allow tests to mock out the WireGuard netlink family.
+var wgGetDevice = func(iface string) ([][]byte, error) {
+	family, err := netlink.GenlFamilyGet("wireguard")
+	if err != nil {
+		return nil, err
+	}
+	req := nl.NewNetlinkRequest(int(family.ID), unix.NLM_F_DUMP)
+	req.AddData(&nl.Genlmsg{Command: wgCmdGetDevice, Version: 1})
+	req.AddData(nl.NewRtAttr(wgDeviceIfname, nl.ZeroTerminated(iface)))
+	return req.Execute(unix.NETLINK_GENERIC, 0)
+}
+
+var native = nl.NativeEndian()
+
+func getWireGuardInfo(iface string) (WireGuardInfo, error) {
+	var info WireGuardInfo
+	msgs, err := wgGetDevice(iface)
+	if errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.ENOENT) {
+		// The interface (or the wireguard kernel module) does not exist,
+		// which just means the tunnel is down.
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+	// Devices with many peers are split across multiple messages, each with
+	// the device attributes and some of the peers. A peer with many allowed
+	// IPs can also be split, repeating its public key in the next message
+	// with the remaining allowed IPs, so peers are merged by public key.
+	peerIdx := map[string]int{}
+	for _, msg := range msgs {
+		if len(msg) < nl.SizeofGenlmsg {
+			continue
+		}
+		attrs, err := nl.ParseRouteAttr(msg[nl.SizeofGenlmsg:])
+		if err != nil {
+			return info, err
+		}
+		for _, a := range attrs {
+			switch attrType(a) {
+			case wgDeviceIfname:
+				info.Interface = nl.BytesToString(a.Value)
+			case wgDevicePublicKey:
+				info.PublicKey = base64.StdEncoding.EncodeToString(a.Value)
+			case wgDeviceListenPort:
+				if len(a.Value) < 2 {
+					continue
+				}
+				info.ListenPort = int(native.Uint16(a.Value))
+			case wgDevicePeers:
+				peers, err := nl.ParseRouteAttr(a.Value)
+				if err != nil {
+					return info, err
+				}
+				for _, p := range peers {
+					peer, err := parsePeer(p.Value)
+					if err != nil {
+						return info, err
+					}
+					if i, ok := peerIdx[peer.PublicKey]; ok {
+						info.Peers[i].AllowedIPs = append(
+							info.Peers[i].AllowedIPs, peer.AllowedIPs...)
+						continue
+					}
+					peerIdx[peer.PublicKey] = len(info.Peers)
+					info.Peers = append(info.Peers, peer)
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+func attrType(a syscall.NetlinkRouteAttr) uint16 {
+	return a.Attr.Type &^ (unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+}
+
+func parsePeer(data []byte) (Peer, error) {
+	var p Peer
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return p, err
+	}
+	for _, a := range attrs {
+		switch attrType(a) {
+		case wgPeerPublicKey:
+			p.PublicKey = base64.StdEncoding.EncodeToString(a.Value)
+		case wgPeerEndpoint:
+			p.Endpoint = parseSockaddr(a.Value)
+		case wgPeerKeepaliveInterval:
+			if len(a.Value) < 2 {
+				continue
+			}
+			p.KeepaliveInterval = time.Duration(native.Uint16(a.Value)) * time.Second
+		case wgPeerLastHandshakeTime:
+			// struct __kernel_timespec, with 64-bit seconds and nanoseconds.
+			if len(a.Value) < 16 {
+				continue
+			}
+			sec := int64(native.Uint64(a.Value[0:8]))
+			nsec := int64(native.Uint64(a.Value[8:16]))
+			if sec != 0 || nsec != 0 {
+				p.LastHandshake = time.Unix(sec, nsec)
+			}
+		case wgPeerRxBytes:
+			if len(a.Value) < 8 {
+				continue
+			}
+			p.Rx = unit.Datasize(native.Uint64(a.Value)) * unit.Byte
+		case wgPeerTxBytes:
+			if len(a.Value) < 8 {
+				continue
+			}
+			p.Tx = unit.Datasize(native.Uint64(a.Value)) * unit.Byte
+		case wgPeerAllowedIPs:
+			ips, err := nl.ParseRouteAttr(a.Value)
+			if err != nil {
+				return p, err
+			}
+			for _, ip := range ips {
+				if n, ok := parseAllowedIP(ip.Value); ok {
+					p.AllowedIPs = append(p.AllowedIPs, n)
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+func parseAllowedIP(data []byte) (net.IPNet, bool) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return net.IPNet{}, false
+	}
+	var ip net.IP
+	var bits, ones int
+	for _, a := range attrs {
+		switch attrType(a) {
+		case wgAllowedIPFamily:
+			if len(a.Value) < 2 {
+				continue
+			}
+			switch native.Uint16(a.Value) {
+			case unix.AF_INET:
+				bits = 32
+			case unix.AF_INET6:
+				bits = 128
+			}
+		case wgAllowedIPAddr:
+			ip = net.IP(a.Value)
+		case wgAllowedIPCidrMask:
+			if len(a.Value) < 1 {
+				continue
+			}
+			ones = int(a.Value[0])
+		}
+	}
+	if ip == nil || bits == 0 {
+		return net.IPNet{}, false
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(ones, bits)}, true
+}
+
+// parseSockaddr parses a struct sockaddr_in or sockaddr_in6.
+func parseSockaddr(b []byte) *net.UDPAddr {
+	if len(b) < 4 {
+		return nil
+	}
+	// The port is in network byte order, the family is not.
+	port := int(binary.BigEndian.Uint16(b[2:4]))
+	switch native.Uint16(b[0:2]) {
+	case unix.AF_INET:
+		if len(b) < 8 {
+			return nil
+		}
+		return &net.UDPAddr{IP: net.IP(b[4:8]), Port: port}
+	case unix.AF_INET6:
+		if len(b) < 24 {
+			return nil
+		}
+		return &net.UDPAddr{IP: net.IP(b[8:24]), Port: port}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpn
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/outputs"
+	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+type testPeer struct {
+	key       byte
+	ip        net.IP
+	port      uint16
+	handshake time.Time
+	rx, tx    uint64
+	allowed   []string
+	// continued peers only include the public key and allowed IPs, like the
+	// kernel does when a peer is split across messages.
+	continued bool
+}
+
+var (
+	wgMu     sync.Mutex
+	wgPeers  map[string][]testPeer
+	wgErr    error
+	wgPubKey = make([]byte, 32)
+)
+
+func wgShouldReturn(iface string, peers ...testPeer) {
+	wgMu.Lock()
+	defer wgMu.Unlock()
+	if wgPeers == nil {
+		wgPeers = map[string][]testPeer{}
+	}
+	wgPeers[iface] = peers
+}
+
+func wgShouldError(err error) {
+	wgMu.Lock()
+	defer wgMu.Unlock()
+	wgErr = err
+}
+
+func sockaddr(ip net.IP, port uint16) []byte {
+	var b []byte
+	if ip4 := ip.To4(); ip4 != nil {
+		b = make([]byte, 16)
+		native.PutUint16(b[0:2], unix.AF_INET)
+		copy(b[4:8], ip4)
+	} else {
+		b = make([]byte, 28)
+		native.PutUint16(b[0:2], unix.AF_INET6)
+		copy(b[8:24], ip)
+	}
+	binary.BigEndian.PutUint16(b[2:4], port)
+	return b
+}
+
+func peerAttr(p testPeer) *nl.RtAttr {
+	attr := nl.NewRtAttr(unix.NLA_F_NESTED, nil)
+	key := make([]byte, 32)
+	key[0] = p.key
+	attr.AddRtAttr(wgPeerPublicKey, key)
+	if p.continued {
+		addAllowedIPs(attr, p.allowed)
+		return attr
+	}
+	if p.ip != nil {
+		attr.AddRtAttr(wgPeerEndpoint, sockaddr(p.ip, p.port))
+	}
+	ts := make([]byte, 16)
+	if !p.handshake.IsZero() {
+		native.PutUint64(ts[0:8], uint64(p.handshake.Unix()))
+		native.PutUint64(ts[8:16], uint64(p.handshake.Nanosecond()))
+	}
+	attr.AddRtAttr(wgPeerLastHandshakeTime, ts)
+	attr.AddRtAttr(wgPeerRxBytes, nl.Uint64Attr(p.rx))
+	attr.AddRtAttr(wgPeerTxBytes, nl.Uint64Attr(p.tx))
+	addAllowedIPs(attr, p.allowed)
+	return attr
+}
+
+func addAllowedIPs(attr *nl.RtAttr, allowed []string) {
+	ips := attr.AddRtAttr(wgPeerAllowedIPs|unix.NLA_F_NESTED, nil)
+	for _, a := range allowed {
+		_, n, _ := net.ParseCIDR(a)
+		ones, bits := n.Mask.Size()
+		ip := ips.AddRtAttr(unix.NLA_F_NESTED, nil)
+		if bits == 32 {
+			ip.AddRtAttr(wgAllowedIPFamily, nl.Uint16Attr(unix.AF_INET))
+			ip.AddRtAttr(wgAllowedIPAddr, n.IP.To4())
+		} else {
+			ip.AddRtAttr(wgAllowedIPFamily, nl.Uint16Attr(unix.AF_INET6))
+			ip.AddRtAttr(wgAllowedIPAddr, n.IP.To16())
+		}
+		ip.AddRtAttr(wgAllowedIPCidrMask, []byte{byte(ones)})
+	}
+}
+
+// mockGetDevice returns one message per peer, to exercise merging of
+// multipart device dumps.
+func mockGetDevice(iface string) ([][]byte, error) {
+	wgMu.Lock()
+	defer wgMu.Unlock()
+	if wgErr != nil {
+		return nil, wgErr
+	}
+	peers, ok := wgPeers[iface]
+	if !ok {
+		return nil, syscall.ENODEV
+	}
+	var msgs [][]byte
+	for i := 0; i == 0 || i < len(peers); i++ {
+		msg := (&nl.Genlmsg{Command: wgCmdGetDevice, Version: 1}).Serialize()
+		for _, a := range []*nl.RtAttr{
+			nl.NewRtAttr(wgDeviceIfname, nl.ZeroTerminated(iface)),
+			nl.NewRtAttr(wgDevicePublicKey, wgPubKey),
+			nl.NewRtAttr(wgDeviceListenPort, nl.Uint16Attr(51820)),
+		} {
+			msg = append(msg, a.Serialize()...)
+		}
+		if i < len(peers) {
+			list := nl.NewRtAttr(wgDevicePeers|unix.NLA_F_NESTED, nil)
+			list.AddChild(peerAttr(peers[i]))
+			msg = append(msg, list.Serialize()...)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func init() {
+	wgGetDevice = mockGetDevice
+}
+
+func TestWireGuard(t *testing.T) {
+	testBar.New(t)
+	wgShouldError(nil)
+	wg := WireGuard("wg0")
+	testBar.Run(wg)
+	testBar.NextOutput("no interface").AssertEmpty()
+
+	wgShouldReturn("wg0", testPeer{key: 1})
+	testBar.Tick()
+	out := testBar.NextOutput("no handshake")
+	out.AssertText([]string{"WG"})
+	urgent, _ := out.At(0).Segment().IsUrgent()
+	require.True(t, urgent, "urgent without handshake")
+
+	now := timing.Now()
+	wgShouldReturn("wg0",
+		testPeer{key: 1},
+		testPeer{key: 2, ip: net.ParseIP("192.0.2.1"), port: 51820, handshake: now.Add(-time.Minute)},
+	)
+	testBar.Tick()
+	out = testBar.NextOutput("recent handshake")
+	out.AssertText([]string{"WG"})
+	urgent, _ = out.At(0).Segment().IsUrgent()
+	require.False(t, urgent, "not urgent with recent handshake")
+
+	infos := make(chan WireGuardInfo, 10)
+	wg.Output(func(i WireGuardInfo) bar.Output {
+		infos <- i
+		if i.State() == Disconnected {
+			return nil
+		}
+		return outputs.Textf("%s:%d", i.Interface, len(i.Peers))
+	})
+	testBar.NextOutput("output func changed").AssertText([]string{"wg0:2"})
+	i := <-infos
+	require.Equal(t, Connected, i.State())
+	require.Equal(t, 51820, i.ListenPort)
+	require.Equal(t, base64.StdEncoding.EncodeToString(wgPubKey), i.PublicKey)
+	require.False(t, i.Peers[0].Connected())
+	require.Equal(t, time.Duration(0), i.Peers[0].HandshakeAge())
+	require.Nil(t, i.Peers[0].Endpoint)
+	require.True(t, i.Peers[1].Connected())
+	require.Equal(t, "192.0.2.1:51820", i.Peers[1].Endpoint.String())
+
+	wgShouldReturn("wg0")
+	testBar.Tick()
+	testBar.NextOutput("no peers").AssertText([]string{"wg0:0"})
+	require.Equal(t, Waiting, (<-infos).State())
+
+	wgShouldError(syscall.EPERM)
+	testBar.Tick()
+	testBar.NextOutput("error").AssertError()
+
+	wgShouldError(nil)
+	wgMu.Lock()
+	delete(wgPeers, "wg0")
+	wgMu.Unlock()
+	testBar.NextOutput("restarted").At(0).LeftClick()
+	testBar.NextOutput("restarted").AssertEmpty()
+}
+
+func TestWireGuardPeers(t *testing.T) {
+	testBar.New(t)
+	wgShouldError(nil)
+	now := timing.Now()
+	wgShouldReturn("wg1",
+		testPeer{
+			key: 3, ip: net.ParseIP("2001:db8::1"), port: 1234,
+			handshake: now.Add(-30 * time.Second),
+			rx:        4096, tx: 1024,
+			allowed: []string{"10.0.0.0/8", "fd00::/64"},
+		},
+	)
+	infos := make(chan WireGuardInfo, 10)
+	wg := WireGuard("wg1").Output(func(i WireGuardInfo) bar.Output {
+		infos <- i
+		return outputs.Textf("%d peers", len(i.Peers))
+	})
+	testBar.Run(wg)
+	testBar.LatestOutput()
+
+	p := (<-infos).Peers[0]
+	key := make([]byte, 32)
+	key[0] = 3
+	require.Equal(t, base64.StdEncoding.EncodeToString(key), p.PublicKey)
+	require.Equal(t, "[2001:db8::1]:1234", p.Endpoint.String())
+	require.Equal(t, 30*time.Second, p.HandshakeAge())
+	require.InDelta(t, 4.0, p.Rx.Kibibytes(), 1e-9)
+	require.InDelta(t, 1.0, p.Tx.Kibibytes(), 1e-9)
+	require.Len(t, p.AllowedIPs, 2)
+	require.Equal(t, "10.0.0.0/8", p.AllowedIPs[0].String())
+	require.Equal(t, "fd00::/64", p.AllowedIPs[1].String())
+
+	// A handshake that is too old means the tunnel is dead.
+	timing.AdvanceBy(HandshakeTimeout)
+	require.False(t, p.Connected())
+	require.Equal(t, HandshakeTimeout+30*time.Second, p.HandshakeAge())
+}
+
+func TestWireGuardSplitPeer(t *testing.T) {
+	testBar.New(t)
+	wgShouldError(nil)
+	now := timing.Now()
+	wgShouldReturn("wg2",
+		testPeer{
+			key: 4, ip: net.ParseIP("192.0.2.4"), port: 51820,
+			handshake: now.Add(-time.Minute),
+			rx:        2048,
+			allowed:   []string{"10.0.0.0/24", "10.0.1.0/24"},
+		},
+		testPeer{key: 4, continued: true, allowed: []string{"10.0.2.0/24"}},
+		testPeer{key: 5, allowed: []string{"10.1.0.0/16"}},
+	)
+	infos := make(chan WireGuardInfo, 10)
+	wg := WireGuard("wg2").Output(func(i WireGuardInfo) bar.Output {
+		infos <- i
+		return outputs.Textf("%d peers", len(i.Peers))
+	})
+	testBar.Run(wg)
+	testBar.LatestOutput().AssertText([]string{"2 peers"}, "split peer is merged")
+
+	i := <-infos
+	p := i.Peers[0]
+	require.Equal(t, "192.0.2.4:51820", p.Endpoint.String())
+	require.Equal(t, time.Minute, p.HandshakeAge())
+	require.InDelta(t, 2.0, p.Rx.Kibibytes(), 1e-9)
+	require.Len(t, p.AllowedIPs, 3)
+	require.Equal(t, "10.0.0.0/24", p.AllowedIPs[0].String())
+	require.Equal(t, "10.0.1.0/24", p.AllowedIPs[1].String())
+	require.Equal(t, "10.0.2.0/24", p.AllowedIPs[2].String())
+	require.Len(t, i.Peers[1].AllowedIPs, 1)
+	require.Equal(t, "10.1.0.0/16", i.Peers[1].AllowedIPs[0].String())
+}
+
+func TestWireGuardShortAttributes(t *testing.T) {
+	defer func() { wgGetDevice = mockGetDevice }()
+	wgGetDevice = func(string) ([][]byte, error) {
+		peer := nl.NewRtAttr(unix.NLA_F_NESTED, nil)
+		peer.AddRtAttr(wgPeerPublicKey, make([]byte, 32))
+		peer.AddRtAttr(wgPeerKeepaliveInterval, []byte{1})
+		peer.AddRtAttr(wgPeerLastHandshakeTime, make([]byte, 8))
+		peer.AddRtAttr(wgPeerRxBytes, []byte{1, 2, 3})
+		peer.AddRtAttr(wgPeerTxBytes, nil)
+		ip := peer.AddRtAttr(wgPeerAllowedIPs|unix.NLA_F_NESTED, nil).
+			AddRtAttr(unix.NLA_F_NESTED, nil)
+		ip.AddRtAttr(wgAllowedIPFamily, []byte{unix.AF_INET})
+		ip.AddRtAttr(wgAllowedIPAddr, net.IPv4(10, 0, 0, 1).To4())
+		ip.AddRtAttr(wgAllowedIPCidrMask, nil)
+		list := nl.NewRtAttr(wgDevicePeers|unix.NLA_F_NESTED, nil)
+		list.AddChild(peer)
+		msg := (&nl.Genlmsg{Command: wgCmdGetDevice, Version: 1}).Serialize()
+		msg = append(msg, nl.NewRtAttr(wgDeviceListenPort, []byte{1}).Serialize()...)
+		msg = append(msg, list.Serialize()...)
+		return [][]byte{msg}, nil
+	}
+	var info WireGuardInfo
+	var err error
+	require.NotPanics(t, func() { info, err = getWireGuardInfo("wg0") })
+	require.NoError(t, err)
+	require.Zero(t, info.ListenPort)
+	require.Len(t, info.Peers, 1)
+	p := info.Peers[0]
+	require.Zero(t, p.KeepaliveInterval)
+	require.True(t, p.LastHandshake.IsZero())
+	require.Zero(t, p.Rx)
+	require.Zero(t, p.Tx)
+	require.Empty(t, p.AllowedIPs)
+}
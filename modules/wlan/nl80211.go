@@ -0,0 +1,344 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wlan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/base/value"
+	l "github.com/soumya92/barista/logging"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// Constants from linux/nl80211.h.
+const (
+	nl80211CmdGetInterface = 5
+	nl80211CmdGetStation   = 17
+	nl80211CmdGetScan      = 32
+	nl80211CmdAssociate    = 38
+	nl80211CmdConnect      = 46
+	nl80211CmdRoam         = 47
+	nl80211CmdDisconnect   = 48
+
+	nl80211AttrIfindex   = 3
+	nl80211AttrIfname    = 4
+	nl80211AttrMac       = 6
+	nl80211AttrStaInfo   = 21
+	nl80211AttrWiphyFreq = 38
+	nl80211AttrBss       = 47
+	nl80211AttrSsid      = 52
+
+	nl80211BssBssid     = 1
+	nl80211BssFrequency = 2
+	nl80211BssIEs       = 6
+	nl80211BssStatus    = 9
+
+	nl80211BssStatusAssociated = 1
+	nl80211BssStatusIbssJoined = 2
+
+	nl80211StaInfoSignal        = 7
+	nl80211StaInfoTxBitrate     = 8
+	nl80211StaInfoRxBitrate     = 14
+	nl80211StaInfoConnectedTime = 16
+
+	nl80211RateInfoBitrate   = 1
+	nl80211RateInfoBitrate32 = 5
+)
+
+var native = nl.NativeEndian()
+
+// To allow tests to mock out nl80211.
+var nl80211Request = func(cmd uint8, flags int, attrs ...*nl.RtAttr) ([][]byte, error) {
+	family, err := nl80211Family()
+	if err != nil {
+		return nil, err
+	}
+	req := nl.NewNetlinkRequest(int(family.ID), flags)
+	req.AddData(&nl.Genlmsg{Command: cmd, Version: 1})
+	for _, a := range attrs {
+		req.AddData(a)
+	}
+	return req.Execute(unix.NETLINK_GENERIC, 0)
+}
+
+var (
+	familyMu sync.Mutex
+	family   *netlink.GenlFamily
+)
+
+// nl80211Family returns the nl80211 generic netlink family. Only a successful
+// lookup is cached, since cfg80211 may not be loaded when the bar starts.
+func nl80211Family() (*netlink.GenlFamily, error) {
+	familyMu.Lock()
+	defer familyMu.Unlock()
+	if family != nil {
+		return family, nil
+	}
+	f, err := netlink.GenlFamilyGet("nl80211")
+	if err != nil {
+		return nil, err
+	}
+	family = f
+	return family, nil
+}
+
+// parseGenlMsg parses the attributes of a generic netlink message.
+func parseGenlMsg(msg []byte) map[uint16][]byte {
+	attrs := map[uint16][]byte{}
+	if len(msg) < nl.SizeofGenlmsg {
+		return attrs
+	}
+	return parseAttrs(msg[nl.SizeofGenlmsg:])
+}
+
+// parseAttrs parses netlink attributes into a map by attribute type.
+func parseAttrs(data []byte) map[uint16][]byte {
+	attrs := map[uint16][]byte{}
+	parsed, _ := nl.ParseRouteAttr(data)
+	for _, a := range parsed {
+		// Strip NLA_F_NESTED and NLA_F_NET_BYTEORDER.
+		attrs[a.Attr.Type&^(unix.NLA_F_NESTED|unix.NLA_F_NET_BYTEORDER)] = a.Value
+	}
+	return attrs
+}
+
+func fillWifiInfo(info *Info) error {
+	msgs, err := nl80211Request(nl80211CmdGetInterface, unix.NLM_F_DUMP)
+	if err != nil {
+		return err
+	}
+	var ifindex []byte
+	for _, msg := range msgs {
+		attrs := parseGenlMsg(msg)
+		if nl.BytesToString(attrs[nl80211AttrIfname]) != info.Name {
+			continue
+		}
+		ifindex = attrs[nl80211AttrIfindex]
+		if ssid, ok := attrs[nl80211AttrSsid]; ok {
+			info.SSID = string(ssid)
+		}
+		if freq, ok := attrs[nl80211AttrWiphyFreq]; ok && len(freq) >= 4 {
+			info.setFrequency(native.Uint32(freq))
+		}
+	}
+	if ifindex == nil {
+		return fmt.Errorf("%s is not a wireless interface", info.Name)
+	}
+
+	msgs, err = nl80211Request(nl80211CmdGetScan, unix.NLM_F_DUMP,
+		nl.NewRtAttr(nl80211AttrIfindex, ifindex))
+	if err != nil {
+		return err
+	}
+	var bssid []byte
+	for _, msg := range msgs {
+		bss := parseAttrs(parseGenlMsg(msg)[nl80211AttrBss])
+		status, ok := bss[nl80211BssStatus]
+		if !ok || len(status) < 4 {
+			continue
+		}
+		switch native.Uint32(status) {
+		case nl80211BssStatusAssociated, nl80211BssStatusIbssJoined:
+		default:
+			continue
+		}
+		bssid = bss[nl80211BssBssid]
+		info.AccessPointMAC = net.HardwareAddr(bssid).String()
+		if freq, ok := bss[nl80211BssFrequency]; ok && len(freq) >= 4 {
+			info.setFrequency(native.Uint32(freq))
+		}
+		if info.SSID == "" {
+			// Older kernels do not include the SSID in the interface, so
+			// get it from the information elements of the BSS.
+			info.SSID = ssidFromIEs(bss[nl80211BssIEs])
+		}
+	}
+	if bssid == nil {
+		return nil
+	}
+
+	msgs, err = nl80211Request(nl80211CmdGetStation, 0,
+		nl.NewRtAttr(nl80211AttrIfindex, ifindex),
+		nl.NewRtAttr(nl80211AttrMac, bssid))
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return errors.New("no station info")
+	}
+	sta := parseAttrs(parseGenlMsg(msgs[0])[nl80211AttrStaInfo])
+	if signal, ok := sta[nl80211StaInfoSignal]; ok && len(signal) >= 1 {
+		info.Signal = int(int8(signal[0]))
+	}
+	info.TxBitrate = bitrate(sta[nl80211StaInfoTxBitrate])
+	info.RxBitrate = bitrate(sta[nl80211StaInfoRxBitrate])
+	if connected, ok := sta[nl80211StaInfoConnectedTime]; ok && len(connected) >= 4 {
+		info.ConnectedTime = time.Duration(native.Uint32(connected)) * time.Second
+	}
+	return nil
+}
+
+// bitrate parses a nested rate info attribute, in units of 100kbit/s.
+func bitrate(data []byte) unit.Datarate {
+	rate := parseAttrs(data)
+	var r uint32
+	if b, ok := rate[nl80211RateInfoBitrate32]; ok && len(b) >= 4 {
+		r = native.Uint32(b)
+	} else if b, ok := rate[nl80211RateInfoBitrate]; ok && len(b) >= 2 {
+		r = uint32(native.Uint16(b))
+	}
+	return unit.Datarate(r) * 100 * unit.KilobitPerSecond
+}
+
+// ssidFromIEs returns the SSID from a list of 802.11 information elements.
+func ssidFromIEs(ies []byte) string {
+	for len(ies) >= 2 {
+		id, size := ies[0], int(ies[1])
+		if len(ies) < 2+size {
+			break
+		}
+		if id == 0 {
+			return string(ies[2 : 2+size])
+		}
+		ies = ies[2+size:]
+	}
+	return ""
+}
+
+func (i *Info) setFrequency(mhz uint32) {
+	i.Frequency = unit.Frequency(mhz) * unit.Megahertz
+	i.Channel = channel(int(mhz))
+}
+
+// channel converts a frequency in MHz to an 802.11 channel number.
+func channel(mhz int) int {
+	switch {
+	case mhz == 2484:
+		return 14
+	case mhz >= 2412 && mhz < 2484:
+		return (mhz - 2407) / 5
+	case mhz >= 5160 && mhz <= 5885:
+		return (mhz - 5000) / 5
+	case mhz >= 5955 && mhz <= 7115:
+		return (mhz - 5950) / 5
+	case mhz >= 58320 && mhz <= 70200:
+		return (mhz - 56160) / 2160
+	}
+	return 0
+}
+
+// associations is updated whenever any wireless interface connects,
+// disconnects, or roams to a different access point.
+var associations value.Value // of int
+
+var mlmeOnce sync.Once
+
+// Bounds for the delay before resubscribing to nl80211 events after an error.
+const (
+	mlmeMinBackoff = time.Second
+	mlmeMaxBackoff = 5 * time.Minute
+)
+
+// To allow tests to mock out nl80211.
+var listenMLME = func() { retryMLME(receiveMLME) }
+
+// retryMLME calls receive, and calls it again with exponential backoff
+// whenever it fails.
+func retryMLME(receive func(count *int) error) {
+	scheduler := timing.NewScheduler()
+	defer scheduler.Close()
+	backoff := mlmeMinBackoff
+	count := 0
+	for {
+		start := timing.Now()
+		err := receive(&count)
+		l.Log("nl80211 events unavailable: %s", err)
+		// Reset the backoff if the subscription was healthy for a while.
+		if timing.Now().Sub(start) > mlmeMaxBackoff {
+			backoff = mlmeMinBackoff
+		}
+		scheduler.After(backoff)
+		<-scheduler.C
+		if backoff *= 2; backoff > mlmeMaxBackoff {
+			backoff = mlmeMaxBackoff
+		}
+	}
+}
+
+// receiveMLME subscribes to nl80211 mlme events and updates associations
+// until the subscription fails.
+func receiveMLME(count *int) error {
+	family, err := nl80211Family()
+	if err != nil {
+		return err
+	}
+	var group uint32
+	for _, g := range family.Groups {
+		if g.Name == "mlme" {
+			group = g.ID
+		}
+	}
+	if group == 0 {
+		return errors.New("nl80211 has no mlme multicast group")
+	}
+	s, err := nl.Subscribe(unix.NETLINK_GENERIC)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	err = unix.SetsockoptInt(s.GetFd(), unix.SOL_NETLINK,
+		unix.NETLINK_ADD_MEMBERSHIP, int(group))
+	if err != nil {
+		return err
+	}
+	for {
+		msgs, _, err := s.Receive()
+		if errors.Is(err, unix.ENOBUFS) {
+			// Events were dropped, but the socket is still usable. Any of
+			// them could have been an association change.
+			*count++
+			associations.Set(*count)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if len(msg.Data) < nl.SizeofGenlmsg {
+				continue
+			}
+			switch msg.Data[0] {
+			case nl80211CmdAssociate, nl80211CmdConnect,
+				nl80211CmdRoam, nl80211CmdDisconnect:
+				*count++
+				associations.Set(*count)
+			}
+		}
+	}
+}
+
+func subscribeAssociations() (<-chan struct{}, func()) {
+	mlmeOnce.Do(func() { go listenMLME() })
+	return associations.Subscribe()
+}
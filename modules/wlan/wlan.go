@@ -12,22 +12,22 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package wlan provides an i3bar module for wireless information.
-// NOTE: This module REQUIRES the external command "iwgetid",
-// because getting the SSID is a privileged operation.
+// Package wlan provides an i3bar module for wireless information, using
+// nl80211 to query the wireless interface.
 package wlan
 
 import (
+	"context"
 	"net"
-	"os/exec"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/value"
 	"github.com/soumya92/barista/base/watchers/netlink"
 	l "github.com/soumya92/barista/logging"
 	"github.com/soumya92/barista/outputs"
+	"github.com/soumya92/barista/timing"
+
 	"github.com/martinlindhe/unit"
 )
 
@@ -40,6 +40,27 @@ type Info struct {
 	AccessPointMAC string
 	Channel        int
 	Frequency      unit.Frequency
+	// Signal strength, in dBm.
+	Signal        int
+	TxBitrate     unit.Datarate
+	RxBitrate     unit.Datarate
+	ConnectedTime time.Duration
+}
+
+// Quality returns the link quality as a percentage, based on the signal
+// strength. -50dBm or better is 100%, and -100dBm or worse is 0%.
+func (i Info) Quality() int {
+	if i.Signal == 0 {
+		return 0
+	}
+	q := 2 * (i.Signal + 100)
+	if q > 100 {
+		return 100
+	}
+	if q < 0 {
+		return 0
+	}
+	return q
 }
 
 // Connecting returns true if a connection is in progress.
@@ -60,14 +81,16 @@ func (i Info) Enabled() bool {
 // Module represents a wlan bar module.
 type Module struct {
 	intf       string
+	scheduler  *timing.Scheduler
 	outputFunc value.Value // of func(Info) bar.Output
 }
 
 // Named constructs an instance of the wlan module for the specified interface.
 func Named(iface string) *Module {
-	m := &Module{intf: iface}
+	m := &Module{intf: iface, scheduler: timing.NewScheduler()}
 	l.Label(m, iface)
-	l.Register(m, "outputFunc")
+	l.Register(m, "scheduler", "outputFunc")
+	m.RefreshInterval(5 * time.Second)
 	// Default output is just the SSID when connected.
 	m.Output(func(i Info) bar.Output {
 		if i.Connected() {
@@ -90,8 +113,20 @@ func (m *Module) Output(outputFunc func(Info) bar.Output) *Module {
 	return m
 }
 
+// RefreshInterval configures the polling frequency for wireless information
+// (e.g. signal strength, bitrate) while connected.
+func (m *Module) RefreshInterval(interval time.Duration) *Module {
+	m.scheduler.Every(interval)
+	return m
+}
+
 // Stream starts the module.
 func (m *Module) Stream(s bar.Sink) {
+	m.StreamContext(context.Background(), s)
+}
+
+// StreamContext implements bar.ContextModule.
+func (m *Module) StreamContext(ctx context.Context, s bar.Sink) {
 	outputFunc := m.outputFunc.Get().(func(Info) bar.Output)
 	nextOutputFunc, done := m.outputFunc.Subscribe()
 	defer done()
@@ -104,14 +139,30 @@ func (m *Module) Stream(s bar.Sink) {
 	}
 	defer linkSub.Unsubscribe()
 
+	associated, doneAssoc := subscribeAssociations()
+	defer doneAssoc()
+
 	info := handleUpdate(linkSub.Get())
 	for {
 		s.Output(outputFunc(info))
-		select {
-		case <-linkSub.C:
-			info = handleUpdate(linkSub.Get())
-		case <-nextOutputFunc:
-			outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+		for updated := false; !updated; {
+			updated = true
+			select {
+			case <-ctx.Done():
+				return
+			case <-linkSub.C:
+				info = handleUpdate(linkSub.Get())
+			case <-associated:
+				info = handleUpdate(linkSub.Get())
+			case <-m.scheduler.C:
+				// Signal strength and bitrate change without any link or
+				// association events, but only while connected.
+				if updated = info.Connected(); updated {
+					info = handleUpdate(linkSub.Get())
+				}
+			case <-nextOutputFunc:
+				outputFunc = m.outputFunc.Get().(func(Info) bar.Output)
+			}
 		}
 	}
 }
@@ -122,25 +173,10 @@ func handleUpdate(link netlink.Link) Info {
 		State: link.State,
 		IPs:   link.IPs,
 	}
-	fillWifiInfo(&info)
-	return info
-}
-
-func fillWifiInfo(info *Info) {
-	ssid, err := iwgetid(info.Name, "-r")
-	if err != nil {
-		return
+	if info.Enabled() {
+		if err := fillWifiInfo(&info); err != nil {
+			l.Fine("%s: %s", info.Name, err)
+		}
 	}
-	info.SSID = ssid
-	info.AccessPointMAC, _ = iwgetid(info.Name, "-a")
-	ch, _ := iwgetid(info.Name, "-c")
-	info.Channel, _ = strconv.Atoi(ch)
-	freq, _ := iwgetid(info.Name, "-f")
-	freqFloat, _ := strconv.ParseFloat(freq, 64)
-	info.Frequency = unit.Frequency(freqFloat) * unit.Hertz
-}
-
-var iwgetid = func(intf, flag string) (string, error) {
-	out, err := exec.Command("/sbin/iwgetid", intf, "-r", flag).Output()
-	return strings.TrimSpace(string(out)), err
+	return info
 }
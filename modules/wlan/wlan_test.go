@@ -17,13 +17,21 @@ package wlan
 import (
 	"errors"
 	"net"
+	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/soumya92/barista/bar"
 	"github.com/soumya92/barista/base/watchers/netlink"
 	"github.com/soumya92/barista/outputs"
 	testBar "github.com/soumya92/barista/testing/bar"
+	"github.com/soumya92/barista/timing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
 )
 
 func TestNoWlan(t *testing.T) {
@@ -35,43 +43,110 @@ func TestNoWlan(t *testing.T) {
 	testBar.LatestOutput().AssertEmpty("when no link is present")
 }
 
-// Map of interface -> map of iwgetid flag -> value.
+type testWifi struct {
+	ssid         string
+	bssid        string
+	freq         uint32
+	signal       int8
+	tx, rx       uint32 // in 100kbit/s
+	connected    uint32
+	omitSSID     bool // from the interface, as on older kernels.
+	noStations   bool
+	disconnected bool
+}
+
+// Map of interface -> wireless info.
 var (
-	testData = map[string]map[string]string{}
+	testData = map[string]testWifi{}
 	testMu   sync.RWMutex
 )
 
-func mockIwgetid(intf, flag string) (string, error) {
+func genlMsg(cmd uint8, attrs ...*nl.RtAttr) []byte {
+	msg := []byte{cmd, 1, 0, 0}
+	for _, a := range attrs {
+		msg = append(msg, a.Serialize()...)
+	}
+	return msg
+}
+
+func mockNl80211(cmd uint8, flags int, attrs ...*nl.RtAttr) ([][]byte, error) {
 	testMu.RLock()
 	defer testMu.RUnlock()
-	d, ok := testData[intf]
-	if !ok {
-		return "", errors.New("No interface")
+	var names []string
+	for name := range testData {
+		names = append(names, name)
 	}
-	val, ok := d[flag]
-	if !ok {
-		return "", errors.New("Unknown flag")
+	sort.Strings(names)
+	ifindex := -1
+	if len(attrs) > 0 {
+		ifindex = int(native.Uint32(attrs[0].Data))
 	}
-	return val, nil
+	var msgs [][]byte
+	for idx, name := range names {
+		d := testData[name]
+		if ifindex >= 0 && idx != ifindex {
+			continue
+		}
+		switch cmd {
+		case nl80211CmdGetInterface:
+			attrs := []*nl.RtAttr{
+				nl.NewRtAttr(nl80211AttrIfindex, nl.Uint32Attr(uint32(idx))),
+				nl.NewRtAttr(nl80211AttrIfname, nl.ZeroTerminated(name)),
+				nl.NewRtAttr(nl80211AttrWiphyFreq, nl.Uint32Attr(d.freq)),
+			}
+			if !d.omitSSID && !d.disconnected {
+				attrs = append(attrs, nl.NewRtAttr(nl80211AttrSsid, []byte(d.ssid)))
+			}
+			msgs = append(msgs, genlMsg(cmd, attrs...))
+		case nl80211CmdGetScan:
+			bssid, _ := net.ParseMAC(d.bssid)
+			ies := append([]byte{1, 2, 0x82, 0x84, 0, byte(len(d.ssid))}, d.ssid...)
+			bss := nl.NewRtAttr(nl80211AttrBss|unix.NLA_F_NESTED, nil)
+			bss.AddRtAttr(nl80211BssBssid, bssid)
+			bss.AddRtAttr(nl80211BssFrequency, nl.Uint32Attr(d.freq))
+			bss.AddRtAttr(nl80211BssIEs, ies)
+			if !d.disconnected {
+				bss.AddRtAttr(nl80211BssStatus, nl.Uint32Attr(nl80211BssStatusAssociated))
+			}
+			// Another BSS in range, but not associated.
+			other := nl.NewRtAttr(nl80211AttrBss|unix.NLA_F_NESTED, nil)
+			other.AddRtAttr(nl80211BssBssid, []byte{6, 5, 4, 3, 2, 1})
+			other.AddRtAttr(nl80211BssFrequency, nl.Uint32Attr(2437))
+			msgs = append(msgs, genlMsg(cmd, other), genlMsg(cmd, bss))
+		case nl80211CmdGetStation:
+			if d.noStations {
+				return nil, unix.ENOENT
+			}
+			sta := nl.NewRtAttr(nl80211AttrStaInfo|unix.NLA_F_NESTED, nil)
+			sta.AddRtAttr(nl80211StaInfoSignal, []byte{byte(d.signal)})
+			sta.AddRtAttr(nl80211StaInfoConnectedTime, nl.Uint32Attr(d.connected))
+			tx := sta.AddRtAttr(nl80211StaInfoTxBitrate|unix.NLA_F_NESTED, nil)
+			tx.AddRtAttr(nl80211RateInfoBitrate32, nl.Uint32Attr(d.tx))
+			rx := sta.AddRtAttr(nl80211StaInfoRxBitrate|unix.NLA_F_NESTED, nil)
+			rx.AddRtAttr(nl80211RateInfoBitrate, nl.Uint16Attr(uint16(d.rx)))
+			msgs = append(msgs, genlMsg(cmd, sta))
+		}
+	}
+	return msgs, nil
 }
 
-func iwgetidShouldReturn(intf string, data map[string]string) {
+func wifiShouldReturn(intf string, data testWifi) {
 	testMu.Lock()
 	defer testMu.Unlock()
 	testData[intf] = data
 }
 
 func init() {
-	iwgetid = mockIwgetid
+	nl80211Request = mockNl80211
+	listenMLME = func() {}
 }
 
 func TestWlan(t *testing.T) {
 	nlt := netlink.TestMode()
-	iwgetidShouldReturn("wlan0", map[string]string{
-		"-r": "OtherNet",
-		"-a": "00:11:22:33:44:66",
-		"-c": "141",
-		"-f": "5.22e+09",
+	wifiShouldReturn("wlan0", testWifi{
+		ssid:  "OtherNet",
+		bssid: "00:11:22:33:44:66",
+		freq:  5220,
 	})
 	link0 := nlt.AddLink(netlink.Link{Name: "wlan0", State: netlink.Up})
 	link1 := nlt.AddLink(netlink.Link{Name: "wlan1", State: netlink.Dormant})
@@ -110,11 +185,10 @@ func TestWlan(t *testing.T) {
 	})
 	testBar.LatestOutput().AssertText([]string{"5e+09", "WLAN ...", "wlan0/OtherNet"})
 
-	iwgetidShouldReturn("wlan1", map[string]string{
-		"-r": "NetworkName",
-		"-a": "00:11:22:33:44:55",
-		"-c": "11",
-		"-f": "2.4e+09",
+	wifiShouldReturn("wlan1", testWifi{
+		ssid:  "NetworkName",
+		bssid: "00:11:22:33:44:55",
+		freq:  2412,
 	})
 	nlt.UpdateLink(link1, netlink.Link{Name: "wlan1", State: netlink.Up})
 	testBar.LatestOutput(1, 2).AssertText([]string{"5e+09", "NetworkName", "wlan0/OtherNet"})
@@ -142,11 +216,11 @@ func TestWlan(t *testing.T) {
 	nlt.UpdateLink(link0, netlink.Link{Name: "wlan0", State: netlink.Down})
 	testBar.LatestOutput(0, 2).At(2).AssertText("wlan1/NetworkName", "when active link switches")
 
-	iwgetidShouldReturn("wl1", map[string]string{
-		"-r": "NetworkName",
-		"-a": "00:11:22:33:44:55",
-		"-c": "11",
-		"-f": "2.4e+09",
+	wifiShouldReturn("wl1", testWifi{
+		ssid:     "NetworkName",
+		bssid:    "00:11:22:33:44:55",
+		freq:     2412,
+		omitSSID: true,
 	})
 	nlt.UpdateLink(link1, netlink.Link{Name: "wl1", State: netlink.Up})
 	testBar.LatestOutput(1, 2).AssertText([]string{"::1", "wl1/NetworkName"}, "when active link is renamed")
@@ -157,3 +231,117 @@ func TestWlan(t *testing.T) {
 	nlt.RemoveLink(link0)
 	testBar.LatestOutput(0, 2).AssertText([]string{"<no wlan>"}, "when no links remain")
 }
+
+func TestWifiDetails(t *testing.T) {
+	nlt := netlink.TestMode()
+	wifiShouldReturn("wlp3s0", testWifi{
+		ssid:      "Home",
+		bssid:     "aa:bb:cc:dd:ee:ff",
+		freq:      5180,
+		signal:    -58,
+		tx:        8667,
+		rx:        5200,
+		connected: 3600,
+	})
+	link := nlt.AddLink(netlink.Link{Name: "wlp3s0", State: netlink.Up})
+
+	infos := make(chan Info, 10)
+	testBar.New(t)
+	testBar.Run(Named("wlp3s0").Output(func(i Info) bar.Output {
+		infos <- i
+		return outputs.Textf("%s %d%%", i.SSID, i.Quality())
+	}))
+	testBar.NextOutput("on start").AssertText([]string{"Home 84%"})
+	info := <-infos
+	require.Equal(t, "aa:bb:cc:dd:ee:ff", info.AccessPointMAC)
+	require.Equal(t, 36, info.Channel)
+	require.InDelta(t, 5.18, info.Frequency.Gigahertz(), 0.001)
+	require.Equal(t, -58, info.Signal)
+	require.InDelta(t, 866.7, info.TxBitrate.MegabitsPerSecond(), 0.01)
+	require.InDelta(t, 520.0, info.RxBitrate.MegabitsPerSecond(), 0.01)
+	require.Equal(t, time.Hour, info.ConnectedTime)
+
+	wifiShouldReturn("wlp3s0", testWifi{
+		ssid:      "Home",
+		bssid:     "aa:bb:cc:dd:ee:ff",
+		freq:      5180,
+		signal:    -71,
+		tx:        2400,
+		rx:        1300,
+		connected: 3605,
+	})
+	testBar.Tick()
+	testBar.NextOutput("on refresh").AssertText([]string{"Home 58%"})
+	require.Equal(t, 3605*time.Second, (<-infos).ConnectedTime)
+
+	wifiShouldReturn("wlp3s0", testWifi{
+		ssid:      "Home",
+		bssid:     "aa:bb:cc:dd:ee:00",
+		freq:      2462,
+		signal:    -40,
+		connected: 0,
+	})
+	associations.Set(1)
+	testBar.NextOutput("on roaming").AssertText([]string{"Home 100%"})
+	info = <-infos
+	require.Equal(t, "aa:bb:cc:dd:ee:00", info.AccessPointMAC)
+	require.Equal(t, 11, info.Channel)
+	require.Equal(t, unit.Datarate(0), info.TxBitrate)
+
+	wifiShouldReturn("wlp3s0", testWifi{freq: 2462, disconnected: true})
+	nlt.UpdateLink(link, netlink.Link{Name: "wlp3s0", State: netlink.Down})
+	testBar.NextOutput("on disconnect").AssertText([]string{" 0%"})
+	<-infos
+	testBar.Tick()
+	testBar.AssertNoOutput("no refresh while disconnected")
+
+	wifiShouldReturn("wlp3s0", testWifi{
+		ssid:       "Cafe",
+		bssid:      "aa:bb:cc:dd:ee:01",
+		freq:       5955,
+		noStations: true,
+	})
+	nlt.UpdateLink(link, netlink.Link{Name: "wlp3s0", State: netlink.Up})
+	testBar.NextOutput("on connect").AssertText([]string{"Cafe 0%"})
+	require.Equal(t, 1, (<-infos).Channel)
+}
+
+func TestQuality(t *testing.T) {
+	for signal, quality := range map[int]int{
+		0: 0, -30: 100, -50: 100, -65: 70, -90: 20, -100: 0, -110: 0,
+	} {
+		require.Equal(t, quality, Info{Signal: signal}.Quality(), "%d dBm", signal)
+	}
+}
+
+func TestMLMEBackoff(t *testing.T) {
+	timing.TestMode()
+	calls := make(chan time.Time, 10)
+	n := 0
+	go retryMLME(func(*int) error {
+		calls <- timing.Now()
+		n++
+		switch n {
+		case 4:
+			// Stay subscribed for longer than the maximum backoff.
+			timing.AdvanceBy(mlmeMaxBackoff + time.Second)
+		case 6:
+			select {}
+		}
+		return errors.New("something went wrong")
+	})
+	last := <-calls
+	for _, expected := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		mlmeMaxBackoff + 2*time.Second,
+		2 * time.Second,
+	} {
+		require.Eventually(t, func() bool { return timing.NextTick().After(last) },
+			time.Second, time.Millisecond, "retry scheduled")
+		next := <-calls
+		require.Equal(t, expected, next.Sub(last))
+		last = next
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify sends desktop notifications using the
+// org.freedesktop.Notifications D-Bus interface, and provides an error handler
+// that shows module errors as notifications.
+package notify
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	l "github.com/soumya92/barista/logging"
+)
+
+// Urgency is the urgency level of a notification.
+type Urgency byte
+
+// Urgency levels defined by the notification spec. The zero value is Normal.
+const (
+	Normal Urgency = iota
+	Low
+	Critical
+)
+
+// level returns the urgency level as sent to the notification server.
+func (u Urgency) level() byte {
+	switch u {
+	case Low:
+		return 0
+	case Critical:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Action is a button shown on a notification.
+type Action struct {
+	Label string
+	// Do is called when the action is invoked.
+	Do func()
+}
+
+// Notification is a single desktop notification.
+type Notification struct {
+	// AppName is the name of the application sending the notification.
+	// Defaults to "barista".
+	AppName string
+	Summary string
+	Body    string
+	// Icon is a themed icon name (e.g. "dialog-error") or a file:// URI.
+	Icon    string
+	Urgency Urgency
+	// Timeout is the time before the notification expires. Zero uses the
+	// notification server's default, and a negative value never expires.
+	Timeout time.Duration
+	Actions []Action
+}
+
+var busType = dbus.Session
+
+const (
+	service = "org.freedesktop.Notifications"
+	object  = "/org/freedesktop/Notifications"
+)
+
+type client struct {
+	watcher *dbus.PropertiesWatcher
+	mu      sync.Mutex
+	actions map[uint32][]Action
+}
+
+var (
+	clientMu sync.Mutex
+	current  *client
+)
+
+// getClient returns the notifications client, connecting on first use.
+func getClient() *client {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if current != nil {
+		return current
+	}
+	c := &client{actions: map[uint32][]Action{}}
+	c.watcher = dbus.WatchProperties(busType, service, object, service).
+		AddSignalHandler("ActionInvoked", c.actionInvoked).
+		AddSignalHandler("NotificationClosed", c.notificationClosed)
+	l.Register(c, "watcher")
+	current = c
+	return c
+}
+
+func (c *client) actionInvoked(s *dbus.Signal, _ dbus.Fetcher) map[string]interface{} {
+	// Ignore malformed signals rather than panicking.
+	if len(s.Body) < 2 {
+		return nil
+	}
+	id, _ := s.Body[0].(uint32)
+	key, _ := s.Body[1].(string)
+	idx, err := strconv.Atoi(key)
+	c.mu.Lock()
+	actions := c.actions[id]
+	c.mu.Unlock()
+	if err == nil && idx >= 0 && idx < len(actions) {
+		l.Fine("%s: action %q on notification %d", l.ID(c), actions[idx].Label, id)
+		// Actions may send notifications, which cannot be done from a
+		// signal handler.
+		go actions[idx].Do()
+	}
+	return nil
+}
+
+func (c *client) notificationClosed(s *dbus.Signal, _ dbus.Fetcher) map[string]interface{} {
+	if len(s.Body) < 1 {
+		return nil
+	}
+	id, _ := s.Body[0].(uint32)
+	c.mu.Lock()
+	delete(c.actions, id)
+	c.mu.Unlock()
+	return nil
+}
+
+// Send sends a notification, and returns its ID.
+func Send(n Notification) (uint32, error) {
+	c := getClient()
+	appName := n.AppName
+	if appName == "" {
+		appName = "barista"
+	}
+	var actions []string
+	for i, a := range n.Actions {
+		actions = append(actions, strconv.Itoa(i), a.Label)
+	}
+	timeout := int32(-1)
+	switch {
+	case n.Timeout > 0:
+		timeout = int32(n.Timeout / time.Millisecond)
+	case n.Timeout < 0:
+		timeout = 0
+	}
+	hints := map[string]interface{}{"urgency": n.Urgency.level()}
+	res, err := c.watcher.Call("Notify", appName, uint32(0), n.Icon,
+		n.Summary, n.Body, actions, hints, timeout)
+	if err != nil {
+		return 0, err
+	}
+	var id uint32
+	if len(res) > 0 {
+		id, _ = res[0].(uint32)
+	}
+	if len(n.Actions) > 0 {
+		c.mu.Lock()
+		c.actions[id] = n.Actions
+		c.mu.Unlock()
+	}
+	return id, nil
+}
+
+// Close closes a notification previously sent using Send.
+func Close(id uint32) error {
+	_, err := getClient().watcher.Call("CloseNotification", id)
+	return err
+}
+
+// ErrorHandler shows the full error text in a desktop notification, with
+// actions to restart the module and copy the error to the clipboard.
+// Use it with barista.SetErrorHandler(notify.ErrorHandler).
+func ErrorHandler(e bar.ErrorEvent) {
+	msg := e.Error.Error()
+	var actions []Action
+	if e.Restart != nil {
+		actions = append(actions, Action{"Restart module", e.Restart})
+	}
+	actions = append(actions, Action{"Copy error", func() {
+		if err := copyToClipboard(msg); err != nil {
+			l.Log("Failed to copy error: %v", err)
+		}
+	}})
+	_, err := Send(Notification{
+		Summary: "Error",
+		Body:    msg,
+		Icon:    "dialog-error",
+		Urgency: Critical,
+		Actions: actions,
+	})
+	if err != nil {
+		l.Log("Failed to show error notification: %v", err)
+	}
+}
+
+// copyToClipboard copies text to the clipboard using wl-copy on wayland,
+// or xclip otherwise. Overridden in tests.
+var copyToClipboard = func(text string) error {
+	cmd := exec.Command("xclip", "-selection", "clipboard")
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmd = exec.Command("wl-copy")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
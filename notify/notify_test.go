@@ -0,0 +1,162 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/soumya92/barista/bar"
+	"github.com/soumya92/barista/base/watchers/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestBus(t *testing.T) *dbus.TestBusObject {
+	busType = dbus.Test
+	bus := dbus.SetupTestBus()
+	clientMu.Lock()
+	if current != nil {
+		current.watcher.Unsubscribe()
+	}
+	current = nil
+	clientMu.Unlock()
+	srv := bus.RegisterService(service)
+	return srv.Object(object, service)
+}
+
+func TestSend(t *testing.T) {
+	obj := setupTestBus(t)
+	calls := make(chan []interface{}, 1)
+	obj.On("Notify", func(args ...interface{}) ([]interface{}, error) {
+		calls <- args
+		return []interface{}{uint32(42)}, nil
+	})
+
+	id, err := Send(Notification{Summary: "Hello", Body: "World"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), id)
+	require.Equal(t, []interface{}{
+		"barista", uint32(0), "", "Hello", "World", []string(nil),
+		map[string]interface{}{"urgency": byte(1)}, int32(-1),
+	}, <-calls)
+
+	_, err = Send(Notification{
+		AppName: "test",
+		Summary: "Critical",
+		Icon:    "dialog-warning",
+		Urgency: Critical,
+		Timeout: 5 * time.Second,
+		Actions: []Action{{"Foo", func() {}}, {"Bar", func() {}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		"test", uint32(0), "dialog-warning", "Critical", "",
+		[]string{"0", "Foo", "1", "Bar"},
+		map[string]interface{}{"urgency": byte(2)}, int32(5000),
+	}, <-calls)
+
+	Send(Notification{Timeout: -1})
+	require.Equal(t, int32(0), (<-calls)[7], "negative timeout never expires")
+
+	obj.On("Notify", func(...interface{}) ([]interface{}, error) {
+		return nil, errors.New("some error")
+	})
+	_, err = Send(Notification{Summary: "Oops"})
+	require.Error(t, err)
+
+	closed := make(chan uint32, 1)
+	obj.On("CloseNotification", func(args ...interface{}) ([]interface{}, error) {
+		closed <- args[0].(uint32)
+		return nil, nil
+	})
+	require.NoError(t, Close(42))
+	require.Equal(t, uint32(42), <-closed)
+}
+
+func TestActions(t *testing.T) {
+	obj := setupTestBus(t)
+	nextID := uint32(0)
+	obj.On("Notify", func(args ...interface{}) ([]interface{}, error) {
+		nextID++
+		return []interface{}{nextID}, nil
+	})
+
+	invoked := make(chan string, 10)
+	action := func(name string) func() {
+		return func() { invoked <- name }
+	}
+	id1, _ := Send(Notification{Actions: []Action{
+		{"A", action("a1")}, {"B", action("b1")},
+	}})
+	id2, _ := Send(Notification{Actions: []Action{{"A", action("a2")}}})
+
+	obj.Emit("ActionInvoked", id1, "1")
+	require.Equal(t, "b1", <-invoked)
+	obj.Emit("ActionInvoked", id2, "0")
+	require.Equal(t, "a2", <-invoked)
+
+	obj.Emit("ActionInvoked", id2, "1")
+	obj.Emit("ActionInvoked", uint32(99), "0")
+	obj.Emit("ActionInvoked", id1, "default")
+	obj.Emit("NotificationClosed", id1, uint32(2))
+	obj.Emit("ActionInvoked", id1, "0")
+	select {
+	case name := <-invoked:
+		require.Fail(t, "Unexpected action", name)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Malformed signals are ignored.
+	obj.Emit("ActionInvoked", id2)
+	obj.Emit("NotificationClosed")
+	obj.Emit("ActionInvoked", id2, "0")
+	require.Equal(t, "a2", <-invoked)
+}
+
+func TestErrorHandler(t *testing.T) {
+	obj := setupTestBus(t)
+	calls := make(chan []interface{}, 1)
+	obj.On("Notify", func(args ...interface{}) ([]interface{}, error) {
+		calls <- args
+		return []interface{}{uint32(7)}, nil
+	})
+	copied := make(chan string, 1)
+	copyToClipboard = func(text string) error {
+		copied <- text
+		return nil
+	}
+	restarted := make(chan struct{}, 1)
+
+	ErrorHandler(bar.ErrorEvent{
+		Error:   errors.New("something went wrong"),
+		Restart: func() { restarted <- struct{}{} },
+	})
+	args := <-calls
+	require.Equal(t, "Error", args[3])
+	require.Equal(t, "something went wrong", args[4])
+	require.Equal(t, []string{"0", "Restart module", "1", "Copy error"}, args[5])
+
+	obj.Emit("ActionInvoked", uint32(7), "1")
+	require.Equal(t, "something went wrong", <-copied)
+	obj.Emit("ActionInvoked", uint32(7), "0")
+	<-restarted
+
+	ErrorHandler(bar.ErrorEvent{Error: errors.New("no restart")})
+	args = <-calls
+	require.Equal(t, []string{"0", "Copy error"}, args[5])
+	obj.Emit("ActionInvoked", uint32(7), "0")
+	require.Equal(t, "no restart", <-copied)
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/soumya92/barista/bar"
+)
+
+// i3Event instances are received from i3bar on stdin.
+type i3Event struct {
+	bar.Event
+	Name string `json:"name"`
+}
+
+// i3Header is sent at the beginning of output.
+type i3Header struct {
+	Version     int  `json:"version"`
+	StopSignal  int  `json:"stop_signal,omitempty"`
+	ContSignal  int  `json:"cont_signal,omitempty"`
+	ClickEvents bool `json:"click_events"`
+}
+
+// i3bar implements the i3bar protocol, which is also used by swaybar.
+// See https://i3wm.org/docs/i3bar-protocol.html for details.
+type i3bar struct{}
+
+// I3Bar returns a renderer that speaks the i3bar protocol. This is the default
+// renderer, and also works with swaybar.
+func I3Bar() Renderer {
+	return i3bar{}
+}
+
+func (i3bar) Start(w io.Writer, opts Options) error {
+	header := i3Header{
+		Version:     1,
+		ClickEvents: true,
+		StopSignal:  int(opts.StopSignal),
+		ContSignal:  int(opts.ContSignal),
+	}
+	if err := json.NewEncoder(w).Encode(&header); err != nil {
+		return err
+	}
+	// Start the infinite array.
+	_, err := io.WriteString(w, "[")
+	return err
+}
+
+func (i3bar) Render(w io.Writer, segments []NamedSegment) error {
+	output := make([]map[string]interface{}, 0)
+	for _, s := range segments {
+		out := i3map(s.Segment)
+		if s.Name != "" {
+			out["name"] = s.Name
+		}
+		output = append(output, out)
+	}
+	if err := json.NewEncoder(w).Encode(output); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, ",\n")
+	return err
+}
+
+// ReadEvents parses the infinite stream of events received from i3.
+func (i3bar) ReadEvents(r io.Reader, events chan<- Event) error {
+	decoder := json.NewDecoder(r)
+	// Consume opening '['
+	_, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	for decoder.More() {
+		var event i3Event
+		err = decoder.Decode(&event)
+		if err != nil {
+			return err
+		}
+		events <- Event{Event: event.Event, Name: event.Name}
+	}
+	return errors.New("stdin exhausted")
+}
+
+// i3map serialises the attributes of the Segment in
+// the format used by i3bar.
+func i3map(s *bar.Segment) map[string]interface{} {
+	i3map := make(map[string]interface{})
+	txt, pango := s.Content()
+	i3map["full_text"] = txt
+	if id, ok := s.GetID(); ok {
+		i3map["instance"] = id
+	}
+	if shortText, ok := s.GetShortText(); ok {
+		i3map["short_text"] = shortText
+	}
+	if color, ok := s.GetColor(); ok {
+		i3map["color"] = colorString(color)
+	}
+	if background, ok := s.GetBackground(); ok {
+		i3map["background"] = colorString(background)
+	}
+	if border, ok := s.GetBorder(); ok {
+		i3map["border"] = colorString(border)
+	}
+	if minWidth, ok := s.GetMinWidth(); ok {
+		i3map["min_width"] = minWidth
+	}
+	if align, ok := s.GetAlignment(); ok {
+		i3map["align"] = align
+	}
+	if urgent, ok := s.IsUrgent(); ok {
+		i3map["urgent"] = urgent
+	}
+	if separator, ok := s.HasSeparator(); ok {
+		i3map["separator"] = separator
+	}
+	if padding, ok := s.GetPadding(); ok {
+		i3map["separator_block_width"] = padding
+	}
+	if pango {
+		i3map["markup"] = "pango"
+	} else {
+		i3map["markup"] = "none"
+	}
+	return i3map
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+type segmentAssertions struct {
+	*testing.T
+	actual   *bar.Segment
+	Expected map[string]string
+}
+
+func (s segmentAssertions) AssertEqual(message string) {
+	actualMap := make(map[string]string)
+	for k, v := range i3map(s.actual) {
+		actualMap[k] = fmt.Sprintf("%v", v)
+	}
+	require.Equal(s.T, s.Expected, actualMap, message)
+}
+
+func TestI3Map(t *testing.T) {
+	segment := bar.TextSegment("test")
+	a := segmentAssertions{t, segment, make(map[string]string)}
+
+	a.Expected["full_text"] = "test"
+	a.Expected["markup"] = "none"
+	a.AssertEqual("sets full_text")
+
+	segment2 := segment.ShortText("t")
+	a2 := segmentAssertions{t, segment2, make(map[string]string)}
+	a2.Expected["full_text"] = "test"
+	a2.Expected["short_text"] = "t"
+	a2.Expected["markup"] = "none"
+	a2.AssertEqual("sets short_text, does not lose full_text")
+
+	segment3 := bar.PangoSegment("<b>bold</b>")
+	a3 := segmentAssertions{t, segment3, make(map[string]string)}
+	a3.Expected["full_text"] = "<b>bold</b>"
+	a3.Expected["markup"] = "pango"
+	a3.AssertEqual("markup set for pango segment")
+
+	a.Expected["short_text"] = "t"
+	a.AssertEqual("mutates in place")
+
+	segment.Color(color.RGBA{0xff, 0x00, 0x00, 0xff})
+	a.Expected["color"] = "#ff0000"
+	a.AssertEqual("sets color value")
+
+	segment.Color(nil)
+	delete(a.Expected, "color")
+	a.AssertEqual("clears color value when blank")
+
+	segment.Background(nil)
+	a.AssertEqual("clearing unset color works")
+
+	segment.Identifier("foo")
+	a.Expected["instance"] = "foo"
+	a.AssertEqual("sets instance")
+
+	segment.Background(color.RGBA{0x00, 0x77, 0x00, 0x77})
+	a.Expected["background"] = "#00ff00"
+	a.AssertEqual("sets background color")
+
+	segment.Border(color.Transparent)
+	// i3 doesn't support alpha in colours yet.
+	a.Expected["border"] = "#000000"
+	a.AssertEqual("sets border color")
+
+	segment.Align(bar.AlignStart)
+	a.Expected["align"] = "left"
+	a.AssertEqual("alignment strings are preserved")
+
+	segment.MinWidth(10)
+	a.Expected["min_width"] = "10"
+	a.AssertEqual("sets min width in px")
+
+	segment.MinWidthPlaceholder("00:00")
+	a.Expected["min_width"] = "00:00"
+	a.AssertEqual("sets min width placeholder")
+
+	// sanity check default go values.
+	segment.Separator(false)
+	a.Expected["separator"] = "false"
+	a.AssertEqual("separator = false")
+
+	segment.Padding(0)
+	a.Expected["separator_block_width"] = "0"
+	a.AssertEqual("separator width = 0")
+
+	segment.Urgent(false)
+	a.Expected["urgent"] = "false"
+	a.AssertEqual("urgent = false")
+}
+
+func TestI3BarHeader(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, I3Bar().Start(&out, Options{}))
+	require.Equal(t, "{\"version\":1,\"click_events\":true}\n[", out.String(),
+		"header omits signals when not provided")
+
+	out.Reset()
+	require.NoError(t, I3Bar().Start(&out, Options{StopSignal: 10, ContSignal: 12}))
+	require.Equal(t,
+		"{\"version\":1,\"stop_signal\":10,\"cont_signal\":12,\"click_events\":true}\n[",
+		out.String(), "header includes signals")
+}
+
+func TestI3BarRender(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, I3Bar().Render(&out, nil))
+	require.Equal(t, "[]\n,\n", out.String(), "empty bar")
+
+	out.Reset()
+	require.NoError(t, I3Bar().Render(&out, []NamedSegment{
+		{Segment: bar.TextSegment("a"), Name: "0"},
+		{Segment: bar.TextSegment("b")},
+	}))
+	require.Equal(t,
+		`[{"full_text":"a","markup":"none","name":"0"},{"full_text":"b","markup":"none"}]`+"\n,\n",
+		out.String(), "name only included when set")
+}
+
+func TestI3BarEvents(t *testing.T) {
+	events := make(chan Event, 10)
+	err := I3Bar().ReadEvents(strings.NewReader(
+		`[{"name":"a","button":1,"x":10},{"name":"b","button":4},`+
+			`{"name":"c","instance":"foo","button":1}`), events)
+	require.Error(t, err, "on end of input")
+	require.Equal(t, Event{Name: "a", Event: bar.Event{Button: bar.ButtonLeft, ScreenX: 10}}, <-events)
+	require.Equal(t, Event{Name: "b", Event: bar.Event{Button: bar.ScrollUp}}, <-events)
+	require.Equal(t, Event{Name: "c", Event: bar.Event{SegmentID: "foo", Button: bar.ButtonLeft}}, <-events)
+
+	err = I3Bar().ReadEvents(strings.NewReader(`!!!`), events)
+	require.Error(t, err, "on invalid input")
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/soumya92/barista/bar"
+	l "github.com/soumya92/barista/logging"
+)
+
+// lemonbar implements the lemonbar text format. Clickable areas are written
+// as actions that lemonbar prints to its stdout when clicked, so lemonbar's
+// output must be piped back to the bar's input, e.g. using a fifo:
+//
+//	mkfifo /tmp/bar.fifo
+//	mybar < /tmp/bar.fifo | lemonbar > /tmp/bar.fifo
+type lemonbar struct{}
+
+// Lemonbar returns a renderer that produces lemonbar formatted text. Pango
+// markup is not supported, so pango segments are displayed as plain text.
+func Lemonbar() Renderer {
+	return lemonbar{}
+}
+
+// lemonbarButtons are the mouse buttons that lemonbar supports in actions.
+var lemonbarButtons = []bar.Button{
+	bar.ButtonLeft, bar.ButtonMiddle, bar.ButtonRight,
+	bar.ScrollUp, bar.ScrollDown,
+}
+
+func (lemonbar) Start(io.Writer, Options) error {
+	return nil
+}
+
+func (lemonbar) Render(w io.Writer, segments []NamedSegment) error {
+	var out strings.Builder
+	// Status bars are usually right-aligned, so mimic i3bar's layout.
+	out.WriteString("%{r}")
+	for i, s := range segments {
+		writeLemonbarSegment(&out, s)
+		if i+1 < len(segments) {
+			out.WriteString(textSeparator(s.Segment))
+		}
+	}
+	out.WriteString("\n")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// actionEscaper escapes segment names for use in lemonbar click actions. Colons
+// would end the action, and percent signs could start another block.
+var actionEscaper = strings.NewReplacer(":", `\:`, "%", "%%")
+
+func writeLemonbarSegment(out *strings.Builder, s NamedSegment) {
+	var closers []string
+	wrap := func(open, close string) {
+		out.WriteString(open)
+		closers = append(closers, close)
+	}
+	if s.Name != "" {
+		for _, btn := range lemonbarButtons {
+			cmd := actionEscaper.Replace(fmt.Sprintf("%d %s", btn, s.Name))
+			wrap(fmt.Sprintf("%%{A%d:%s:}", btn, cmd), "%{A}")
+		}
+	}
+	if color, ok := s.GetColor(); ok {
+		wrap("%{F"+colorString(color)+"}", "%{F-}")
+	}
+	if background, ok := s.GetBackground(); ok {
+		wrap("%{B"+colorString(background)+"}", "%{B-}")
+	}
+	if border, ok := s.GetBorder(); ok {
+		wrap("%{U"+colorString(border)+"}%{+u}", "%{-u}%{U-}")
+	}
+	if urgent, _ := s.IsUrgent(); urgent {
+		wrap("%{R}", "%{R}")
+	}
+	txt := strings.ReplaceAll(plainText(s.Segment), "\n", " ")
+	out.WriteString(strings.ReplaceAll(txt, "%", "%%"))
+	for i := len(closers) - 1; i >= 0; i-- {
+		out.WriteString(closers[i])
+	}
+}
+
+// ReadEvents parses the actions printed by lemonbar when clicked, one per line.
+func (lemonbar) ReadEvents(r io.Reader, events chan<- Event) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		space := strings.IndexByte(line, ' ')
+		if space < 0 {
+			l.Log("Ignoring unexpected input from lemonbar: %q", line)
+			continue
+		}
+		btn, err := strconv.Atoi(line[:space])
+		if err != nil {
+			l.Log("Ignoring unexpected input from lemonbar: %q", line)
+			continue
+		}
+		events <- Event{
+			Event: bar.Event{Button: bar.Button(btn)},
+			// lemonbar removes the escaping of colons, but not of percent
+			// signs.
+			Name: strings.ReplaceAll(line[space+1:], "%%", "%"),
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return errors.New("stdin exhausted")
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+func renderLemonbar(t *testing.T, segments ...NamedSegment) string {
+	var out strings.Builder
+	require.NoError(t, Lemonbar().Render(&out, segments))
+	return out.String()
+}
+
+func TestLemonbarRender(t *testing.T) {
+	require.Equal(t, "%{r}\n", renderLemonbar(t), "empty bar")
+
+	require.Equal(t, "%{r}a | b\n", renderLemonbar(t,
+		NamedSegment{Segment: bar.TextSegment("a")},
+		NamedSegment{Segment: bar.TextSegment("b")},
+	), "separator between segments")
+
+	require.Equal(t, "%{r}ab\n", renderLemonbar(t,
+		NamedSegment{Segment: bar.TextSegment("a").Separator(false).Padding(0)},
+		NamedSegment{Segment: bar.TextSegment("b")},
+	), "no separator or padding")
+
+	require.Equal(t, "%{r}bold & 100%%\n", renderLemonbar(t,
+		NamedSegment{Segment: bar.PangoSegment("<b>bold</b> &amp; 100%")},
+	), "pango markup removed, % escaped")
+
+	require.Equal(t, "%{r}%{F#ff0000}%{B#0000ff}%{U#00ff00}%{+u}%{R}x%{R}%{-u}%{U-}%{B-}%{F-}\n",
+		renderLemonbar(t, NamedSegment{Segment: bar.TextSegment("x").
+			Color(color.RGBA{0xff, 0, 0, 0xff}).
+			Background(color.RGBA{0, 0, 0xff, 0xff}).
+			Border(color.RGBA{0, 0xff, 0, 0xff}).
+			Urgent(true)}),
+		"colours and urgency")
+
+	require.Equal(t,
+		`%{r}%{A1:1 a\:b:}%{A2:2 a\:b:}%{A3:3 a\:b:}%{A4:4 a\:b:}%{A5:5 a\:b:}`+
+			"x%{A}%{A}%{A}%{A}%{A}\n",
+		renderLemonbar(t, NamedSegment{Segment: bar.TextSegment("x"), Name: "a:b"}),
+		"click actions for named segments")
+
+	require.Equal(t,
+		`%{r}%{A1:1 50%%:}%{A2:2 50%%:}%{A3:3 50%%:}%{A4:4 50%%:}%{A5:5 50%%:}`+
+			"x%{A}%{A}%{A}%{A}%{A}\n",
+		renderLemonbar(t, NamedSegment{Segment: bar.TextSegment("x"), Name: "50%"}),
+		"% escaped in click actions")
+}
+
+func TestLemonbarEvents(t *testing.T) {
+	events := make(chan Event, 10)
+	err := Lemonbar().ReadEvents(strings.NewReader(
+		"1 a\ngarbage\nx y\n4 b:c\n3 50%%\n"), events)
+	require.Error(t, err, "on end of input")
+	require.Equal(t, Event{Name: "a", Event: bar.Event{Button: bar.ButtonLeft}}, <-events)
+	require.Equal(t, Event{Name: "b:c", Event: bar.Event{Button: bar.ScrollUp}}, <-events)
+	require.Equal(t, Event{Name: "50%", Event: bar.Event{Button: bar.ButtonRight}}, <-events)
+	require.Empty(t, events, "malformed lines are ignored")
+}
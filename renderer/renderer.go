@@ -0,0 +1,124 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package renderer provides the protocols used to communicate with the program
+// that displays the bar, e.g. i3bar, swaybar, lemonbar, or waybar.
+package renderer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/soumya92/barista/bar"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"golang.org/x/sys/unix"
+)
+
+// NamedSegment is a segment of output, along with the name that the renderer
+// must use to identify the segment in click events. Segments that cannot be
+// clicked have an empty name.
+type NamedSegment struct {
+	*bar.Segment
+	Name string
+}
+
+// Event is a click event received from the bar. Name is the name of the
+// segment that was clicked.
+type Event struct {
+	bar.Event
+	Name string
+}
+
+// Options are the settings that the bar provides to a renderer on startup.
+type Options struct {
+	// StopSignal and ContSignal are the signals that the bar will use to pause
+	// and resume processing, or zero if pause/resume is not supported.
+	StopSignal unix.Signal
+	ContSignal unix.Signal
+}
+
+// Renderer handles the protocol used to communicate with the program that
+// displays the bar. It writes the bar's output, and parses click events.
+type Renderer interface {
+	// Start is called once before any output, and writes any required preamble.
+	Start(w io.Writer, opts Options) error
+	// Render writes the complete bar, made up of the given segments.
+	Render(w io.Writer, segments []NamedSegment) error
+	// ReadEvents reads click events, sending them to the given channel, and
+	// returns when the input is exhausted or on error. A nil return indicates
+	// that the renderer does not support click events.
+	ReadEvents(r io.Reader, events chan<- Event) error
+}
+
+// Named returns a renderer by name. This allows a single bar binary to select
+// the appropriate protocol at startup, e.g. from a command-line flag.
+// Supported names are "i3bar", "swaybar", "lemonbar", and "waybar".
+func Named(name string) (Renderer, error) {
+	switch name {
+	case "i3bar", "swaybar":
+		return I3Bar(), nil
+	case "lemonbar":
+		return Lemonbar(), nil
+	case "waybar":
+		return Waybar(), nil
+	}
+	return nil, fmt.Errorf("Unknown renderer: %s", name)
+}
+
+func colorString(c color.Color) string {
+	cful, _ := colorful.MakeColor(c)
+	return cful.Hex()
+}
+
+// textSeparator approximates i3bar's separators and padding, for renderers
+// that display the entire bar as a single block of text.
+func textSeparator(s *bar.Segment) string {
+	if sep, _ := s.HasSeparator(); sep {
+		return " | "
+	}
+	if padding, _ := s.GetPadding(); padding > 0 {
+		return " "
+	}
+	return ""
+}
+
+// plainText returns the text content of the segment, with any pango markup
+// removed, for renderers that do not support pango.
+func plainText(s *bar.Segment) string {
+	txt, isPango := s.Content()
+	if !isPango {
+		return txt
+	}
+	var out strings.Builder
+	d := xml.NewDecoder(strings.NewReader("<markup>" + txt + "</markup>"))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return out.String()
+		}
+		if err != nil {
+			// Invalid markup, so it's likely to be displayed as-is anyway.
+			return txt
+		}
+		if data, ok := tok.(xml.CharData); ok {
+			out.Write(data)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamed(t *testing.T) {
+	for name, expected := range map[string]Renderer{
+		"i3bar":    I3Bar(),
+		"swaybar":  I3Bar(),
+		"lemonbar": Lemonbar(),
+		"waybar":   Waybar(),
+	} {
+		r, err := Named(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, r, name)
+	}
+	_, err := Named("xmobar")
+	require.Error(t, err, "unknown renderer")
+}
+
+func TestPlainText(t *testing.T) {
+	require.Equal(t, "a<b", plainText(bar.TextSegment("a<b")))
+	require.Equal(t, "bold & italic",
+		plainText(bar.PangoSegment("<b>bold</b> &amp; <i>italic</i>")))
+	require.Equal(t, "a</b>",
+		plainText(bar.PangoSegment("a</b>")),
+		"invalid markup returned as-is")
+}
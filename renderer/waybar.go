@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"strings"
+)
+
+// waybarOutput is a single line of output for a waybar custom module.
+// See waybar-custom(5) for details.
+type waybarOutput struct {
+	Text    string   `json:"text"`
+	Tooltip string   `json:"tooltip,omitempty"`
+	Class   []string `json:"class,omitempty"`
+}
+
+// waybar implements the JSON format used by waybar's custom modules. Since
+// waybar displays a custom module as a single block, the entire bar is
+// rendered as pango markup in one block.
+//
+// The corresponding waybar configuration is:
+//
+//	"custom/barista": {
+//	    "exec": "mybar",
+//	    "return-type": "json"
+//	}
+type waybar struct{}
+
+// Waybar returns a renderer for a waybar custom module with "return-type" set
+// to "json". Waybar does not send click events to custom modules.
+func Waybar() Renderer {
+	return waybar{}
+}
+
+func (waybar) Start(io.Writer, Options) error {
+	return nil
+}
+
+func (waybar) Render(w io.Writer, segments []NamedSegment) error {
+	var text strings.Builder
+	var errs []string
+	classes := map[string]bool{}
+	for i, s := range segments {
+		text.WriteString(waybarMarkup(s))
+		if i+1 < len(segments) {
+			text.WriteString(html.EscapeString(textSeparator(s.Segment)))
+		}
+		if urgent, _ := s.IsUrgent(); urgent {
+			classes["urgent"] = true
+		}
+		if err := s.GetError(); err != nil {
+			classes["error"] = true
+			errs = append(errs, err.Error())
+		}
+	}
+	out := waybarOutput{
+		Text:    text.String(),
+		Tooltip: strings.Join(errs, "\n"),
+	}
+	for _, c := range []string{"urgent", "error"} {
+		if classes[c] {
+			out.Class = append(out.Class, c)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+// waybarMarkup returns the pango markup for a single segment, using span
+// attributes for the segment's colours.
+func waybarMarkup(s NamedSegment) string {
+	txt, isPango := s.Content()
+	if !isPango {
+		txt = html.EscapeString(txt)
+	}
+	var attrs []string
+	if color, ok := s.GetColor(); ok {
+		attrs = append(attrs, "color='"+colorString(color)+"'")
+	}
+	if background, ok := s.GetBackground(); ok {
+		attrs = append(attrs, "background='"+colorString(background)+"'")
+	}
+	if border, ok := s.GetBorder(); ok {
+		attrs = append(attrs,
+			"underline='single'", "underline_color='"+colorString(border)+"'")
+	}
+	if len(attrs) == 0 {
+		return txt
+	}
+	return "<span " + strings.Join(attrs, " ") + ">" + txt + "</span>"
+}
+
+// ReadEvents discards any input, since waybar does not send click events.
+func (waybar) ReadEvents(r io.Reader, events chan<- Event) error {
+	io.Copy(io.Discard, r)
+	return nil
+}
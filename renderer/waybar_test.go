@@ -0,0 +1,58 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/soumya92/barista/bar"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaybarRender(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, Waybar().Start(&out, Options{}))
+	require.Empty(t, out.String(), "no header")
+
+	require.NoError(t, Waybar().Render(&out, []NamedSegment{
+		{Segment: bar.TextSegment("a<b")},
+		{Segment: bar.PangoSegment("<b>c</b>").Color(color.RGBA{0xff, 0, 0, 0xff})},
+	}))
+	require.Equal(t,
+		`{"text":"a&lt;b | <span color='#ff0000'><b>c</b></span>"}`+"\n",
+		out.String(), "segments combined into pango markup")
+
+	out.Reset()
+	require.NoError(t, Waybar().Render(&out, []NamedSegment{
+		{Segment: bar.TextSegment("a").Urgent(true)},
+		{Segment: bar.ErrorSegment(errors.New("oops"))},
+	}))
+	var o waybarOutput
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &o))
+	require.Equal(t, []string{"urgent", "error"}, o.Class)
+	require.Equal(t, "oops", o.Tooltip)
+}
+
+func TestWaybarEvents(t *testing.T) {
+	events := make(chan Event, 1)
+	require.NoError(t, Waybar().ReadEvents(strings.NewReader(`foo`), events),
+		"events are not supported")
+	require.Empty(t, events)
+}
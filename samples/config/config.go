@@ -0,0 +1,43 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// config demonstrates a bar built from a YAML configuration file, which can be
+// changed without recompiling the bar. The bar reloads the configuration when
+// the file changes, or on SIGHUP.
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/soumya92/barista"
+	"github.com/soumya92/barista/config"
+)
+
+func main() {
+	configDir, _ := os.UserConfigDir()
+	configFile := flag.String("config",
+		filepath.Join(configDir, "barista", "bar.yaml"),
+		"path of the bar configuration file")
+	flag.Parse()
+
+	modules, err := config.Watch(*configFile)
+	if err != nil {
+		panic(err)
+	}
+	if err := barista.Run(modules); err != nil {
+		panic(err)
+	}
+}
@@ -151,5 +151,7 @@ func main() {
 
 	barista.Add(clock.Local().OutputFormat("2006-01-02 15:04:05"))
 
-	panic(barista.Run())
+	if err := barista.Run(); err != nil {
+		panic(err)
+	}
 }
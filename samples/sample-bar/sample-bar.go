@@ -665,5 +665,7 @@ func main() {
 
 	var mm bar.Module
 	mm, mainModalController = mainModal.Build()
-	panic(barista.Run(mm, localtime))
+	if err := barista.Run(mm, localtime); err != nil {
+		panic(err)
+	}
 }
@@ -433,7 +433,7 @@ func main() {
 				click.RunLeft("xdg-open", "https://github.com/notifications"))
 		})
 
-	panic(barista.Run(
+	err := barista.Run(
 		rhythmbox,
 		grp,
 		ghNotify,
@@ -441,5 +441,8 @@ func main() {
 		batt,
 		wthr,
 		localtime,
-	))
+	)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -59,9 +59,12 @@ func (d diskSpaceModule) Stream(sink bar.Sink) {
 }
 
 func main() {
-	panic(barista.Run(
+	err := barista.Run(
 		diskSpaceModule("/home"),
 		simpleClockModule{"Mon Jan 02", time.Hour},
 		simpleClockModule{"15:04:05", time.Second},
-	))
+	)
+	if err != nil {
+		panic(err)
+	}
 }
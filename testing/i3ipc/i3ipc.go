@@ -0,0 +1,242 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i3ipc provides a fake i3/sway IPC server for tests. It serves canned
+// replies for each message type, records commands, and sends events to
+// subscribed clients.
+package i3ipc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Message types supported by the server.
+const (
+	RunCommand      uint32 = 0
+	GetWorkspaces   uint32 = 1
+	Subscribe       uint32 = 2
+	GetTree         uint32 = 4
+	GetBindingState uint32 = 12
+)
+
+// eventTypes maps event names to the type sent in the event message.
+var eventTypes = map[string]uint32{
+	"workspace":        0x80000000,
+	"output":           0x80000001,
+	"mode":             0x80000002,
+	"window":           0x80000003,
+	"barconfig_update": 0x80000004,
+	"binding":          0x80000005,
+	"shutdown":         0x80000006,
+	"tick":             0x80000007,
+}
+
+const magic = "i3-ipc"
+
+// i3 uses the native byte order, which is little-endian on all platforms
+// supported by barista.
+var byteOrder = binary.LittleEndian
+
+// Server is a fake i3 IPC server listening on a unix socket.
+type Server struct {
+	dir      string
+	ln       net.Listener
+	commands chan string
+
+	mu      sync.Mutex
+	replies map[uint32][]byte
+	conns   map[*conn]bool
+}
+
+type conn struct {
+	net.Conn
+	mu     sync.Mutex
+	events map[string]bool
+}
+
+// New starts a new fake IPC server on a unix socket in a temporary directory.
+// By default, the server replies to commands with success, has no workspaces,
+// an empty tree, and is in the default binding mode.
+func New() *Server {
+	dir, err := os.MkdirTemp("", "i3ipc")
+	if err != nil {
+		panic(err)
+	}
+	ln, err := net.Listen("unix", filepath.Join(dir, "ipc.sock"))
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		dir:      dir,
+		ln:       ln,
+		commands: make(chan string, 100),
+		replies:  map[uint32][]byte{},
+		conns:    map[*conn]bool{},
+	}
+	s.SetReply(GetWorkspaces, []interface{}{})
+	s.SetReply(GetTree, map[string]interface{}{"type": "root", "nodes": []interface{}{}})
+	s.SetReply(GetBindingState, map[string]interface{}{"name": "default"})
+	go s.serve()
+	return s
+}
+
+// Path returns the path of the server's socket.
+func (s *Server) Path() string {
+	return s.ln.Addr().String()
+}
+
+// SetReply sets the reply for a message type. The payload is encoded as JSON.
+func (s *Server) SetReply(msgType uint32, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[msgType] = data
+}
+
+// Commands returns a channel that receives each command sent to the server.
+// Commands always succeed, unless a different reply is set for RunCommand.
+func (s *Server) Commands() <-chan string {
+	return s.commands
+}
+
+// Emit sends an event to all clients subscribed to it. The payload is
+// encoded as JSON.
+func (s *Server) Emit(event string, payload interface{}) {
+	typ, ok := eventTypes[event]
+	if !ok {
+		panic("unknown event " + event)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.mu.Lock()
+		if c.events[event] {
+			write(c, typ, data)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Disconnect closes all client connections, without stopping the server.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.Close()
+		delete(s.conns, c)
+	}
+}
+
+// Close stops the server, closing all connections.
+func (s *Server) Close() {
+	s.ln.Close()
+	s.Disconnect()
+	os.RemoveAll(s.dir)
+}
+
+func (s *Server) serve() {
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &conn{Conn: nc, events: map[string]bool{}}
+		s.mu.Lock()
+		s.conns[c] = true
+		s.mu.Unlock()
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c *conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+	for {
+		typ, payload, err := read(c)
+		if err != nil {
+			return
+		}
+		var reply []byte
+		switch typ {
+		case RunCommand:
+			s.commands <- string(payload)
+			s.mu.Lock()
+			reply = s.replies[RunCommand]
+			s.mu.Unlock()
+			if reply == nil {
+				reply = []byte(`[{"success":true}]`)
+			}
+		case Subscribe:
+			var events []string
+			json.Unmarshal(payload, &events)
+			c.mu.Lock()
+			for _, e := range events {
+				c.events[e] = true
+			}
+			c.mu.Unlock()
+			reply = []byte(`{"success":true}`)
+		default:
+			s.mu.Lock()
+			reply = s.replies[typ]
+			s.mu.Unlock()
+			if reply == nil {
+				reply = []byte(`{"success":false,"error":"unsupported"}`)
+			}
+		}
+		c.mu.Lock()
+		err = write(c, typ, reply)
+		c.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func read(r io.Reader) (uint32, []byte, error) {
+	header := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	size := byteOrder.Uint32(header[len(magic):])
+	typ := byteOrder.Uint32(header[len(magic)+4:])
+	payload := make([]byte, size)
+	_, err := io.ReadFull(r, payload)
+	return typ, payload, err
+}
+
+func write(w io.Writer, typ uint32, payload []byte) error {
+	msg := make([]byte, len(magic)+8, len(magic)+8+len(payload))
+	copy(msg, magic)
+	byteOrder.PutUint32(msg[len(magic):], uint32(len(payload)))
+	byteOrder.PutUint32(msg[len(magic)+4:], typ)
+	_, err := w.Write(append(msg, payload...))
+	return err
+}
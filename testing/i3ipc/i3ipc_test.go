@@ -0,0 +1,91 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i3ipc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func dial(t *testing.T, s *Server) net.Conn {
+	c, err := net.Dial("unix", s.Path())
+	require.NoError(t, err)
+	c.SetDeadline(time.Now().Add(time.Second))
+	return c
+}
+
+func request(t *testing.T, c net.Conn, typ uint32, payload string) (uint32, string) {
+	require.NoError(t, write(c, typ, []byte(payload)))
+	replyType, reply, err := read(c)
+	require.NoError(t, err)
+	return replyType, string(reply)
+}
+
+func TestReplies(t *testing.T) {
+	s := New()
+	defer s.Close()
+	c := dial(t, s)
+	defer c.Close()
+
+	typ, reply := request(t, c, GetWorkspaces, "")
+	require.Equal(t, GetWorkspaces, typ)
+	require.Equal(t, "[]", reply)
+	_, reply = request(t, c, GetBindingState, "")
+	require.Equal(t, `{"name":"default"}`, reply)
+	_, reply = request(t, c, 99, "")
+	require.Contains(t, reply, `"success":false`)
+
+	s.SetReply(GetWorkspaces, []map[string]string{{"name": "1"}})
+	_, reply = request(t, c, GetWorkspaces, "")
+	require.Equal(t, `[{"name":"1"}]`, reply)
+
+	_, reply = request(t, c, RunCommand, "workspace 1")
+	require.Equal(t, `[{"success":true}]`, reply)
+	require.Equal(t, "workspace 1", <-s.Commands())
+}
+
+func TestEvents(t *testing.T) {
+	s := New()
+	defer s.Close()
+	c := dial(t, s)
+	defer c.Close()
+	other := dial(t, s)
+	defer other.Close()
+
+	_, reply := request(t, c, Subscribe, `["mode"]`)
+	require.Equal(t, `{"success":true}`, reply)
+
+	s.Emit("workspace", map[string]string{"change": "focus"})
+	s.Emit("mode", map[string]string{"change": "resize"})
+	typ, payload, err := read(c)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x80000002), typ)
+	require.Equal(t, `{"change":"resize"}`, string(payload))
+	require.Panics(t, func() { s.Emit("nope", nil) })
+
+	s.Disconnect()
+	_, _, err = read(c)
+	require.Error(t, err, "after disconnect")
+	_, _, err = read(other)
+	require.Error(t, err, "after disconnect")
+
+	c = dial(t, s)
+	defer c.Close()
+	typ, _ = request(t, c, GetTree, "")
+	require.Equal(t, GetTree, typ, "server accepts new connections")
+}